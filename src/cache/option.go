@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"time"
+)
+
+// Option represents an option for configure UserAccountService instance.
+type Option interface {
+	apply(svc *UserAccountService)
+}
+
+type optionFunc func(svc *UserAccountService)
+
+func (fn optionFunc) apply(svc *UserAccountService) {
+	fn(svc)
+}
+
+// DefaultCapacity is the maximum number of user accounts could be cached.
+const DefaultCapacity = 1024
+
+// WithCapacity sets up the maximum number of user accounts could be cached.
+func WithCapacity(capacity int) Option {
+	return optionFunc(func(svc *UserAccountService) {
+		svc.capacity = capacity
+	})
+}
+
+// DefaultTTL is the time during which the found user account is cached.
+const DefaultTTL = time.Minute
+
+// WithTTL sets up the time during which the found user account is cached.
+func WithTTL(ttl time.Duration) Option {
+	return optionFunc(func(svc *UserAccountService) {
+		svc.ttl = ttl
+	})
+}
+
+// DefaultNegativeTTL is the time during which the user account absence is cached.
+const DefaultNegativeTTL = time.Second * 5
+
+// WithNegativeTTL sets up the time during which the user account absence is cached. Zero value disables negative
+// caching.
+func WithNegativeTTL(ttl time.Duration) Option {
+	return optionFunc(func(svc *UserAccountService) {
+		svc.negativeTTL = ttl
+	})
+}
+
+// DefaultLookupTimeout is the maximum time the shared lookup of the wrapped service takes.
+const DefaultLookupTimeout = time.Second * 5
+
+// WithLookupTimeout sets up the maximum time the shared lookup of the wrapped service takes. The lookup is shared
+// by concurrent callers, so it is not canceled with the context of any of them.
+func WithLookupTimeout(timeout time.Duration) Option {
+	return optionFunc(func(svc *UserAccountService) {
+		svc.lookupTimeout = timeout
+	})
+}
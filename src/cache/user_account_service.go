@@ -0,0 +1,339 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"golang.org/x/sync/singleflight"
+)
+
+// Stats contains the cache statistics.
+type Stats struct {
+	// Size is the number of entries are currently cached.
+	Size int
+
+	// Capacity is the maximum number of entries could be cached.
+	Capacity int
+
+	// Hits is the number of lookups which found an entry.
+	Hits uint64
+
+	// Misses is the number of lookups which have to call the wrapped service.
+	Misses uint64
+
+	// Evictions is the number of entries which were removed because of capacity limit.
+	Evictions uint64
+
+	// Expirations is the number of entries which were removed because of TTL.
+	Expirations uint64
+}
+
+// key is the cache key. User accounts are isolated by tenant, so the same
+// identifier must not be shared between tenants.
+type key struct {
+	tenantID otelexample.TenantID
+	id       otelexample.ID
+}
+
+func newKey(ctx context.Context, id otelexample.ID) key {
+	tenantID, _ := otelexample.TenantIDFromContext(ctx)
+
+	return key{
+		tenantID: tenantID,
+		id:       id,
+	}
+}
+
+// String returns the key representation which is used to deduplicate lookups.
+func (k key) String() string {
+	return k.tenantID.String() + "/" + k.id.String()
+}
+
+// entry is the cached result of the lookup. The error is set for negative entries.
+type entry struct {
+	key       key
+	ua        *otelexample.UserAccount
+	err       error
+	expiresAt time.Time
+}
+
+var _ otelexample.UserAccountService = (*UserAccountService)(nil)
+
+// UserAccountService represents a service for managing UserAccount data which
+// caches user accounts found by identifier.
+type UserAccountService struct {
+	wrapped otelexample.UserAccountService
+
+	mu      sync.Mutex
+	entries map[key]*list.Element
+	order   *list.List
+	group   singleflight.Group
+
+	// generation is changed when the entries are invalidated, so the results
+	// of the lookups which were started before are not cached.
+	generation uint64
+
+	capacity      int
+	ttl           time.Duration
+	negativeTTL   time.Duration
+	lookupTimeout time.Duration
+
+	hits        uint64
+	misses      uint64
+	evictions   uint64
+	expirations uint64
+}
+
+// NewUserAccountService returns a new instance of UserAccountService.
+func NewUserAccountService(svc otelexample.UserAccountService, opts ...Option) *UserAccountService {
+	cache := &UserAccountService{
+		wrapped: svc,
+
+		mu:      sync.Mutex{},
+		entries: nil,
+		order:   list.New(),
+		group:   singleflight.Group{},
+
+		generation: 0,
+
+		capacity:      DefaultCapacity,
+		ttl:           DefaultTTL,
+		negativeTTL:   DefaultNegativeTTL,
+		lookupTimeout: DefaultLookupTimeout,
+
+		hits:        0,
+		misses:      0,
+		evictions:   0,
+		expirations: 0,
+	}
+
+	for _, opt := range opts {
+		opt.apply(cache)
+	}
+
+	cache.entries = make(map[key]*list.Element, cache.capacity)
+
+	return cache
+}
+
+// CreateUserAccount creates a new user account.
+func (svc *UserAccountService) CreateUserAccount(ctx context.Context, ua *otelexample.UserAccount) error {
+	if err := svc.wrapped.CreateUserAccount(ctx, ua); err != nil {
+		return err // nolint:wrapcheck
+	}
+
+	// The account could be looked up before it was created, so the negative
+	// entry should be dropped.
+	svc.Invalidate(ctx, ua.ID)
+
+	return nil
+}
+
+// FindUserAccounts returns a list of user accounts.
+func (svc *UserAccountService) FindUserAccounts(
+	ctx context.Context,
+	opts otelexample.FindOptions,
+) (
+	*otelexample.FindUserAccountsResult,
+	error,
+) {
+	return svc.wrapped.FindUserAccounts(ctx, opts) // nolint:wrapcheck
+}
+
+// FindUserAccountByID returns user account by unique identifier.
+//
+// Concurrent lookups of the same missing account are deduplicated, so the
+// wrapped service is called once. The shared lookup keeps the values of the
+// context, but it is not canceled when the caller which started it goes away.
+func (svc *UserAccountService) FindUserAccountByID(
+	ctx context.Context,
+	id otelexample.ID,
+) (
+	*otelexample.UserAccount,
+	error,
+) {
+	cacheKey := newKey(ctx, id)
+
+	cached, generation, ok := svc.get(cacheKey)
+	if ok {
+		atomic.AddUint64(&svc.hits, 1)
+
+		return cloneResult(cached)
+	}
+
+	atomic.AddUint64(&svc.misses, 1)
+
+	// The lookups which were started before the invalidation are not joined.
+	groupKey := cacheKey.String() + "@" + strconv.FormatUint(generation, 10)
+
+	resultCh := svc.group.DoChan(groupKey, func() (any, error) {
+		lookupCtx, cancel := context.WithTimeout(detachedContext{Context: ctx}, svc.lookupTimeout)
+		defer cancel()
+
+		ua, err := svc.wrapped.FindUserAccountByID(lookupCtx, id)
+		if err != nil && otelexample.ErrorCodeFromError(err) != otelexample.ErrorCodeNotFound {
+			return nil, err
+		}
+
+		return svc.set(cacheKey, generation, ua, err), nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultCh:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		return cloneResult(result.Val.(*entry)) // nolint:forcetypeassert
+	}
+}
+
+// Invalidate removes the cached user account of the tenant from the context.
+// It should be called when the account is updated or deleted.
+func (svc *UserAccountService) Invalidate(ctx context.Context, id otelexample.ID) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	svc.generation++
+
+	if elem, ok := svc.entries[newKey(ctx, id)]; ok {
+		svc.remove(elem)
+	}
+}
+
+// Purge removes all cached user accounts.
+func (svc *UserAccountService) Purge() {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	svc.generation++
+	svc.entries = make(map[key]*list.Element, svc.capacity)
+	svc.order.Init()
+}
+
+// Stats returns the cache statistics.
+func (svc *UserAccountService) Stats() Stats {
+	svc.mu.Lock()
+	size := svc.order.Len()
+	svc.mu.Unlock()
+
+	return Stats{
+		Size:     size,
+		Capacity: svc.capacity,
+
+		Hits:        atomic.LoadUint64(&svc.hits),
+		Misses:      atomic.LoadUint64(&svc.misses),
+		Evictions:   atomic.LoadUint64(&svc.evictions),
+		Expirations: atomic.LoadUint64(&svc.expirations),
+	}
+}
+
+// get returns the cached entry. The current generation is returned on a miss,
+// so the result of the lookup is cached only when nothing was invalidated.
+func (svc *UserAccountService) get(cacheKey key) (*entry, uint64, bool) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	elem, ok := svc.entries[cacheKey]
+	if !ok {
+		return nil, svc.generation, false
+	}
+
+	cached := elem.Value.(*entry) // nolint:forcetypeassert
+	if time.Now().After(cached.expiresAt) {
+		svc.remove(elem)
+
+		atomic.AddUint64(&svc.expirations, 1)
+
+		return nil, svc.generation, false
+	}
+
+	svc.order.MoveToFront(elem)
+
+	return cached, svc.generation, true
+}
+
+func (svc *UserAccountService) set(
+	cacheKey key,
+	generation uint64,
+	ua *otelexample.UserAccount,
+	err error,
+) *entry {
+	ttl := svc.ttl
+	if err != nil {
+		ttl = svc.negativeTTL
+	}
+
+	cached := &entry{
+		key:       cacheKey,
+		ua:        ua,
+		err:       err,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	if ttl <= 0 {
+		return cached
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	// The entry could be stale, because it was invalidated during the lookup.
+	if generation != svc.generation {
+		return cached
+	}
+
+	if elem, ok := svc.entries[cacheKey]; ok {
+		svc.remove(elem)
+	}
+
+	svc.entries[cacheKey] = svc.order.PushFront(cached)
+
+	for svc.order.Len() > svc.capacity {
+		svc.remove(svc.order.Back())
+
+		atomic.AddUint64(&svc.evictions, 1)
+	}
+
+	return cached
+}
+
+// remove removes element from the cache. It should be called under the lock.
+func (svc *UserAccountService) remove(elem *list.Element) {
+	cached := svc.order.Remove(elem).(*entry) // nolint:forcetypeassert
+	delete(svc.entries, cached.key)
+}
+
+// cloneResult returns a copy of the cached user account, so callers could
+// not modify the cached value.
+func cloneResult(cached *entry) (*otelexample.UserAccount, error) {
+	if cached.err != nil {
+		return nil, cached.err
+	}
+
+	return cached.ua.Clone(), nil
+}
+
+// detachedContext keeps the values of the parent context, but it is never canceled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
@@ -0,0 +1,169 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/cache"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/inmem"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/otelexampletest"
+)
+
+func TestUserAccountService(t *testing.T) {
+	t.Parallel()
+
+	otelexampletest.RunUserAccountServiceSuite(t, func(
+		_ *testing.T,
+		identifierGenerator otelexample.IdentifierGenerator,
+		timer otelexample.Timer,
+	) otelexample.UserAccountService {
+		return cache.NewUserAccountService(inmem.NewUserAccountService(identifierGenerator, timer))
+	})
+}
+
+// blockingUserAccountService finds user accounts when the lookup is released.
+type blockingUserAccountService struct {
+	otelexample.UserAccountService
+
+	calls    int64
+	started  chan context.Context
+	released chan *otelexample.UserAccount
+}
+
+func newBlockingUserAccountService() *blockingUserAccountService {
+	return &blockingUserAccountService{
+		UserAccountService: nil,
+
+		calls:    0,
+		started:  make(chan context.Context, 1),
+		released: make(chan *otelexample.UserAccount, 1),
+	}
+}
+
+func (svc *blockingUserAccountService) FindUserAccountByID(
+	ctx context.Context,
+	id otelexample.ID,
+) (
+	*otelexample.UserAccount,
+	error,
+) {
+	atomic.AddInt64(&svc.calls, 1)
+	svc.started <- ctx
+
+	if ua := <-svc.released; ua != nil {
+		return ua, nil
+	}
+
+	return nil, &otelexample.Error{
+		Code:    otelexample.ErrorCodeNotFound,
+		Message: "user account not found",
+		Err:     nil,
+	}
+}
+
+func findAsync(ctx context.Context, svc otelexample.UserAccountService, id otelexample.ID) <-chan error {
+	errCh := make(chan error, 1)
+
+	go func() {
+		_, err := svc.FindUserAccountByID(ctx, id)
+		errCh <- err
+	}()
+
+	return errCh
+}
+
+// receive returns the value from the channel or fails the test when the value is not sent in time.
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+
+	select {
+	case value := <-ch:
+		return value
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	panic("unreachable")
+}
+
+func TestUserAccountService_FindUserAccountByID_CanceledCaller(t *testing.T) {
+	t.Parallel()
+
+	var (
+		wrapped = newBlockingUserAccountService()
+		svc     = cache.NewUserAccountService(wrapped)
+		id      = otelexample.ID("1")
+	)
+
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErrCh := findAsync(firstCtx, svc, id)
+	lookupCtx := receive(t, wrapped.started)
+
+	secondErrCh := findAsync(context.Background(), svc, id)
+
+	cancel()
+
+	if err := receive(t, firstErrCh); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error of canceled caller: %v", err)
+	}
+
+	if err := lookupCtx.Err(); err != nil {
+		t.Fatalf("shared lookup was canceled: %v", err)
+	}
+
+	wrapped.released <- &otelexample.UserAccount{
+		ID:        id,
+		Username:  "username",
+		User:      &otelexample.User{ID: "1", FirstName: "", LastName: "", CreatedAt: time.Time{}},
+		CreatedAt: time.Time{},
+	}
+
+	if err := receive(t, secondErrCh); err != nil {
+		t.Fatalf("unexpected error of waiting caller: %v", err)
+	}
+
+	if calls := atomic.LoadInt64(&wrapped.calls); calls != 1 {
+		t.Errorf("unexpected number of lookups: %d", calls)
+	}
+}
+
+func TestUserAccountService_FindUserAccountByID_InvalidatedDuringLookup(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		wrapped = newBlockingUserAccountService()
+		svc     = cache.NewUserAccountService(wrapped)
+		id      = otelexample.ID("1")
+	)
+
+	errCh := findAsync(ctx, svc, id)
+	receive(t, wrapped.started)
+
+	// The account is created while the lookup is in flight.
+	svc.Invalidate(ctx, id)
+
+	wrapped.released <- nil
+
+	if err := receive(t, errCh); otelexample.ErrorCodeFromError(err) != otelexample.ErrorCodeNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errCh = findAsync(ctx, svc, id)
+	receive(t, wrapped.started)
+
+	wrapped.released <- &otelexample.UserAccount{
+		ID:        id,
+		Username:  "username",
+		User:      &otelexample.User{ID: "1", FirstName: "", LastName: "", CreatedAt: time.Time{}},
+		CreatedAt: time.Time{},
+	}
+
+	if err := receive(t, errCh); err != nil {
+		t.Fatalf("stale absence was cached: %v", err)
+	}
+}
@@ -2,42 +2,89 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"strings"
+	stdtime "time"
 
 	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/cache"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/health"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/http"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/inmem"
 	"github.com/morozovcookie/opentelemetry-prometheus-example/nanoid"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/metrics"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/otlp"
+	otelprometheus "github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/prometheus"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/tracing"
 	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
 	"github.com/morozovcookie/opentelemetry-prometheus-example/prometheus"
 	"github.com/morozovcookie/opentelemetry-prometheus-example/time"
 	"github.com/morozovcookie/opentelemetry-prometheus-example/zap"
 	prom "github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
 	uberzap "go.uber.org/zap"
 )
 
+var errShardsWithReplicas = errors.New("replicas are not supported together with shards")
+
 type backend struct {
 	config *Config
 	logger *uberzap.Logger
 
-	registerer prom.Registerer
-	gatherer   prom.Gatherer
+	registerer    prom.Registerer
+	gatherer      prom.Gatherer
+	meterProvider metric.MeterProvider
+	// metricController pushes the OpenTelemetry metrics, it is nil when they are pulled.
+	metricController *controller.Controller
+
+	tracerProvider *sdktrace.TracerProvider
+
+	health   *health.Health
+	redactor *zap.Redactor
 
 	identifierGenerator otelexample.IdentifierGenerator
 	timer               otelexample.Timer
 
+	tenantResolver http.TenantResolver
+	tenantLabeler  *prometheus.TenantLabeler
+
+	deadlineExceededHandler percona.DeadlineExceededHandler
+
+	perconaClients    []*percona.Client
 	prepareTxBeginner percona.PrepareTxBeginner
+	replicaRouter     *percona.Router
+	shardRouter       *percona.ShardRouter
+	slowQueryLogs     []*percona.SlowQueryLog
 
-	userAccountService otelexample.UserAccountService
+	userAccountService       otelexample.UserAccountService
+	memoryUserAccountService *inmem.UserAccountService
 }
 
-func newBackend(config *Config, logger *uberzap.Logger) *backend {
+func newBackend(config *Config, logger *uberzap.Logger) (*backend, error) {
 	be := new(backend)
 
 	be.config, be.logger = config, logger
 
+	redactor, err := initRedactor(config)
+	if err != nil {
+		return nil, fmt.Errorf("new backend: %w", err)
+	}
+
+	be.redactor = redactor
+
+	be.initHealth()
 	be.initIdentifierGenerator()
 	be.initTimer()
+	be.initTenancy()
 
-	return be
+	return be, nil
 }
 
 func (be *backend) init(ctx context.Context) error {
@@ -46,6 +93,34 @@ func (be *backend) init(ctx context.Context) error {
 
 	be.registerer = prom.WrapRegistererWithPrefix("server_", be.registerer)
 
+	if be.config.MetricsConfig.Otel() {
+		if err := be.initOtelMetrics(registry); err != nil {
+			return fmt.Errorf("init backend: %w", err)
+		}
+	}
+
+	if be.config.TracingConfig.Enabled() {
+		if err := be.initTracing(); err != nil {
+			return fmt.Errorf("init backend: %w", err)
+		}
+	}
+
+	if be.config.StorageConfig.Storage == StorageMemory {
+		if err := be.initMemoryStorage(); err != nil {
+			return fmt.Errorf("init backend: %w", err)
+		}
+
+		return nil
+	}
+
+	if be.config.PerconaConfig.CheckSchema {
+		if err := checkSchemaVersion(ctx, be.config); err != nil {
+			return fmt.Errorf("init backend: %w", err)
+		}
+	}
+
+	be.initDeadlineExceededHandler()
+
 	perconaLogger := be.logger.Named("percona")
 
 	if err := be.initPrepareTxBeginner(ctx, perconaLogger); err != nil {
@@ -57,12 +132,317 @@ func (be *backend) init(ctx context.Context) error {
 	return nil
 }
 
+// initDeadlineExceededHandler builds the handler which counts the database
+// operations which have hit their deadline by every enabled metrics backend.
+func (be *backend) initDeadlineExceededHandler() {
+	var handlers []percona.DeadlineExceededHandler
+
+	if be.config.MetricsConfig.Prometheus() {
+		handlers = append(handlers, prometheus.NewDeadlineExceededCounter(
+			prom.WrapRegistererWithPrefix("sql_", be.registerer)).HandleDeadlineExceeded)
+	}
+
+	if be.config.MetricsConfig.Otel() {
+		handlers = append(handlers, metrics.NewDeadlineExceededCounter(
+			be.meterProvider.Meter("percona")).HandleDeadlineExceeded)
+	}
+
+	be.deadlineExceededHandler = func(ctx context.Context, op percona.Operation) {
+		for _, handler := range handlers {
+			handler(ctx, op)
+		}
+	}
+}
+
+// initOtelMetrics builds the meter provider. The pulled metrics are served
+// together with the Prometheus client metrics. The OpenTelemetry metrics have
+// their own prefix, so their names never collide.
+func (be *backend) initOtelMetrics(registry *prom.Registry) error {
+	if be.config.MetricsConfig.OtelExporter == OtelExporterOTLP {
+		return be.initOTLPMetrics()
+	}
+
+	otelRegistry := prom.NewRegistry()
+
+	exporter, err := otelprometheus.NewExporter(prom.WrapRegistererWithPrefix("otel_", otelRegistry), otelRegistry)
+	if err != nil {
+		return err
+	}
+
+	be.meterProvider = exporter.MeterProvider()
+	be.gatherer = prom.Gatherers{registry, otelRegistry}
+
+	return nil
+}
+
+// initTracing builds the tracer provider which exports the finished spans in
+// batches. The parent sampling decision is respected, so the traces started
+// by the callers are never broken.
+func (be *backend) initTracing() error {
+	var (
+		cfg = be.config.TracingConfig
+
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+
+	switch cfg.Exporter {
+	case TracingExporterStdout:
+		exporter = tracing.NewStdoutExporter()
+	case TracingExporterFile:
+		if exporter, err = tracing.NewFileExporter(cfg.FilePath); err != nil {
+			return err
+		}
+	case TracingExporterOTLP:
+		exporter = tracing.NewOTLPExporter(otlp.NewClient(cfg.OTLPEndpoint))
+	}
+
+	be.tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String("server"))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))))
+
+	return nil
+}
+
+// initOTLPMetrics builds the meter provider which pushes the metrics to the
+// collector periodically.
+func (be *backend) initOTLPMetrics() error {
+	var (
+		cfg  = be.config.MetricsConfig
+		opts []otlp.ClientOption
+	)
+
+	if cfg.OTLPGzip {
+		opts = append(opts, otlp.WithGzip())
+	}
+
+	exporter := otlp.NewMetricExporter(otlp.NewClient(cfg.OTLPEndpoint, opts...), cfg.OTLPTemporality)
+	be.metricController = otlp.NewController(exporter, cfg.OTLPInterval, otelprometheus.DefaultHistogramBoundaries)
+
+	// The controller outlives the init context, it is stopped during the shutdown.
+	if err := be.metricController.Start(context.Background()); err != nil {
+		return err
+	}
+
+	be.meterProvider = be.metricController
+
+	return nil
+}
+
+func (be *backend) initMemoryStorage() error {
+	be.memoryUserAccountService = inmem.NewUserAccountService(be.identifierGenerator, be.timer)
+
+	if path := be.config.StorageConfig.MemorySnapshotPath; path != "" {
+		if err := be.memoryUserAccountService.LoadFile(path); err != nil {
+			return err
+		}
+	}
+
+	be.userAccountService = be.memoryUserAccountService
+
+	be.withUserAccountServiceMetrics()
+
+	if be.tracerProvider != nil {
+		be.userAccountService = tracing.NewUserAccountService(be.userAccountService,
+			be.tracerProvider.Tracer("inmem"))
+	}
+
+	be.userAccountService = zap.NewUserAccountService(be.userAccountService,
+		be.logger.Named("inmem").Named("user_account_svc"), be.redactor)
+
+	return nil
+}
+
+// shutdown releases the backend resources after servers are stopped.
+func (be *backend) shutdown(ctx context.Context) error {
+	for _, slowQueryLog := range be.slowQueryLogs {
+		slowQueryLog.Wait()
+	}
+
+	// The metrics are pushed for the last time, so the final values are not lost.
+	if be.metricController != nil {
+		if err := be.metricController.Stop(ctx); err != nil {
+			return fmt.Errorf("shutdown backend: %w", err)
+		}
+	}
+
+	// The remaining spans are exported before the process exits.
+	if be.tracerProvider != nil {
+		if err := be.tracerProvider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("shutdown backend: %w", err)
+		}
+	}
+
+	// The cached statements are closed together with the connection pools. Every
+	// client is closed even when another one has failed.
+	var closeErr error
+
+	for _, perconaClient := range be.perconaClients {
+		if err := perconaClient.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+
+	if closeErr != nil {
+		return fmt.Errorf("shutdown backend: %w", closeErr)
+	}
+
+	if be.memoryUserAccountService == nil || be.config.StorageConfig.MemorySnapshotPath == "" {
+		return nil
+	}
+
+	if err := be.memoryUserAccountService.SaveFile(be.config.StorageConfig.MemorySnapshotPath); err != nil {
+		return fmt.Errorf("shutdown backend: %w", err)
+	}
+
+	return nil
+}
+
 func (be *backend) initPrepareTxBeginner(ctx context.Context, logger *uberzap.Logger) error {
-	perconaClient := percona.NewClient(be.config.PerconaConfig.Dsn)
-	if err := perconaClient.Connect(ctx); err != nil {
+	if len(be.config.PerconaConfig.Shards) > 0 {
+		return be.initSharding(ctx, logger)
+	}
+
+	return be.initReplicaRouting(ctx, logger)
+}
+
+func (be *backend) initSharding(ctx context.Context, logger *uberzap.Logger) error {
+	if len(be.config.PerconaConfig.ReplicaDsns) > 0 {
+		return errShardsWithReplicas
+	}
+
+	shards := make([]percona.Shard, len(be.config.PerconaConfig.Shards))
+
+	// Every shard has its own metrics, health check and circuit breaker, so
+	// the failure of one shard does not affect others.
+	for i, shard := range be.config.PerconaConfig.Shards {
+		target := "shard_" + shard.Name
+
+		_, prepareTxBeginner, err := be.initPerconaTarget(ctx, logger, target, shard.Dsn)
+		if err != nil {
+			return err
+		}
+
+		shards[i] = percona.Shard{
+			Name:              shard.Name,
+			PrepareTxBeginner: be.withCircuitBreaker(logger, target, prepareTxBeginner),
+		}
+	}
+
+	be.shardRouter = percona.NewShardRouter(shards)
+	be.prepareTxBeginner = be.shardRouter
+
+	return nil
+}
+
+func (be *backend) withCircuitBreaker(
+	logger *uberzap.Logger,
+	target string,
+	prepareTxBeginner percona.PrepareTxBeginner,
+) percona.PrepareTxBeginner {
+	if !be.config.PerconaConfig.CircuitBreakerEnabled {
+		return prepareTxBeginner
+	}
+
+	registerer := be.registerer
+	registerer = prom.WrapRegistererWithPrefix("sql_", registerer)
+	registerer = prom.WrapRegistererWith(prom.Labels{
+		"target": target,
+	}, registerer)
+
+	observer := prometheus.NewCircuitBreakerObserver(registerer)
+
+	return percona.NewCircuitBreaker(prepareTxBeginner,
+		percona.WithCircuitBreakerFailureRatio(be.config.PerconaConfig.CircuitBreakerFailureRatio),
+		percona.WithCircuitBreakerMinRequests(be.config.PerconaConfig.CircuitBreakerMinRequests),
+		percona.WithCircuitBreakerWindow(be.config.PerconaConfig.CircuitBreakerWindow),
+		percona.WithCircuitBreakerOpenTimeout(be.config.PerconaConfig.CircuitBreakerOpenTimeout),
+		percona.WithCircuitBreakerStateChangeHandler(observer.HandleStateChange),
+		percona.WithCircuitBreakerStateChangeHandler(zap.CircuitBreakerStateChangeHandler(
+			logger.Named("circuit_breaker").With(uberzap.String("target", target)))))
+}
+
+func (be *backend) initReplicaRouting(ctx context.Context, logger *uberzap.Logger) error {
+	_, prepareTxBeginner, err := be.initPerconaTarget(ctx, logger, "primary", be.config.PerconaConfig.Dsn)
+	if err != nil {
 		return err
 	}
 
+	// Every target has its own circuit breaker, so the failure of the replica
+	// does not open the breaker of the primary.
+	primary := be.withCircuitBreaker(logger, "primary", prepareTxBeginner)
+	be.prepareTxBeginner = primary
+
+	if len(be.config.PerconaConfig.ReplicaDsns) == 0 {
+		return nil
+	}
+
+	replicas := make([]percona.Replica, len(be.config.PerconaConfig.ReplicaDsns))
+
+	for i, dsn := range be.config.PerconaConfig.ReplicaDsns {
+		target := fmt.Sprintf("replica_%d", i)
+
+		client, prepareTxBeginner, err := be.initPerconaTarget(ctx, logger, target, dsn)
+		if err != nil {
+			return err
+		}
+
+		replicas[i] = percona.Replica{
+			PrepareTxBeginner: be.withCircuitBreaker(logger, target, prepareTxBeginner),
+			Checker:           client,
+		}
+
+		// The lagging replica is only reported, because the reads fall back to
+		// the primary and the application is still able to serve requests.
+		be.health.AddReadinessCheck("percona_"+target, health.LagChecker(client.ReplicationLag,
+			be.config.PerconaConfig.MaxReplicationLag), health.WithCheckOptional())
+	}
+
+	routerLogger := logger.Named("router")
+
+	be.replicaRouter = percona.NewRouter(primary, replicas,
+		percona.WithMaxReplicationLag(be.config.PerconaConfig.MaxReplicationLag),
+		percona.WithReplicaCheckInterval(be.config.PerconaConfig.ReplicaCheckInterval),
+		percona.WithReplicaCheckHandler(func(idx int, lag stdtime.Duration, err error) {
+			ff := []uberzap.Field{
+				uberzap.String("target", fmt.Sprintf("replica_%d", idx)), uberzap.Duration("lag", lag),
+				uberzap.Error(err),
+			}
+
+			routerLogger.Debug("check replica", ff...)
+
+			if err != nil || lag > be.config.PerconaConfig.MaxReplicationLag {
+				routerLogger.Warn("replica is unhealthy", ff...)
+			}
+		}))
+	be.prepareTxBeginner = be.replicaRouter
+
+	return nil
+}
+
+func (be *backend) initPerconaTarget(
+	ctx context.Context,
+	logger *uberzap.Logger,
+	target string,
+	dsn string,
+) (
+	*percona.Client,
+	percona.PrepareTxBeginner,
+	error,
+) {
+	perconaClient := percona.NewClient(dsn,
+		percona.WithStmtCacheSize(be.config.PerconaConfig.StmtCacheSize),
+		percona.WithConnectTimeout(be.config.PerconaConfig.ConnectTimeout),
+		percona.WithPingTimeout(be.config.PerconaConfig.PingTimeout),
+		percona.WithDeadlineExceededHandler(be.deadlineExceededHandler))
+	if err := perconaClient.Connect(ctx); err != nil {
+		return nil, nil, fmt.Errorf("connect to %s: %w", target, err)
+	}
+
+	be.perconaClients = append(be.perconaClients, perconaClient)
+
 	var (
 		dbName = perconaClient.DBName()
 		dbUser = perconaClient.DBUser()
@@ -74,19 +454,188 @@ func (be *backend) initPrepareTxBeginner(ctx context.Context, logger *uberzap.Lo
 		"system": "mysql",
 		"user":   dbUser,
 		"name":   dbName,
+		"target": target,
 	}, registerer)
 
-	be.prepareTxBeginner = perconaClient
-	be.prepareTxBeginner = prometheus.NewPrepareTxBeginner(be.prepareTxBeginner, registerer)
-	be.prepareTxBeginner = zap.NewPrepareTxBeginner(be.prepareTxBeginner, logger, uberzap.String("dbName", dbName),
-		uberzap.String("dbUser", dbUser))
+	switch {
+	case target == "primary":
+		be.health.AddReadinessCheck("percona", health.CheckerFunc(perconaClient.Ping))
+	case strings.HasPrefix(target, "shard_"):
+		be.health.AddReadinessCheck("percona_"+target, health.CheckerFunc(perconaClient.Ping))
+	}
 
-	return nil
+	var (
+		prepareTxBeginner percona.PrepareTxBeginner = perconaClient
+
+		attrs = []attribute.KeyValue{
+			semconv.DBSystemMySQL, semconv.DBUserKey.String(dbUser), semconv.DBNameKey.String(dbName),
+			attribute.String("target", target),
+		}
+	)
+
+	if be.config.MetricsConfig.Prometheus() {
+		registerer.MustRegister(prometheus.NewDBStatsCollector(perconaClient),
+			prometheus.NewStmtCacheCollector(perconaClient))
+
+		prepareTxBeginner = prometheus.NewPrepareTxBeginner(prepareTxBeginner, registerer, be.tenantLabeler)
+	}
+
+	if be.config.MetricsConfig.Otel() {
+		meter := be.meterProvider.Meter("percona")
+
+		if err := metrics.ObserveDBStats(meter, perconaClient, attrs...); err != nil {
+			return nil, nil, fmt.Errorf("observe %s stats: %w", target, err)
+		}
+
+		prepareTxBeginner = metrics.NewPrepareTxBeginner(prepareTxBeginner, meter, attrs...)
+	}
+
+	if be.tracerProvider != nil {
+		prepareTxBeginner = tracing.NewPrepareTxBeginner(prepareTxBeginner, be.tracerProvider.Tracer("percona"),
+			attrs...)
+	}
+
+	prepareTxBeginner = zap.NewPrepareTxBeginner(prepareTxBeginner, logger, be.redactor,
+		uberzap.String("dbName", dbName), uberzap.String("dbUser", dbUser), uberzap.String("target", target))
+
+	if be.config.SlowQueryConfig.Threshold > 0 {
+		prepareTxBeginner = be.withSlowQueryLog(logger.With(uberzap.String("dbName", dbName),
+			uberzap.String("dbUser", dbUser), uberzap.String("target", target)), registerer, attrs,
+			perconaClient.Uncached(), prepareTxBeginner)
+	}
+
+	return perconaClient, prepareTxBeginner, nil
+}
+
+func (be *backend) withSlowQueryLog(
+	logger *uberzap.Logger,
+	registerer prom.Registerer,
+	attrs []attribute.KeyValue,
+	explainer percona.Preparer,
+	prepareTxBeginner percona.PrepareTxBeginner,
+) percona.PrepareTxBeginner {
+	cfg := be.config.SlowQueryConfig
+
+	opts := []percona.SlowQueryLogOption{
+		percona.WithSlowQueryThreshold(cfg.Threshold),
+		percona.WithSlowQueryHandler(zap.SlowQueryHandler(logger.Named("slow_query"), cfg.SampleTick,
+			cfg.SampleFirst, cfg.SampleThereafter)),
+	}
+
+	if be.config.MetricsConfig.Prometheus() {
+		opts = append(opts, percona.WithSlowQueryHandler(prometheus.NewSlowQueryCounter(registerer).HandleSlowQuery))
+	}
+
+	if be.config.MetricsConfig.Otel() {
+		opts = append(opts, percona.WithSlowQueryHandler(metrics.NewSlowQueryCounter(be.meterProvider.Meter("percona"),
+			attrs...).HandleSlowQuery))
+	}
+
+	// The plans are captured by the client directly, so the EXPLAIN queries do
+	// not affect the metrics of the application queries. They are not cached
+	// either, so they do not evict the application statements.
+	if cfg.Explain {
+		opts = append(opts, percona.WithSlowQueryExplain(explainer),
+			percona.WithSlowQueryExplainInterval(cfg.ExplainInterval),
+			percona.WithSlowQueryExplainTimeout(cfg.ExplainTimeout))
+	}
+
+	slowQueryLog := percona.NewSlowQueryLog(prepareTxBeginner, opts...)
+	be.slowQueryLogs = append(be.slowQueryLogs, slowQueryLog)
+
+	return slowQueryLog
 }
 
 func (be *backend) initUserAccountService(logger *uberzap.Logger) {
-	be.userAccountService = percona.NewUserAccountService(be.prepareTxBeginner, be.identifierGenerator, be.timer)
-	be.userAccountService = zap.NewUserAccountService(be.userAccountService, logger.Named("user_account_svc"))
+	var (
+		identifierGenerator = be.identifierGenerator
+		opts                = []percona.UserAccountServiceOption{
+			percona.WithCreateTimeout(be.config.PerconaConfig.CreateTimeout),
+			percona.WithListTimeout(be.config.PerconaConfig.ListTimeout),
+			percona.WithGetTimeout(be.config.PerconaConfig.GetTimeout),
+			percona.WithOperationDeadlineExceededHandler(be.deadlineExceededHandler),
+		}
+	)
+
+	if be.shardRouter != nil {
+		identifierGenerator = percona.NewShardIdentifierGenerator(identifierGenerator, be.shardRouter)
+		opts = append(opts, percona.WithShards(be.shardRouter.Shards()))
+	}
+
+	be.userAccountService = percona.NewUserAccountService(be.prepareTxBeginner, identifierGenerator, be.timer,
+		opts...)
+
+	if be.config.CacheConfig.Capacity > 0 {
+		userAccountCache := cache.NewUserAccountService(be.userAccountService,
+			cache.WithCapacity(be.config.CacheConfig.Capacity), cache.WithTTL(be.config.CacheConfig.TTL),
+			cache.WithNegativeTTL(be.config.CacheConfig.NegativeTTL),
+			cache.WithLookupTimeout(be.config.CacheConfig.LookupTimeout))
+
+		if be.config.MetricsConfig.Prometheus() {
+			registerer := be.registerer
+			registerer = prom.WrapRegistererWithPrefix("cache_", registerer)
+			registerer = prom.WrapRegistererWith(prom.Labels{
+				"name": "user_account",
+			}, registerer)
+
+			registerer.MustRegister(prometheus.NewCacheCollector(userAccountCache))
+		}
+
+		be.userAccountService = userAccountCache
+	}
+
+	be.withUserAccountServiceMetrics()
+
+	if be.tracerProvider != nil {
+		be.userAccountService = tracing.NewUserAccountService(be.userAccountService,
+			be.tracerProvider.Tracer("percona"))
+	}
+
+	be.userAccountService = zap.NewUserAccountService(be.userAccountService, logger.Named("user_account_svc"),
+		be.redactor)
+}
+
+// withUserAccountServiceMetrics measures the user account operations end-to-end,
+// so the cache hits are measured as well as the queries.
+func (be *backend) withUserAccountServiceMetrics() {
+	if be.config.MetricsConfig.Prometheus() {
+		registerer := be.registerer
+		registerer = prom.WrapRegistererWithPrefix("service_", registerer)
+		registerer = prom.WrapRegistererWith(prom.Labels{
+			"name": "user_account",
+		}, registerer)
+
+		be.userAccountService = prometheus.NewUserAccountService(be.userAccountService, registerer,
+			be.tenantLabeler)
+	}
+
+	if be.config.MetricsConfig.Otel() {
+		be.userAccountService = metrics.NewUserAccountService(be.userAccountService,
+			be.meterProvider.Meter("service"), attribute.String("service", "user_account"))
+	}
+}
+
+func (be *backend) initHealth() {
+	be.health = health.NewHealth()
+
+	if path := be.config.MonitorConfig.HealthDiskPath; path != "" {
+		be.health.AddReadinessCheck("disk", health.DiskSpaceChecker(path,
+			be.config.MonitorConfig.HealthDiskMinFreeBytes))
+	}
+}
+
+func initRedactor(config *Config) (*zap.Redactor, error) {
+	key := []byte(config.LogRedactionHashKey)
+
+	if len(key) == 0 {
+		key = make([]byte, 32) // nolint:gomnd
+
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generate redaction hash key: %w", err)
+		}
+	}
+
+	return zap.NewRedactor(config.LogRedaction, key), nil
 }
 
 func (be *backend) initIdentifierGenerator() {
@@ -98,3 +647,22 @@ func (be *backend) initTimer() {
 	be.timer = time.NewTimer()
 	be.timer = zap.NewTimer(be.timer, be.logger.Named("timer"))
 }
+
+func (be *backend) initTenancy() {
+	resolvers := make([]http.TenantResolver, 0, 3) // nolint:gomnd
+
+	if len(be.config.TenantConfig.Tokens) > 0 {
+		resolvers = append(resolvers, http.TokenTenantResolver(be.config.TenantConfig.Tokens))
+	}
+
+	if header := be.config.TenantConfig.Header; header != "" {
+		resolvers = append(resolvers, http.HeaderTenantResolver(header))
+	}
+
+	if tenant := be.config.TenantConfig.Default; tenant != otelexample.EmptyTenantID {
+		resolvers = append(resolvers, http.StaticTenantResolver(tenant))
+	}
+
+	be.tenantResolver = http.ChainTenantResolver(resolvers...)
+	be.tenantLabeler = prometheus.NewTenantLabeler(be.config.TenantConfig.MaxLabelValues)
+}
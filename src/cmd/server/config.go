@@ -1,13 +1,284 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/cache"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/http"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/otlp"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/prometheus"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/zap"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	uberzap "go.uber.org/zap"
 )
 
+const (
+	StoragePercona = "percona"
+	StorageMemory  = "memory"
+)
+
+var errUnknownStorage = errors.New("storage should be percona or memory")
+
+type StorageConfig struct {
+	Storage            string
+	MemorySnapshotPath string
+}
+
+func NewStorageConfig() *StorageConfig {
+	return &StorageConfig{
+		Storage:            StoragePercona,
+		MemorySnapshotPath: "",
+	}
+}
+
+func (cfg *StorageConfig) Parse() error {
+	if storage := os.Getenv("SERVER_STORAGE"); storage != "" {
+		if storage != StoragePercona && storage != StorageMemory {
+			return fmt.Errorf("%q: %w", storage, errUnknownStorage)
+		}
+
+		cfg.Storage = storage
+	}
+
+	if path := os.Getenv("SERVER_MEMORY_SNAPSHOT_PATH"); path != "" {
+		cfg.MemorySnapshotPath = path
+	}
+
+	return nil
+}
+
+const (
+	MetricsBackendPrometheus = "prometheus"
+	MetricsBackendOtel       = "otel"
+	MetricsBackendBoth       = "both"
+)
+
+var errUnknownMetricsBackend = errors.New("metrics backend should be prometheus, otel or both")
+
+const (
+	OtelExporterPrometheus = "prometheus"
+	OtelExporterOTLP       = "otlp"
+)
+
+var errUnknownOtelExporter = errors.New("otel metrics exporter should be prometheus or otlp")
+
+const (
+	HTTPLabelClientIP  = "client_ip"
+	HTTPLabelUserAgent = "user_agent"
+)
+
+var (
+	errUnknownHTTPLabel      = errors.New("http label should be client_ip or user_agent")
+	errInvalidMaxLabelValues = errors.New("max label values should be positive")
+)
+
+type MetricsConfig struct {
+	// Backend is the implementation of HTTP and SQL metrics. The metrics which
+	// have no OpenTelemetry counterpart are always collected by Prometheus client.
+	Backend string
+
+	// OtelExporter is the way the OpenTelemetry metrics are delivered: served
+	// together with Prometheus client metrics or pushed to the collector.
+	OtelExporter    string
+	OTLPEndpoint    string
+	OTLPInterval    time.Duration
+	OTLPTemporality aggregation.TemporalitySelector
+	OTLPGzip        bool
+
+	// HTTPLabels are the opt-in high-cardinality labels of HTTP metrics.
+	HTTPLabels []string
+
+	// HTTPMaxLabelValues caps the distinct label sets of every HTTP metric of both backends.
+	HTTPMaxLabelValues int
+}
+
+func NewMetricsConfig() *MetricsConfig {
+	return &MetricsConfig{
+		Backend: MetricsBackendPrometheus,
+
+		OtelExporter:    OtelExporterPrometheus,
+		OTLPEndpoint:    "http://localhost:4318",
+		OTLPInterval:    time.Second * 10, // nolint:gomnd
+		OTLPTemporality: aggregation.CumulativeTemporalitySelector(),
+		OTLPGzip:        false,
+
+		HTTPLabels:         nil,
+		HTTPMaxLabelValues: prometheus.DefaultMaxLabelValues,
+	}
+}
+
+func (cfg *MetricsConfig) Parse() error {
+	if backend := os.Getenv("SERVER_METRICS_BACKEND"); backend != "" {
+		switch backend {
+		case MetricsBackendPrometheus, MetricsBackendOtel, MetricsBackendBoth:
+			cfg.Backend = backend
+		default:
+			return fmt.Errorf("%q: %w", backend, errUnknownMetricsBackend)
+		}
+	}
+
+	if exporter := os.Getenv("SERVER_METRICS_OTEL_EXPORTER"); exporter != "" {
+		if exporter != OtelExporterPrometheus && exporter != OtelExporterOTLP {
+			return fmt.Errorf("%q: %w", exporter, errUnknownOtelExporter)
+		}
+
+		cfg.OtelExporter = exporter
+	}
+
+	if err := cfg.parseHTTPLabels(); err != nil {
+		return err
+	}
+
+	return cfg.parseOTLP()
+}
+
+func (cfg *MetricsConfig) parseHTTPLabels() error {
+	if labels := os.Getenv("SERVER_METRICS_HTTP_LABELS"); labels != "" {
+		cfg.HTTPLabels = cfg.HTTPLabels[:0]
+
+		for _, label := range strings.Split(labels, ",") {
+			label = strings.TrimSpace(label)
+			if label != HTTPLabelClientIP && label != HTTPLabelUserAgent {
+				return fmt.Errorf("%q: %w", label, errUnknownHTTPLabel)
+			}
+
+			cfg.HTTPLabels = append(cfg.HTTPLabels, label)
+		}
+	}
+
+	if maxValues := os.Getenv("SERVER_METRICS_HTTP_MAX_LABEL_VALUES"); maxValues != "" {
+		var err error
+
+		if cfg.HTTPMaxLabelValues, err = strconv.Atoi(maxValues); err != nil {
+			return err
+		}
+
+		if cfg.HTTPMaxLabelValues <= 0 {
+			return fmt.Errorf("%d: %w", cfg.HTTPMaxLabelValues, errInvalidMaxLabelValues)
+		}
+	}
+
+	return nil
+}
+
+// HTTPLabel returns true when the opt-in label of HTTP metrics is enabled.
+func (cfg *MetricsConfig) HTTPLabel(label string) bool {
+	for _, enabled := range cfg.HTTPLabels {
+		if enabled == label {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cfg *MetricsConfig) parseOTLP() error {
+	var err error
+
+	if endpoint := os.Getenv("SERVER_METRICS_OTLP_ENDPOINT"); endpoint != "" {
+		cfg.OTLPEndpoint = endpoint
+	}
+
+	if interval := os.Getenv("SERVER_METRICS_OTLP_INTERVAL"); interval != "" {
+		if cfg.OTLPInterval, err = time.ParseDuration(interval); err != nil {
+			return err
+		}
+	}
+
+	if temporality := os.Getenv("SERVER_METRICS_OTLP_TEMPORALITY"); temporality != "" {
+		if cfg.OTLPTemporality, err = otlp.ParseTemporality(temporality); err != nil {
+			return err
+		}
+	}
+
+	if gzip := os.Getenv("SERVER_METRICS_OTLP_GZIP"); gzip != "" {
+		if cfg.OTLPGzip, err = strconv.ParseBool(gzip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Prometheus returns true when metrics are collected by Prometheus client.
+func (cfg *MetricsConfig) Prometheus() bool {
+	return cfg.Backend == MetricsBackendPrometheus || cfg.Backend == MetricsBackendBoth
+}
+
+// Otel returns true when metrics are collected by OpenTelemetry.
+func (cfg *MetricsConfig) Otel() bool {
+	return cfg.Backend == MetricsBackendOtel || cfg.Backend == MetricsBackendBoth
+}
+
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+	TracingExporterOTLP   = "otlp"
+)
+
+var errUnknownTracingExporter = errors.New("tracing exporter should be none, stdout, file or otlp")
+
+type TracingConfig struct {
+	// Exporter is the destination of the finished spans. Tracing is disabled
+	// when the exporter is none.
+	Exporter     string
+	FilePath     string
+	OTLPEndpoint string
+	SampleRatio  float64
+}
+
+func NewTracingConfig() *TracingConfig {
+	return &TracingConfig{
+		Exporter:     TracingExporterNone,
+		FilePath:     "traces.json",
+		OTLPEndpoint: "http://localhost:4318",
+		SampleRatio:  1,
+	}
+}
+
+func (cfg *TracingConfig) Parse() error {
+	if exporter := os.Getenv("SERVER_TRACING_EXPORTER"); exporter != "" {
+		switch exporter {
+		case TracingExporterNone, TracingExporterStdout, TracingExporterFile, TracingExporterOTLP:
+			cfg.Exporter = exporter
+		default:
+			return fmt.Errorf("%q: %w", exporter, errUnknownTracingExporter)
+		}
+	}
+
+	if path := os.Getenv("SERVER_TRACING_FILE_PATH"); path != "" {
+		cfg.FilePath = path
+	}
+
+	if endpoint := os.Getenv("SERVER_TRACING_OTLP_ENDPOINT"); endpoint != "" {
+		cfg.OTLPEndpoint = endpoint
+	}
+
+	if ratio := os.Getenv("SERVER_TRACING_SAMPLE_RATIO"); ratio != "" {
+		var err error
+
+		if cfg.SampleRatio, err = strconv.ParseFloat(ratio, 64); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Enabled returns true when spans are recorded and exported.
+func (cfg *TracingConfig) Enabled() bool {
+	return cfg.Exporter != TracingExporterNone
+}
+
 type HTTPConfig struct {
 	Address string
 }
@@ -28,57 +299,465 @@ func (cfg *HTTPConfig) Parse() error {
 
 type MonitorConfig struct {
 	Address string
+
+	// AdminToken is the bearer token which is required to change the log
+	// levels. The levels could not be changed when it is empty.
+	AdminToken string
+
+	HealthDiskPath         string
+	HealthDiskMinFreeBytes uint64
 }
 
 func NewMonitorConfig() *MonitorConfig {
 	return &MonitorConfig{
 		Address: "127.0.0.1:9090",
+
+		AdminToken: "",
+
+		HealthDiskPath:         "",
+		HealthDiskMinFreeBytes: 0,
 	}
 }
 
 func (cfg *MonitorConfig) Parse() error {
+	var err error
+
 	if addr := os.Getenv("SERVER_MONITOR_ADDRESS"); addr != "" {
 		cfg.Address = addr
 	}
 
+	if token := os.Getenv("SERVER_MONITOR_ADMIN_TOKEN"); token != "" {
+		cfg.AdminToken = token
+	}
+
+	if path := os.Getenv("SERVER_HEALTH_DISK_PATH"); path != "" {
+		cfg.HealthDiskPath = path
+	}
+
+	if minFree := os.Getenv("SERVER_HEALTH_DISK_MIN_FREE_BYTES"); minFree != "" {
+		if cfg.HealthDiskMinFreeBytes, err = strconv.ParseUint(minFree, 10, 64); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+var errInvalidShard = errors.New("shard should be in name=dsn format with unique name without dots")
+
+type ShardConfig struct {
+	Name string
+	Dsn  string
+}
+
 type PerconaConfig struct {
-	Dsn string
+	Dsn           string
+	Shards        []ShardConfig
+	StmtCacheSize int
+	CheckSchema   bool
+
+	ReplicaDsns          []string
+	MaxReplicationLag    time.Duration
+	ReplicaCheckInterval time.Duration
+
+	ConnectTimeout time.Duration
+	PingTimeout    time.Duration
+	CreateTimeout  time.Duration
+	ListTimeout    time.Duration
+	GetTimeout     time.Duration
+
+	CircuitBreakerEnabled      bool
+	CircuitBreakerFailureRatio float64
+	CircuitBreakerMinRequests  uint64
+	CircuitBreakerWindow       time.Duration
+	CircuitBreakerOpenTimeout  time.Duration
 }
 
 func NewPerconaConfig() *PerconaConfig {
 	return &PerconaConfig{
-		Dsn: "",
+		Dsn:           "",
+		Shards:        nil,
+		StmtCacheSize: percona.DefaultStmtCacheSize,
+		CheckSchema:   false,
+
+		ReplicaDsns:          nil,
+		MaxReplicationLag:    percona.DefaultMaxReplicationLag,
+		ReplicaCheckInterval: percona.DefaultReplicaCheckInterval,
+
+		ConnectTimeout: percona.DefaultConnectTimeout,
+		PingTimeout:    percona.DefaultPingTimeout,
+		CreateTimeout:  percona.DefaultCreateTimeout,
+		ListTimeout:    percona.DefaultListTimeout,
+		GetTimeout:     percona.DefaultGetTimeout,
+
+		CircuitBreakerEnabled:      true,
+		CircuitBreakerFailureRatio: percona.DefaultCircuitBreakerFailureRatio,
+		CircuitBreakerMinRequests:  percona.DefaultCircuitBreakerMinRequests,
+		CircuitBreakerWindow:       percona.DefaultCircuitBreakerWindow,
+		CircuitBreakerOpenTimeout:  percona.DefaultCircuitBreakerOpenTimeout,
 	}
 }
 
 func (cfg *PerconaConfig) Parse() error {
+	var err error
+
 	if dsn := os.Getenv("SERVER_PERCONA_DSN"); dsn != "" {
 		cfg.Dsn = dsn
 	}
 
+	if size := os.Getenv("SERVER_PERCONA_STMT_CACHE_SIZE"); size != "" {
+		if cfg.StmtCacheSize, err = strconv.Atoi(size); err != nil {
+			return err
+		}
+	}
+
+	if checkSchema := os.Getenv("SERVER_PERCONA_CHECK_SCHEMA"); checkSchema != "" {
+		if cfg.CheckSchema, err = strconv.ParseBool(checkSchema); err != nil {
+			return err
+		}
+	}
+
+	if shards := os.Getenv("SERVER_PERCONA_SHARDS"); shards != "" {
+		if cfg.Shards, err = parseShards(shards); err != nil {
+			return err
+		}
+	}
+
+	if dsns := os.Getenv("SERVER_PERCONA_REPLICA_DSNS"); dsns != "" {
+		cfg.ReplicaDsns = strings.Split(dsns, ",")
+	}
+
+	if lag := os.Getenv("SERVER_PERCONA_MAX_REPLICATION_LAG"); lag != "" {
+		if cfg.MaxReplicationLag, err = time.ParseDuration(lag); err != nil {
+			return err
+		}
+	}
+
+	if interval := os.Getenv("SERVER_PERCONA_REPLICA_CHECK_INTERVAL"); interval != "" {
+		if cfg.ReplicaCheckInterval, err = time.ParseDuration(interval); err != nil {
+			return err
+		}
+	}
+
+	if err = cfg.parseTimeouts(); err != nil {
+		return err
+	}
+
+	return cfg.parseCircuitBreaker()
+}
+
+// Dsns returns the DSN of every database which stores the schema.
+func (cfg *PerconaConfig) Dsns() []string {
+	if len(cfg.Shards) == 0 {
+		return []string{cfg.Dsn}
+	}
+
+	dsns := make([]string, len(cfg.Shards))
+	for i, shard := range cfg.Shards {
+		dsns[i] = shard.Dsn
+	}
+
+	return dsns
+}
+
+// parseShards parses the list of name=dsn pairs separated by comma.
+func parseShards(value string) ([]ShardConfig, error) {
+	var (
+		pairs  = strings.Split(value, ",")
+		shards = make([]ShardConfig, 0, len(pairs))
+		names  = make(map[string]struct{}, len(pairs))
+	)
+
+	for _, pair := range pairs {
+		name, dsn, ok := strings.Cut(pair, "=")
+		if _, exists := names[name]; !ok || exists || name == "" || dsn == "" ||
+			strings.Contains(name, percona.ShardIDSeparator) {
+			return nil, fmt.Errorf("%q: %w", name, errInvalidShard)
+		}
+
+		names[name] = struct{}{}
+		shards = append(shards, ShardConfig{
+			Name: name,
+			Dsn:  dsn,
+		})
+	}
+
+	return shards, nil
+}
+
+func (cfg *PerconaConfig) parseCircuitBreaker() error {
+	var err error
+
+	if enabled := os.Getenv("SERVER_PERCONA_CIRCUIT_BREAKER_ENABLED"); enabled != "" {
+		if cfg.CircuitBreakerEnabled, err = strconv.ParseBool(enabled); err != nil {
+			return err
+		}
+	}
+
+	if ratio := os.Getenv("SERVER_PERCONA_CIRCUIT_BREAKER_FAILURE_RATIO"); ratio != "" {
+		if cfg.CircuitBreakerFailureRatio, err = strconv.ParseFloat(ratio, 64); err != nil {
+			return err
+		}
+	}
+
+	if minRequests := os.Getenv("SERVER_PERCONA_CIRCUIT_BREAKER_MIN_REQUESTS"); minRequests != "" {
+		if cfg.CircuitBreakerMinRequests, err = strconv.ParseUint(minRequests, 10, 64); err != nil {
+			return err
+		}
+	}
+
+	if window := os.Getenv("SERVER_PERCONA_CIRCUIT_BREAKER_WINDOW"); window != "" {
+		if cfg.CircuitBreakerWindow, err = time.ParseDuration(window); err != nil {
+			return err
+		}
+	}
+
+	if timeout := os.Getenv("SERVER_PERCONA_CIRCUIT_BREAKER_OPEN_TIMEOUT"); timeout != "" {
+		if cfg.CircuitBreakerOpenTimeout, err = time.ParseDuration(timeout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cfg *PerconaConfig) parseTimeouts() error {
+	var err error
+
+	for env, timeout := range map[string]*time.Duration{
+		"SERVER_PERCONA_CONNECT_TIMEOUT": &cfg.ConnectTimeout,
+		"SERVER_PERCONA_PING_TIMEOUT":    &cfg.PingTimeout,
+		"SERVER_PERCONA_CREATE_TIMEOUT":  &cfg.CreateTimeout,
+		"SERVER_PERCONA_LIST_TIMEOUT":    &cfg.ListTimeout,
+		"SERVER_PERCONA_GET_TIMEOUT":     &cfg.GetTimeout,
+	} {
+		val := os.Getenv(env)
+		if val == "" {
+			continue
+		}
+
+		if *timeout, err = time.ParseDuration(val); err != nil {
+			return fmt.Errorf("%s: %w", env, err)
+		}
+	}
+
 	return nil
 }
 
+type CacheConfig struct {
+	Capacity      int
+	TTL           time.Duration
+	NegativeTTL   time.Duration
+	LookupTimeout time.Duration
+}
+
+func NewCacheConfig() *CacheConfig {
+	return &CacheConfig{
+		Capacity:      cache.DefaultCapacity,
+		TTL:           cache.DefaultTTL,
+		NegativeTTL:   cache.DefaultNegativeTTL,
+		LookupTimeout: cache.DefaultLookupTimeout,
+	}
+}
+
+func (cfg *CacheConfig) Parse() error {
+	var err error
+
+	if capacity := os.Getenv("SERVER_CACHE_CAPACITY"); capacity != "" {
+		if cfg.Capacity, err = strconv.Atoi(capacity); err != nil {
+			return err
+		}
+	}
+
+	if ttl := os.Getenv("SERVER_CACHE_TTL"); ttl != "" {
+		if cfg.TTL, err = time.ParseDuration(ttl); err != nil {
+			return err
+		}
+	}
+
+	if ttl := os.Getenv("SERVER_CACHE_NEGATIVE_TTL"); ttl != "" {
+		if cfg.NegativeTTL, err = time.ParseDuration(ttl); err != nil {
+			return err
+		}
+	}
+
+	if timeout := os.Getenv("SERVER_CACHE_LOOKUP_TIMEOUT"); timeout != "" {
+		if cfg.LookupTimeout, err = time.ParseDuration(timeout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type SlowQueryConfig struct {
+	Threshold        time.Duration
+	SampleTick       time.Duration
+	SampleFirst      int
+	SampleThereafter int
+	Explain          bool
+	ExplainInterval  time.Duration
+	ExplainTimeout   time.Duration
+}
+
+func NewSlowQueryConfig() *SlowQueryConfig {
+	return &SlowQueryConfig{
+		Threshold:        percona.DefaultSlowQueryThreshold,
+		SampleTick:       time.Second,
+		SampleFirst:      10,  // nolint:gomnd
+		SampleThereafter: 100, // nolint:gomnd
+		Explain:          false,
+		ExplainInterval:  percona.DefaultSlowQueryExplainInterval,
+		ExplainTimeout:   percona.DefaultSlowQueryExplainTimeout,
+	}
+}
+
+func (cfg *SlowQueryConfig) Parse() error {
+	var err error
+
+	// Zero threshold disables the slow query log.
+	for env, duration := range map[string]*time.Duration{
+		"SERVER_SLOW_QUERY_THRESHOLD":        &cfg.Threshold,
+		"SERVER_SLOW_QUERY_SAMPLE_TICK":      &cfg.SampleTick,
+		"SERVER_SLOW_QUERY_EXPLAIN_INTERVAL": &cfg.ExplainInterval,
+		"SERVER_SLOW_QUERY_EXPLAIN_TIMEOUT":  &cfg.ExplainTimeout,
+	} {
+		val := os.Getenv(env)
+		if val == "" {
+			continue
+		}
+
+		if *duration, err = time.ParseDuration(val); err != nil {
+			return fmt.Errorf("%s: %w", env, err)
+		}
+	}
+
+	if first := os.Getenv("SERVER_SLOW_QUERY_SAMPLE_FIRST"); first != "" {
+		if cfg.SampleFirst, err = strconv.Atoi(first); err != nil {
+			return err
+		}
+	}
+
+	if thereafter := os.Getenv("SERVER_SLOW_QUERY_SAMPLE_THEREAFTER"); thereafter != "" {
+		if cfg.SampleThereafter, err = strconv.Atoi(thereafter); err != nil {
+			return err
+		}
+	}
+
+	if explain := os.Getenv("SERVER_SLOW_QUERY_EXPLAIN"); explain != "" {
+		if cfg.Explain, err = strconv.ParseBool(explain); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var errInvalidTenantToken = errors.New("tenant token should be in token=tenant format")
+
+type TenantConfig struct {
+	Header         string
+	Tokens         map[string]otelexample.TenantID
+	Default        otelexample.TenantID
+	MaxLabelValues int
+}
+
+func NewTenantConfig() *TenantConfig {
+	return &TenantConfig{
+		Header:         http.DefaultTenantHeader,
+		Tokens:         nil,
+		Default:        otelexample.EmptyTenantID,
+		MaxLabelValues: prometheus.DefaultMaxTenantLabelValues,
+	}
+}
+
+func (cfg *TenantConfig) Parse() error {
+	var err error
+
+	// Empty header disables resolving of the tenant from the header.
+	if header, ok := os.LookupEnv("SERVER_TENANT_HEADER"); ok {
+		cfg.Header = header
+	}
+
+	if tokens := os.Getenv("SERVER_TENANT_TOKENS"); tokens != "" {
+		if cfg.Tokens, err = parseTenantTokens(tokens); err != nil {
+			return err
+		}
+	}
+
+	if tenant := os.Getenv("SERVER_TENANT_DEFAULT"); tenant != "" {
+		cfg.Default = otelexample.TenantID(tenant)
+	}
+
+	if maxValues := os.Getenv("SERVER_TENANT_MAX_LABEL_VALUES"); maxValues != "" {
+		if cfg.MaxLabelValues, err = strconv.Atoi(maxValues); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseTenantTokens parses the list of token=tenant pairs separated by comma.
+func parseTenantTokens(value string) (map[string]otelexample.TenantID, error) {
+	pairs := strings.Split(value, ",")
+	tokens := make(map[string]otelexample.TenantID, len(pairs))
+
+	for _, pair := range pairs {
+		token, tenant, ok := strings.Cut(pair, "=")
+		if !ok || token == "" || tenant == "" {
+			return nil, fmt.Errorf("%q: %w", pair, errInvalidTenantToken)
+		}
+
+		tokens[token] = otelexample.TenantID(tenant)
+	}
+
+	return tokens, nil
+}
+
+// DefaultShutdownDelay is the time between the readiness failure and the
+// servers shutdown. It should be longer than the readiness probe period, so the
+// load balancers stop sending the requests before the server stops accepting them.
+const DefaultShutdownDelay = time.Second * 5
+
 type Config struct {
+	*StorageConfig
 	*HTTPConfig
 	*MonitorConfig
 	*PerconaConfig
+	*CacheConfig
+	*TenantConfig
+	*SlowQueryConfig
+	*MetricsConfig
+	*TracingConfig
+
+	BaseURL       *url.URL
+	ZapLevel      uberzap.AtomicLevel
+	ShutdownDelay time.Duration
 
-	BaseURL  *url.URL
-	ZapLevel uberzap.AtomicLevel
+	LogRedaction        zap.RedactionPolicy
+	LogRedactionHashKey string
 }
 
 func NewConfig() *Config {
 	return &Config{
+		StorageConfig: NewStorageConfig(),
 		HTTPConfig:    NewHTTPConfig(),
 		MonitorConfig: NewMonitorConfig(),
 		PerconaConfig: NewPerconaConfig(),
+		CacheConfig:   NewCacheConfig(),
+		TenantConfig:  NewTenantConfig(),
+
+		SlowQueryConfig: NewSlowQueryConfig(),
+		MetricsConfig:   NewMetricsConfig(),
+		TracingConfig:   NewTracingConfig(),
 
-		BaseURL:  nil,
-		ZapLevel: uberzap.NewAtomicLevelAt(uberzap.ErrorLevel),
+		BaseURL:       nil,
+		ZapLevel:      uberzap.NewAtomicLevelAt(uberzap.ErrorLevel),
+		ShutdownDelay: DefaultShutdownDelay,
+
+		LogRedaction:        zap.RedactionMask,
+		LogRedactionHashKey: "",
 	}
 }
 
@@ -86,9 +765,15 @@ func (cfg *Config) Parse() error {
 	for _, cfg := range []interface {
 		Parse() error
 	}{
+		cfg.StorageConfig,
 		cfg.HTTPConfig,
 		cfg.MonitorConfig,
 		cfg.PerconaConfig,
+		cfg.CacheConfig,
+		cfg.TenantConfig,
+		cfg.SlowQueryConfig,
+		cfg.MetricsConfig,
+		cfg.TracingConfig,
 	} {
 		if err := cfg.Parse(); err != nil {
 			return fmt.Errorf("parse config: %w", err)
@@ -111,11 +796,31 @@ func (cfg *Config) parse() error {
 		}
 	}
 
+	if policy := os.Getenv("SERVER_LOG_REDACTION"); policy != "" {
+		if cfg.LogRedaction, err = zap.ParseRedactionPolicy(policy); err != nil {
+			return err
+		}
+	}
+
+	// The random key is used when the key is not specified, so the hashes are
+	// comparable only within the single process.
+	if key := os.Getenv("SERVER_LOG_REDACTION_HASH_KEY"); key != "" {
+		cfg.LogRedactionHashKey = key
+	}
+
 	if baseURL := os.Getenv("SERVER_BASE_URL"); baseURL != "" {
 		if cfg.BaseURL, err = url.Parse(baseURL); err != nil {
 			return err
 		}
 	}
 
+	// SERVER_SHUTDOWN_DELAY is the duration like "5s" the server keeps serving
+	// after the readiness fails. The zero delay stops the server immediately.
+	if delay := os.Getenv("SERVER_SHUTDOWN_DELAY"); delay != "" {
+		if cfg.ShutdownDelay, err = time.ParseDuration(delay); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"os/signal"
 	"syscall"
 	stdtime "time"
@@ -13,10 +14,14 @@ import (
 	"github.com/morozovcookie/opentelemetry-prometheus-example/http"
 	v1 "github.com/morozovcookie/opentelemetry-prometheus-example/http/v1"
 	"github.com/morozovcookie/opentelemetry-prometheus-example/nanoid"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/metrics"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/tracing"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
 	"github.com/morozovcookie/opentelemetry-prometheus-example/prometheus"
 	"github.com/morozovcookie/opentelemetry-prometheus-example/zap"
 	prom "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/propagation"
 	uberzap "go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
@@ -27,7 +32,7 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	logger, err := initLogger(config)
+	logger, levels, err := initLogger(config)
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -41,16 +46,28 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, config, os.Args[2:]); err != nil {
+			logger.Fatal("failed to migrate schema", uberzap.Error(err))
+		}
+
+		return
+	}
+
 	group, ctx := errgroup.WithContext(ctx)
 
-	be := newBackend(config, logger)
+	be, err := newBackend(config, logger)
+	if err != nil {
+		logger.Fatal("failed to init backend", uberzap.Error(err))
+	}
+
 	if err := be.init(ctx); err != nil {
 		logger.Fatal("failed to init backend", uberzap.Error(err))
 	}
 
 	var (
 		httpServer    = initHTTPServer(be)
-		monitorServer = initMonitorServer(be)
+		monitorServer = initMonitorServer(be, levels)
 	)
 
 	logger.Info("starting application")
@@ -58,15 +75,26 @@ func main() {
 	group.Go(startServer(monitorServer, "monitor", logger))
 	group.Go(startServer(httpServer, "http", logger))
 
+	if be.replicaRouter != nil {
+		group.Go(startReplicaRouter(ctx, be.replicaRouter, logger))
+	}
+
 	logger.Info("application is started")
 
 	<-ctx.Done()
 
 	logger.Info("stopping application")
 
+	// Readiness fails first, so load balancers stop sending new requests
+	// before the server stops accepting them.
+	be.health.Shutdown()
+	stdtime.Sleep(config.ShutdownDelay)
+
 	const timeout = stdtime.Second * 5
 
-	ctx, cancel = context.WithDeadline(ctx, stdtime.Now().Add(timeout))
+	// The application context is already canceled, so the shutdown has its own
+	// context and the servers and exporters are able to finish their work.
+	ctx, cancel = context.WithDeadline(context.Background(), stdtime.Now().Add(timeout))
 	defer cancel()
 
 	if err := httpServer.Shutdown(ctx); err != nil {
@@ -81,38 +109,105 @@ func main() {
 		logger.Error("waiting for application be stopped", uberzap.Error(err))
 	}
 
+	if err := be.shutdown(ctx); err != nil {
+		logger.Error("shutdown backend", uberzap.Error(err))
+	}
+
 	logger.Info("application is stopped")
 }
 
-func initLogger(config *Config) (*uberzap.Logger, error) {
+func initLogger(config *Config) (*uberzap.Logger, *zap.LevelRegistry, error) {
+	levels := zap.NewLevelRegistry(config.ZapLevel)
+
+	// The levels of the named loggers are checked by the registry, so the core
+	// itself enables all levels.
 	loggerConfig := uberzap.NewProductionConfig()
-	loggerConfig.Level = config.ZapLevel
+	loggerConfig.Level = uberzap.NewAtomicLevelAt(uberzap.DebugLevel)
 
-	logger, err := loggerConfig.Build()
+	logger, err := loggerConfig.Build(uberzap.WrapCore(levels.Core))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return logger.Named("server"), nil
+	return logger.Named("server"), levels, nil
 }
 
 func initHTTPServer(be *backend) *http.Server {
 	router := chi.NewRouter()
-	router.Use(prometheus.HTTPHandler(prom.WrapRegistererWithPrefix("http_", be.registerer)), middleware.RealIP,
-		nanoid.RequestID(be.identifierGenerator), zap.HTTPHandler(be.logger.Named("http")))
+
+	// The server span is started first, so it covers the whole request handling.
+	if be.tracerProvider != nil {
+		router.Use(tracing.HTTPHandler(be.tracerProvider.Tracer("http"), propagation.TraceContext{}))
+	}
+
+	// The request identifier is generated before the metrics are measured, so
+	// it could be attached to the exemplars. The remote address is replaced
+	// with the forwarded one before the client IP is measured.
+	router.Use(http.TenantHandler(be.tenantResolver), nanoid.RequestID(be.identifierGenerator), middleware.RealIP)
+
+	if be.config.MetricsConfig.Prometheus() {
+		router.Use(prometheus.HTTPHandler(prom.WrapRegistererWithPrefix("http_", be.registerer), be.tenantLabeler,
+			prometheusHTTPHandlerOptions(be.config.MetricsConfig)...))
+	}
+
+	if be.config.MetricsConfig.Otel() {
+		router.Use(metrics.HTTPHandler(be.meterProvider.Meter("http"),
+			otelHTTPHandlerOptions(be.config.MetricsConfig)...))
+	}
+
+	router.Use(zap.HTTPHandler(be.logger.Named("http"), be.redactor), percona.ReadYourWritesHandler)
 
 	router.Mount(v1.UserAccountHandlerPathPrefix, v1.NewUserAccountHandler(be.config.BaseURL, be.userAccountService))
 
 	return http.NewServer(be.config.HTTPConfig.Address, router)
 }
 
-func initMonitorServer(be *backend) *http.Server {
+func prometheusHTTPHandlerOptions(cfg *MetricsConfig) []prometheus.HTTPHandlerOption {
+	opts := []prometheus.HTTPHandlerOption{
+		prometheus.WithMaxLabelValues(cfg.HTTPMaxLabelValues),
+	}
+
+	if cfg.HTTPLabel(HTTPLabelClientIP) {
+		opts = append(opts, prometheus.WithClientIPLabel())
+	}
+
+	if cfg.HTTPLabel(HTTPLabelUserAgent) {
+		opts = append(opts, prometheus.WithUserAgentLabel())
+	}
+
+	return opts
+}
+
+func otelHTTPHandlerOptions(cfg *MetricsConfig) []metrics.HTTPHandlerOption {
+	opts := []metrics.HTTPHandlerOption{
+		metrics.WithMaxAttributeValues(cfg.HTTPMaxLabelValues),
+	}
+
+	if cfg.HTTPLabel(HTTPLabelClientIP) {
+		opts = append(opts, metrics.WithClientIPAttribute())
+	}
+
+	if cfg.HTTPLabel(HTTPLabelUserAgent) {
+		opts = append(opts, metrics.WithUserAgentAttribute())
+	}
+
+	return opts
+}
+
+func initMonitorServer(be *backend, levels *zap.LevelRegistry) *http.Server {
 	router := chi.NewRouter()
-	router.Use(middleware.RealIP, nanoid.RequestID(be.identifierGenerator), zap.HTTPHandler(be.logger.Named("monitor")))
+	router.Use(middleware.RealIP, nanoid.RequestID(be.identifierGenerator),
+		zap.HTTPHandler(be.logger.Named("monitor"), be.redactor))
 
+	// The exemplars are exposed only in OpenMetrics format, which is negotiated
+	// with the scraper by Accept header.
 	var promOpts promhttp.HandlerOpts
+	promOpts.EnableOpenMetrics = true
 
 	router.Handle("/metrics", promhttp.HandlerFor(be.gatherer, promOpts))
+	router.Handle("/livez", be.health.LivenessHandler())
+	router.Handle("/readyz", be.health.ReadinessHandler())
+	router.Handle("/admin/log-level", levels.Handler(be.config.MonitorConfig.AdminToken))
 
 	return http.NewServer(be.config.MonitorConfig.Address, router)
 }
@@ -128,3 +223,15 @@ func startServer(server *http.Server, name string, logger *uberzap.Logger) func(
 		return nil
 	}
 }
+
+func startReplicaRouter(ctx context.Context, router *percona.Router, logger *uberzap.Logger) func() error {
+	return func() error {
+		logger.Info("starting replica health checks")
+
+		if err := router.Run(ctx); err != nil {
+			return fmt.Errorf("checking replicas: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/migrations"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+)
+
+var errMigrateUsage = errors.New("usage: server migrate up [N] | down N | down -all | status | force VERSION")
+
+// migrateDownAllFlag is the flag which reverts all migrations. The full rollback drops the schema on every
+// shard, so it is not the default of the down command.
+const migrateDownAllFlag = "-all"
+
+func runMigrate(ctx context.Context, config *Config, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	// Every shard stores the whole schema, so the command is applied to all of them.
+	for _, dsn := range config.PerconaConfig.Dsns() {
+		if err := migrate(ctx, percona.NewMigrator(dsn, migrations.FS), args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func migrate(ctx context.Context, migrator *percona.Migrator, args []string) error {
+	command := args[0]
+
+	switch command {
+	case "up":
+		steps, err := parseMigrateArg(args[1:], 0)
+		if err != nil {
+			return err
+		}
+
+		return migrator.Up(ctx, int(steps))
+	case "down":
+		if len(args) != 2 { // nolint:gomnd
+			return errMigrateUsage
+		}
+
+		if args[1] == migrateDownAllFlag {
+			return migrator.Down(ctx, 0)
+		}
+
+		steps, err := parseMigrateArg(args[1:], 0)
+		if err != nil {
+			return err
+		}
+
+		// Zero steps reverts all migrations, so it should be requested by the flag.
+		if steps <= 0 {
+			return errMigrateUsage
+		}
+
+		return migrator.Down(ctx, int(steps))
+	case "force":
+		if len(args) != 2 { // nolint:gomnd
+			return errMigrateUsage
+		}
+
+		version, err := parseMigrateArg(args[1:], 0)
+		if err != nil {
+			return err
+		}
+
+		return migrator.Force(ctx, version)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(os.Stdout, "version: %d\ndirty: %t\nlatest: %d\npending: %v\n", status.Version,
+			status.Dirty, status.Latest, status.Pending)
+
+		return err
+	}
+
+	return errMigrateUsage
+}
+
+func parseMigrateArg(args []string, defaultValue int64) (int64, error) {
+	if len(args) == 0 {
+		return defaultValue, nil
+	}
+
+	if len(args) > 1 {
+		return 0, errMigrateUsage
+	}
+
+	val, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", errMigrateUsage.Error(), err)
+	}
+
+	return val, nil
+}
+
+func checkSchemaVersion(ctx context.Context, config *Config) error {
+	for _, dsn := range config.PerconaConfig.Dsns() {
+		status, err := percona.NewMigrator(dsn, migrations.FS).Status(ctx)
+		if err != nil {
+			return fmt.Errorf("check schema version: %w", err)
+		}
+
+		if status.IsBehind() {
+			return fmt.Errorf("check schema version: %w", &schemaBehindError{
+				status: status,
+			})
+		}
+	}
+
+	return nil
+}
+
+type schemaBehindError struct {
+	status percona.MigrationStatus
+}
+
+func (e *schemaBehindError) Error() string {
+	return fmt.Sprintf("schema version %d (dirty: %t) is behind the latest version %d", e.status.Version,
+		e.status.Dirty, e.status.Latest)
+}
@@ -3,6 +3,7 @@ package otelexample
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 var _ fmt.Stringer = (*ErrorCode)(nil)
@@ -16,6 +17,8 @@ const (
 	ErrorCodeNotFound = ErrorCode("not_found")
 	ErrorCodeConflict = ErrorCode("conflict")
 	ErrorCodeInternal = ErrorCode("internal")
+
+	ErrorCodeUnavailable = ErrorCode("unavailable")
 )
 
 // The String method is used to print values passed as an operand
@@ -80,3 +83,22 @@ func ErrorMessageFromError(err error) string {
 
 	return "an internal error has occurred"
 }
+
+// RetryAfterFromError returns the time after which the failed operation could
+// be retried, or zero if the error does not carry it.
+func RetryAfterFromError(err error) time.Duration {
+	var retryAfterErr interface {
+		RetryAfter() time.Duration
+	}
+
+	if errors.As(err, &retryAfterErr) {
+		return retryAfterErr.RetryAfter()
+	}
+
+	var customErr *Error
+	if errors.As(err, &customErr) && customErr.Err != nil {
+		return RetryAfterFromError(customErr.Err)
+	}
+
+	return 0
+}
@@ -0,0 +1,44 @@
+package health
+
+import (
+	"time"
+)
+
+// CheckOption represents an option for configure the check.
+type CheckOption interface {
+	apply(c *check)
+}
+
+type checkOptionFunc func(c *check)
+
+func (fn checkOptionFunc) apply(c *check) {
+	fn(c)
+}
+
+// DefaultCheckTimeout is the maximum time for waiting until the check will be finished.
+const DefaultCheckTimeout = time.Second
+
+// WithCheckTimeout sets up the maximum time for waiting until the check will be finished.
+func WithCheckTimeout(timeout time.Duration) CheckOption {
+	return checkOptionFunc(func(c *check) {
+		c.timeout = timeout
+	})
+}
+
+// WithCheckOptional marks the check as optional. The failed optional check is
+// reported, but it does not fail the overall status.
+func WithCheckOptional() CheckOption {
+	return checkOptionFunc(func(c *check) {
+		c.optional = true
+	})
+}
+
+// DefaultCheckCacheTTL is the time during which the check result is reused.
+const DefaultCheckCacheTTL = time.Second
+
+// WithCheckCacheTTL sets up the time during which the check result is reused.
+func WithCheckCacheTTL(ttl time.Duration) CheckOption {
+	return checkOptionFunc(func(c *check) {
+		c.cacheTTL = ttl
+	})
+}
@@ -0,0 +1,43 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Checker represents a service that can check the dependency health.
+type Checker interface {
+	// Check returns error when the dependency is unhealthy.
+	Check(ctx context.Context) error
+}
+
+var _ Checker = (CheckerFunc)(nil)
+
+// CheckerFunc is an adapter to allow the use of ordinary functions as Checker.
+type CheckerFunc func(ctx context.Context) error
+
+// Check returns error when the dependency is unhealthy.
+func (fn CheckerFunc) Check(ctx context.Context) error {
+	return fn(ctx)
+}
+
+// ErrLagTooHigh is returned when the lag exceeds the maximum allowed value.
+var ErrLagTooHigh = errors.New("lag is too high")
+
+// LagChecker returns Checker which fails when the lag reported by the function exceeds the maximum value.
+func LagChecker(lag func(ctx context.Context) (time.Duration, error), maxLag time.Duration) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		val, err := lag(ctx)
+		if err != nil {
+			return err
+		}
+
+		if val > maxLag {
+			return fmt.Errorf("%s > %s: %w", val, maxLag, ErrLagTooHigh)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotEnoughDiskSpace is returned when the free disk space is lower than the minimum value.
+var ErrNotEnoughDiskSpace = errors.New("not enough disk space")
+
+// DiskSpaceChecker returns Checker which fails when the free space of the file system
+// which contains the path is lower than the minimum value.
+func DiskSpaceChecker(path string, minFreeBytes uint64) Checker {
+	return CheckerFunc(func(_ context.Context) error {
+		free, err := freeDiskSpace(path)
+		if err != nil {
+			return fmt.Errorf("check disk space: %w", err)
+		}
+
+		if free < minFreeBytes {
+			return fmt.Errorf("%d bytes free on %s: %w", free, path, ErrNotEnoughDiskSpace)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd
+
+package health
+
+import (
+	"errors"
+)
+
+var errDiskSpaceNotSupported = errors.New("disk space check is not supported on this platform")
+
+func freeDiskSpace(_ string) (uint64, error) {
+	return 0, errDiskSpaceNotSupported
+}
@@ -0,0 +1,262 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrShuttingDown is returned by the readiness check when the application is shutting down.
+var ErrShuttingDown = errors.New("application is shutting down")
+
+// Status is the result of the check.
+type Status string
+
+const (
+	StatusOK     = Status("ok")
+	StatusFailed = Status("failed")
+)
+
+// Result is the result of the single check.
+type Result struct {
+	// Name is the check name.
+	Name string `json:"name"`
+
+	// Status is the check status.
+	Status Status `json:"status"`
+
+	// Error is the reason why the check was failed.
+	Error string `json:"error,omitempty"`
+
+	// Optional is true when the failed check does not fail the overall status.
+	Optional bool `json:"optional,omitempty"`
+
+	// Duration is the time which was spent on the check.
+	Duration string `json:"duration"`
+
+	// CheckedAt is the time when the check was performed.
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Report is the result of all checks.
+type Report struct {
+	// Status is the overall status.
+	Status Status `json:"status"`
+
+	// Checks is the list of results of every check.
+	Checks []Result `json:"checks"`
+}
+
+type check struct {
+	name    string
+	checker Checker
+
+	timeout  time.Duration
+	cacheTTL time.Duration
+	optional bool
+
+	mu     sync.Mutex
+	result Result
+}
+
+func (c *check) run(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.result.CheckedAt.IsZero() && time.Since(c.result.CheckedAt) < c.cacheTTL {
+		return c.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(ctx)
+
+	c.result = Result{
+		Name:      c.name,
+		Status:    StatusOK,
+		Error:     "",
+		Optional:  c.optional,
+		Duration:  time.Since(start).Round(time.Microsecond).String(),
+		CheckedAt: start.UTC(),
+	}
+
+	if err != nil {
+		c.result.Status, c.result.Error = StatusFailed, err.Error()
+	}
+
+	return c.result
+}
+
+// Health contains the liveness and readiness checks of the application.
+type Health struct {
+	mu              sync.RWMutex
+	livenessChecks  []*check
+	readinessChecks []*check
+
+	shuttingDown int32
+}
+
+// NewHealth returns a new Health instance.
+func NewHealth() *Health {
+	return &Health{
+		mu:              sync.RWMutex{},
+		livenessChecks:  nil,
+		readinessChecks: nil,
+
+		shuttingDown: 0,
+	}
+}
+
+// AddLivenessCheck adds check which should fail only when the application
+// could not recover without restart.
+func (h *Health) AddLivenessCheck(name string, checker Checker, opts ...CheckOption) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.livenessChecks = append(h.livenessChecks, newCheck(name, checker, opts...))
+}
+
+// AddReadinessCheck adds check which should fail when the application could
+// not serve requests.
+func (h *Health) AddReadinessCheck(name string, checker Checker, opts ...CheckOption) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.readinessChecks = append(h.readinessChecks, newCheck(name, checker, opts...))
+}
+
+func newCheck(name string, checker Checker, opts ...CheckOption) *check {
+	c := &check{
+		name:    name,
+		checker: checker,
+
+		timeout:  DefaultCheckTimeout,
+		cacheTTL: DefaultCheckCacheTTL,
+		optional: false,
+
+		mu:     sync.Mutex{},
+		result: Result{}, // nolint:exhaustivestruct
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	return c
+}
+
+// Shutdown makes the readiness check fail, so load balancers stop sending
+// new requests to the application.
+func (h *Health) Shutdown() {
+	atomic.StoreInt32(&h.shuttingDown, 1)
+}
+
+// Liveness runs the liveness checks.
+func (h *Health) Liveness(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := h.livenessChecks
+	h.mu.RUnlock()
+
+	return runChecks(ctx, checks)
+}
+
+// Readiness runs the readiness checks.
+func (h *Health) Readiness(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := h.readinessChecks
+	h.mu.RUnlock()
+
+	report := runChecks(ctx, checks)
+
+	if atomic.LoadInt32(&h.shuttingDown) == 1 {
+		report.Status = StatusFailed
+		report.Checks = append(report.Checks, Result{
+			Name:      "shutdown",
+			Status:    StatusFailed,
+			Error:     ErrShuttingDown.Error(),
+			Optional:  false,
+			Duration:  "0s",
+			CheckedAt: time.Now().UTC(),
+		})
+	}
+
+	return report
+}
+
+func runChecks(ctx context.Context, checks []*check) Report {
+	report := Report{
+		Status: StatusOK,
+		Checks: make([]Result, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(len(checks))
+
+	for i, c := range checks {
+		go func(i int, c *check) {
+			defer wg.Done()
+
+			report.Checks[i] = c.run(ctx)
+		}(i, c)
+	}
+
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK && !result.Optional {
+			report.Status = StatusFailed
+		}
+	}
+
+	return report
+}
+
+// LivenessHandler returns handler which serves the liveness checks.
+func (h *Health) LivenessHandler() http.Handler {
+	return reportHandler(h.Liveness)
+}
+
+// ReadinessHandler returns handler which serves the readiness checks.
+func (h *Health) ReadinessHandler() http.Handler {
+	return reportHandler(h.Readiness)
+}
+
+// reportHandler writes the plain status, or the detailed JSON report when
+// the verbose query parameter is passed.
+func reportHandler(fn func(ctx context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var (
+			report = fn(request.Context())
+			status = http.StatusOK
+		)
+
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		writer.Header().Set("Cache-Control", "no-store")
+
+		if _, verbose := request.URL.Query()["verbose"]; !verbose {
+			writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			writer.WriteHeader(status)
+
+			_, _ = writer.Write([]byte(report.Status))
+
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(status)
+
+		if err := json.NewEncoder(writer).Encode(report); err != nil {
+			panic(err)
+		}
+	})
+}
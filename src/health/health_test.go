@@ -0,0 +1,29 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/health"
+)
+
+func TestHealth_Readiness_Optional(t *testing.T) {
+	t.Parallel()
+
+	h := health.NewHealth()
+	h.AddReadinessCheck("primary", health.CheckerFunc(func(context.Context) error { return nil }))
+	h.AddReadinessCheck("replica", health.CheckerFunc(func(context.Context) error {
+		return errors.New("replica is lagging")
+	}), health.WithCheckOptional())
+
+	report := h.Readiness(context.Background())
+
+	if report.Status != health.StatusOK {
+		t.Fatalf("failed optional check fails the readiness: %+v", report)
+	}
+
+	if replica := report.Checks[1]; replica.Status != health.StatusFailed || !replica.Optional {
+		t.Errorf("optional check is not reported: %+v", replica)
+	}
+}
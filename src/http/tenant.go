@@ -0,0 +1,91 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+)
+
+// TenantResolver represents a service that can resolve the tenant of the request.
+type TenantResolver interface {
+	// ResolveTenant returns the tenant identifier of the request.
+	ResolveTenant(request *http.Request) (otelexample.TenantID, bool)
+}
+
+var _ TenantResolver = (TenantResolverFunc)(nil)
+
+// TenantResolverFunc is an adapter to allow the use of ordinary functions as TenantResolver.
+type TenantResolverFunc func(request *http.Request) (otelexample.TenantID, bool)
+
+// ResolveTenant returns the tenant identifier of the request.
+func (fn TenantResolverFunc) ResolveTenant(request *http.Request) (otelexample.TenantID, bool) {
+	return fn(request)
+}
+
+// DefaultTenantHeader is the header which carries the tenant identifier.
+const DefaultTenantHeader = "X-Tenant-ID"
+
+// HeaderTenantResolver returns TenantResolver which takes the tenant from the header. It should be used only
+// behind a gateway which sets the header itself.
+func HeaderTenantResolver(header string) TenantResolver {
+	return TenantResolverFunc(func(request *http.Request) (otelexample.TenantID, bool) {
+		id := otelexample.TenantID(strings.TrimSpace(request.Header.Get(header)))
+
+		return id, id != otelexample.EmptyTenantID
+	})
+}
+
+// TokenTenantResolver returns TenantResolver which maps the bearer token from the Authorization header to
+// the tenant.
+func TokenTenantResolver(tokens map[string]otelexample.TenantID) TenantResolver {
+	return TenantResolverFunc(func(request *http.Request) (otelexample.TenantID, bool) {
+		const prefix = "bearer "
+
+		auth := request.Header.Get("Authorization")
+		if len(auth) <= len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+			return otelexample.EmptyTenantID, false
+		}
+
+		id, ok := tokens[strings.TrimSpace(auth[len(prefix):])]
+
+		return id, ok
+	})
+}
+
+// StaticTenantResolver returns TenantResolver which always resolves the same tenant.
+func StaticTenantResolver(id otelexample.TenantID) TenantResolver {
+	return TenantResolverFunc(func(_ *http.Request) (otelexample.TenantID, bool) {
+		return id, id != otelexample.EmptyTenantID
+	})
+}
+
+// ChainTenantResolver returns TenantResolver which returns the tenant resolved by the first succeeded resolver.
+func ChainTenantResolver(resolvers ...TenantResolver) TenantResolver {
+	return TenantResolverFunc(func(request *http.Request) (otelexample.TenantID, bool) {
+		for _, resolver := range resolvers {
+			if id, ok := resolver.ResolveTenant(request); ok {
+				return id, true
+			}
+		}
+
+		return otelexample.EmptyTenantID, false
+	})
+}
+
+// TenantHandler is the middleware which puts the resolved tenant into the request context. The request without
+// tenant is passed through, so the services decide how to handle it.
+func TenantHandler(resolver TenantResolver) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			id, ok := resolver.ResolveTenant(request)
+			if !ok {
+				next.ServeHTTP(writer, request)
+
+				return
+			}
+
+			next.ServeHTTP(writer, request.WithContext(otelexample.NewContextWithTenantID(request.Context(), id)))
+		})
+	}
+}
@@ -2,7 +2,9 @@ package v1
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
 
 	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
 )
@@ -29,6 +31,8 @@ var mapErrorCodeToStatusCode = map[otelexample.ErrorCode]int{
 	otelexample.ErrorCodeConflict: http.StatusConflict,
 
 	otelexample.ErrorCodeInternal: http.StatusInternalServerError,
+
+	otelexample.ErrorCodeUnavailable: http.StatusServiceUnavailable,
 }
 
 func encodeErrorResponse(writer http.ResponseWriter, err error) {
@@ -48,5 +52,9 @@ func encodeErrorResponse(writer http.ResponseWriter, err error) {
 		status = statusCode
 	}
 
+	if retryAfter := otelexample.RetryAfterFromError(err); retryAfter > 0 {
+		writer.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+	}
+
 	encodeResponse(writer, status, response)
 }
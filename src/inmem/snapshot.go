@@ -0,0 +1,155 @@
+package inmem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+)
+
+type snapshotUser struct {
+	ID        otelexample.ID `json:"id"`
+	FirstName string         `json:"firstName"`
+	LastName  string         `json:"lastName"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+type snapshotUserAccount struct {
+	ID        otelexample.ID `json:"id"`
+	Username  string         `json:"username"`
+	User      snapshotUser   `json:"user"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+type snapshot struct {
+	Tenants map[otelexample.TenantID][]snapshotUserAccount `json:"tenants"`
+}
+
+// Save writes all user accounts as JSON.
+func (svc *UserAccountService) Save(writer io.Writer) error {
+	svc.mu.RLock()
+
+	snap := snapshot{
+		Tenants: make(map[otelexample.TenantID][]snapshotUserAccount, len(svc.tenants)),
+	}
+
+	for tenantID, store := range svc.tenants {
+		accounts := make([]snapshotUserAccount, len(store.accounts))
+
+		for i, ua := range store.accounts {
+			accounts[i] = snapshotUserAccount{
+				ID:       ua.ID,
+				Username: ua.Username,
+				User: snapshotUser{
+					ID:        ua.User.ID,
+					FirstName: ua.User.FirstName,
+					LastName:  ua.User.LastName,
+					CreatedAt: ua.User.CreatedAt,
+				},
+				CreatedAt: ua.CreatedAt,
+			}
+		}
+
+		snap.Tenants[tenantID] = accounts
+	}
+
+	svc.mu.RUnlock()
+
+	if err := json.NewEncoder(writer).Encode(snap); err != nil {
+		return fmt.Errorf("save user accounts: %w", err)
+	}
+
+	return nil
+}
+
+// Load replaces all user accounts with ones read from JSON.
+func (svc *UserAccountService) Load(reader io.Reader) error {
+	var snap snapshot
+	if err := json.NewDecoder(reader).Decode(&snap); err != nil {
+		return fmt.Errorf("load user accounts: %w", err)
+	}
+
+	tenants := make(map[otelexample.TenantID]*tenantStore, len(snap.Tenants))
+
+	for tenantID, accounts := range snap.Tenants {
+		store := newTenantStore()
+
+		for _, ua := range accounts {
+			store.add(&otelexample.UserAccount{
+				ID:       ua.ID,
+				Username: ua.Username,
+				User: &otelexample.User{
+					ID:        ua.User.ID,
+					FirstName: ua.User.FirstName,
+					LastName:  ua.User.LastName,
+					CreatedAt: ua.User.CreatedAt,
+				},
+				CreatedAt: ua.CreatedAt,
+			})
+		}
+
+		tenants[tenantID] = store
+	}
+
+	svc.mu.Lock()
+	svc.tenants = tenants
+	svc.mu.Unlock()
+
+	return nil
+}
+
+// SaveFile writes all user accounts to the file. The file is replaced
+// atomically, so the previous snapshot is kept when saving fails.
+func (svc *UserAccountService) SaveFile(path string) (err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("save user accounts: %w", err)
+	}
+
+	defer func(name string, err *error) {
+		if *err != nil {
+			_ = os.Remove(name)
+		}
+	}(file.Name(), &err)
+
+	if err = svc.Save(file); err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("save user accounts: %w", err)
+	}
+
+	if err = os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("save user accounts: %w", err)
+	}
+
+	return nil
+}
+
+// LoadFile reads all user accounts from the file. Missing file is not an
+// error, so the first start begins with the empty storage.
+func (svc *UserAccountService) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("load user accounts: %w", err)
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	return svc.Load(file)
+}
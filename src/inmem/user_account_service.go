@@ -0,0 +1,182 @@
+package inmem
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+)
+
+// tenantStore keeps user accounts of the single tenant ordered by the creation time and then by the identifier,
+// the same way the database backed service lists them.
+type tenantStore struct {
+	accounts   []*otelexample.UserAccount
+	byID       map[otelexample.ID]*otelexample.UserAccount
+	byUsername map[string]*otelexample.UserAccount
+}
+
+func newTenantStore() *tenantStore {
+	return &tenantStore{
+		accounts:   make([]*otelexample.UserAccount, 0),
+		byID:       make(map[otelexample.ID]*otelexample.UserAccount),
+		byUsername: make(map[string]*otelexample.UserAccount),
+	}
+}
+
+func (store *tenantStore) add(ua *otelexample.UserAccount) {
+	i := sort.Search(len(store.accounts), func(i int) bool {
+		if !store.accounts[i].CreatedAt.Equal(ua.CreatedAt) {
+			return store.accounts[i].CreatedAt.After(ua.CreatedAt)
+		}
+
+		return store.accounts[i].ID > ua.ID
+	})
+
+	store.accounts = append(store.accounts, nil)
+	copy(store.accounts[i+1:], store.accounts[i:])
+	store.accounts[i] = ua
+
+	store.byID[ua.ID] = ua
+	store.byUsername[ua.Username] = ua
+}
+
+var _ otelexample.UserAccountService = (*UserAccountService)(nil)
+
+// UserAccountService represents a service for managing UserAccount data which
+// are stored in memory. It behaves the same way as the database backed
+// service, so it could be used for development and tests.
+type UserAccountService struct {
+	identifierGenerator otelexample.IdentifierGenerator
+	timer               otelexample.Timer
+
+	mu      sync.RWMutex
+	tenants map[otelexample.TenantID]*tenantStore
+}
+
+// NewUserAccountService returns a new instance of UserAccountService.
+func NewUserAccountService(
+	identifierGenerator otelexample.IdentifierGenerator,
+	timer otelexample.Timer,
+) *UserAccountService {
+	return &UserAccountService{
+		identifierGenerator: identifierGenerator,
+		timer:               timer,
+
+		mu:      sync.RWMutex{},
+		tenants: make(map[otelexample.TenantID]*tenantStore),
+	}
+}
+
+// CreateUserAccount creates a new user account.
+func (svc *UserAccountService) CreateUserAccount(ctx context.Context, ua *otelexample.UserAccount) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("create user account: %w", err)
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	store, ok := svc.tenants[tenantID]
+	if !ok {
+		store = newTenantStore()
+		svc.tenants[tenantID] = store
+	}
+
+	if _, exists := store.byUsername[ua.Username]; exists {
+		return fmt.Errorf("create user account: %w", &otelexample.Error{
+			Code:    otelexample.ErrorCodeConflict,
+			Message: fmt.Sprintf(`user account with username "%s" already exist`, ua.Username),
+			Err:     nil,
+		})
+	}
+
+	ua.User.ID, ua.User.CreatedAt = svc.identifierGenerator.GenerateIdentifier(ctx), svc.timer.Time(ctx)
+	ua.ID, ua.CreatedAt = svc.identifierGenerator.GenerateIdentifier(ctx), svc.timer.Time(ctx)
+
+	store.add(ua.Clone())
+
+	return nil
+}
+
+// FindUserAccounts returns a list of user accounts.
+func (svc *UserAccountService) FindUserAccounts(
+	ctx context.Context,
+	opts otelexample.FindOptions,
+) (
+	*otelexample.FindUserAccountsResult,
+	error,
+) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("find user accounts: %w", err)
+	}
+
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+
+	result := &otelexample.FindUserAccountsResult{
+		HasNext: false,
+		Total:   0,
+		Options: opts,
+		Data:    make([]*otelexample.UserAccount, 0, opts.Limit()),
+	}
+
+	store, ok := svc.tenants[tenantID]
+	if !ok {
+		return result, nil
+	}
+
+	total := uint64(len(store.accounts))
+
+	result.Total, result.HasNext = total, total > opts.Offset()+opts.Limit()
+
+	for i := opts.Offset(); i < total && i < opts.Offset()+opts.Limit(); i++ {
+		result.Data = append(result.Data, store.accounts[i].Clone())
+	}
+
+	return result, nil
+}
+
+// FindUserAccountByID returns user account by unique identifier.
+func (svc *UserAccountService) FindUserAccountByID(
+	ctx context.Context,
+	id otelexample.ID,
+) (
+	*otelexample.UserAccount,
+	error,
+) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("find user account by id: %w", err)
+	}
+
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+
+	if store, ok := svc.tenants[tenantID]; ok {
+		if ua, ok := store.byID[id]; ok {
+			return ua.Clone(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("find user account by id: %w", &otelexample.Error{
+		Code:    otelexample.ErrorCodeNotFound,
+		Message: "user account does not exist",
+		Err:     nil,
+	})
+}
+
+func tenantFromContext(ctx context.Context) (otelexample.TenantID, error) {
+	if id, ok := otelexample.TenantIDFromContext(ctx); ok {
+		return id, nil
+	}
+
+	return otelexample.EmptyTenantID, &otelexample.Error{
+		Code:    otelexample.ErrorCodeInvalid,
+		Message: "tenant is not specified",
+		Err:     nil,
+	}
+}
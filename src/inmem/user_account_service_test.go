@@ -0,0 +1,21 @@
+package inmem_test
+
+import (
+	"testing"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/inmem"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/otelexampletest"
+)
+
+func TestUserAccountService(t *testing.T) {
+	t.Parallel()
+
+	otelexampletest.RunUserAccountServiceSuite(t, func(
+		_ *testing.T,
+		identifierGenerator otelexample.IdentifierGenerator,
+		timer otelexample.Timer,
+	) otelexample.UserAccountService {
+		return inmem.NewUserAccountService(identifierGenerator, timer)
+	})
+}
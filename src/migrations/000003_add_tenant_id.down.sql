@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE user_accounts DROP INDEX tenant_row_id_idx;
+ALTER TABLE user_accounts DROP CONSTRAINT tenant_username_unique_idx;
+ALTER TABLE user_accounts ADD CONSTRAINT username_unique_idx UNIQUE (username);
+ALTER TABLE user_accounts DROP COLUMN tenant_id;
+
+ALTER TABLE users DROP INDEX tenant_user_id_idx;
+ALTER TABLE users DROP COLUMN tenant_id;
+
+COMMIT;
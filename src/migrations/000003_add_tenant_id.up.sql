@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default'
+    COMMENT 'tenant unique identifier' AFTER row_id;
+ALTER TABLE users ADD INDEX tenant_user_id_idx (tenant_id, user_id);
+
+ALTER TABLE user_accounts ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default'
+    COMMENT 'tenant unique identifier' AFTER row_id;
+ALTER TABLE user_accounts DROP CONSTRAINT username_unique_idx;
+ALTER TABLE user_accounts ADD CONSTRAINT tenant_username_unique_idx UNIQUE (tenant_id, username);
+ALTER TABLE user_accounts ADD INDEX tenant_row_id_idx (tenant_id, row_id);
+
+COMMIT;
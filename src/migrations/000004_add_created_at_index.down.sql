@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE user_accounts DROP INDEX tenant_created_at_idx;
+
+COMMIT;
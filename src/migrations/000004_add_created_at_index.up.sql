@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE user_accounts ADD INDEX tenant_created_at_idx (tenant_id, created_at, user_account_id);
+
+COMMIT;
@@ -0,0 +1,12 @@
+// Package migrations contains the database schema migrations which are
+// embedded into the server binary.
+package migrations
+
+import (
+	"embed"
+)
+
+// FS is the file system with migration files.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+)
+
+// OtherAttributeValue is the attribute value which replaces the values over the limit.
+const OtherAttributeValue = "__other__"
+
+// DefaultMaxAttributeValues is the default number of distinct attribute sets which are measured per instrument.
+const DefaultMaxAttributeValues = 1000
+
+// AttributeLimiter caps the number of distinct attribute sets of the single instrument. The sets over the limit
+// have their unbounded attributes folded into OtherAttributeValue, so the number of time series does not grow
+// with the number of hosts, clients or any other values which are controlled by the callers.
+type AttributeLimiter struct {
+	mu sync.RWMutex
+
+	maxValues int
+	folded    map[attribute.Key]struct{}
+	values    map[attribute.Distinct]struct{}
+
+	dropped      syncint64.Counter
+	droppedAttrs []attribute.KeyValue
+}
+
+// NewAttributeLimiter returns a new instance of AttributeLimiter. The folded are the keys of the attributes which
+// values are replaced when the limit is reached, the dropped counts the measurements with the replaced values.
+func NewAttributeLimiter(
+	maxValues int,
+	dropped syncint64.Counter,
+	instrument string,
+	folded ...attribute.Key,
+) *AttributeLimiter {
+	limiter := &AttributeLimiter{
+		mu: sync.RWMutex{},
+
+		maxValues: maxValues,
+		folded:    make(map[attribute.Key]struct{}, len(folded)),
+		values:    make(map[attribute.Distinct]struct{}),
+
+		dropped:      dropped,
+		droppedAttrs: []attribute.KeyValue{attribute.String("instrument", instrument)},
+	}
+
+	for _, key := range folded {
+		limiter.folded[key] = struct{}{}
+	}
+
+	return limiter
+}
+
+// Limit returns the attributes to measure. The first sets up to the limit are measured as is, the attributes
+// are copied when they are folded.
+func (l *AttributeLimiter) Limit(ctx context.Context, attrs []attribute.KeyValue) []attribute.KeyValue {
+	set := attribute.NewSet(attrs...)
+	key := set.Equivalent()
+
+	l.mu.RLock()
+	_, known := l.values[key]
+	l.mu.RUnlock()
+
+	if known {
+		return attrs
+	}
+
+	l.mu.Lock()
+
+	if _, known = l.values[key]; known {
+		l.mu.Unlock()
+
+		return attrs
+	}
+
+	if len(l.values) < l.maxValues {
+		l.values[key] = struct{}{}
+		l.mu.Unlock()
+
+		return attrs
+	}
+
+	l.mu.Unlock()
+
+	l.dropped.Add(ctx, 1, l.droppedAttrs...)
+
+	folded := make([]attribute.KeyValue, len(attrs))
+
+	for i, attr := range attrs {
+		if _, ok := l.folded[attr.Key]; ok {
+			attr = attr.Key.String(OtherAttributeValue)
+		}
+
+		folded[i] = attr
+	}
+
+	return folded
+}
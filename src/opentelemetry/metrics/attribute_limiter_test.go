@@ -0,0 +1,103 @@
+package metrics_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+)
+
+// droppedCounter records the increments of the dropped measurements.
+type droppedCounter struct {
+	syncint64.Counter
+
+	mu    sync.Mutex
+	attrs [][]attribute.KeyValue
+}
+
+func (c *droppedCounter) Add(_ context.Context, incr int64, attrs ...attribute.KeyValue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := int64(0); i < incr; i++ {
+		c.attrs = append(c.attrs, attrs)
+	}
+}
+
+func TestAttributeLimiter_Limit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		dropped = &droppedCounter{Counter: nil, mu: sync.Mutex{}, attrs: nil}
+		limiter = metrics.NewAttributeLimiter(2, dropped, "request_duration", "target", "client_ip")
+	)
+
+	attrs := func(method, target, clientIP string) []attribute.KeyValue {
+		return []attribute.KeyValue{
+			attribute.String("method", method),
+			attribute.String("target", target),
+			attribute.String("client_ip", clientIP),
+		}
+	}
+
+	for _, tc := range []struct {
+		attrs    []attribute.KeyValue
+		expected []attribute.KeyValue
+	}{
+		{
+			attrs:    attrs("GET", "/users", "10.0.0.1"),
+			expected: attrs("GET", "/users", "10.0.0.1"),
+		},
+		{
+			attrs:    attrs("GET", "/accounts", "10.0.0.1"),
+			expected: attrs("GET", "/accounts", "10.0.0.1"),
+		},
+		// The set over the limit has only the unbounded attributes folded.
+		{
+			attrs:    attrs("POST", "/users", "10.0.0.2"),
+			expected: attrs("POST", metrics.OtherAttributeValue, metrics.OtherAttributeValue),
+		},
+		// The known set is still measured as is after the limit is reached, regardless of the order.
+		{
+			attrs: []attribute.KeyValue{
+				attribute.String("client_ip", "10.0.0.1"),
+				attribute.String("target", "/accounts"),
+				attribute.String("method", "GET"),
+			},
+			expected: attrs("GET", "/accounts", "10.0.0.1"),
+		},
+		{
+			attrs:    attrs("GET", "/admin", "10.0.0.1"),
+			expected: attrs("GET", metrics.OtherAttributeValue, metrics.OtherAttributeValue),
+		},
+	} {
+		input := append([]attribute.KeyValue(nil), tc.attrs...)
+
+		actual, expected := attribute.NewSet(limiter.Limit(ctx, tc.attrs)...), attribute.NewSet(tc.expected...)
+		if !actual.Equals(&expected) {
+			t.Errorf("unexpected attributes %v, expected %v", actual.Encoded(attribute.DefaultEncoder()),
+				expected.Encoded(attribute.DefaultEncoder()))
+		}
+
+		// The folded attributes are copied, so the slice of the caller is not changed.
+		if passed, original := attribute.NewSet(tc.attrs...), attribute.NewSet(input...); !passed.Equals(&original) {
+			t.Errorf("attributes are changed %v", tc.attrs)
+		}
+	}
+
+	if len(dropped.attrs) != 2 {
+		t.Fatalf("unexpected dropped %v", dropped.attrs)
+	}
+
+	expected := attribute.NewSet(attribute.String("instrument", "request_duration"))
+
+	for _, attrs := range dropped.attrs {
+		if set := attribute.NewSet(attrs...); !set.Equals(&expected) {
+			t.Errorf("unexpected dropped attributes %v", attrs)
+		}
+	}
+}
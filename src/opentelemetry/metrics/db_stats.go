@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+// DBStatsProvider represents a service that can provide database connection pool statistics.
+type DBStatsProvider interface {
+	// Stats returns database connection pool statistics.
+	Stats() sql.DBStats
+}
+
+// ObserveDBStats registers asynchronous instruments which report database connection pool statistics.
+func ObserveDBStats(meter metric.Meter, provider DBStatsProvider, attrs ...attribute.KeyValue) error {
+	maxOpen, err := meter.AsyncInt64().Gauge("db.client.connections.max_open",
+		instrument.WithDescription("measures the maximum number of open connections to the database"),
+		instrument.WithUnit(unit.Dimensionless))
+	if err != nil {
+		return err
+	}
+
+	usage, err := meter.AsyncInt64().UpDownCounter("db.client.connections.usage",
+		instrument.WithDescription("measures the number of connections that are currently in the state"),
+		instrument.WithUnit(unit.Dimensionless))
+	if err != nil {
+		return err
+	}
+
+	waitCount, err := meter.AsyncInt64().Counter("db.client.connections.wait",
+		instrument.WithDescription("measures the total number of connections waited for"),
+		instrument.WithUnit(unit.Dimensionless))
+	if err != nil {
+		return err
+	}
+
+	waitDuration, err := meter.AsyncInt64().Counter("db.client.connections.wait_duration",
+		instrument.WithDescription("measures the total time blocked waiting for a new connection"),
+		instrument.WithUnit(unit.Milliseconds))
+	if err != nil {
+		return err
+	}
+
+	closed, err := meter.AsyncInt64().Counter("db.client.connections.closed",
+		instrument.WithDescription("measures the total number of connections closed by the reason"),
+		instrument.WithUnit(unit.Dimensionless))
+	if err != nil {
+		return err
+	}
+
+	var (
+		stateKey  = attribute.Key("state")
+		reasonKey = attribute.Key("reason")
+	)
+
+	return meter.RegisterCallback([]instrument.Asynchronous{
+		maxOpen, usage, waitCount, waitDuration, closed,
+	}, func(ctx context.Context) {
+		stats := provider.Stats()
+
+		maxOpen.Observe(ctx, int64(stats.MaxOpenConnections), attrs...)
+
+		usage.Observe(ctx, int64(stats.InUse), append(attrs, stateKey.String("used"))...)
+		usage.Observe(ctx, int64(stats.Idle), append(attrs, stateKey.String("idle"))...)
+
+		waitCount.Observe(ctx, stats.WaitCount, attrs...)
+		waitDuration.Observe(ctx, stats.WaitDuration.Milliseconds(), attrs...)
+
+		closed.Observe(ctx, stats.MaxIdleClosed, append(attrs, reasonKey.String("max_idle"))...)
+		closed.Observe(ctx, stats.MaxIdleTimeClosed, append(attrs, reasonKey.String("max_idle_time"))...)
+		closed.Observe(ctx, stats.MaxLifetimeClosed, append(attrs, reasonKey.String("max_lifetime"))...)
+	})
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+// DeadlineExceededCounter counts database operations which have hit their deadline.
+type DeadlineExceededCounter struct {
+	counter syncint64.Counter
+}
+
+// NewDeadlineExceededCounter returns a new instance of DeadlineExceededCounter.
+func NewDeadlineExceededCounter(meter metric.Meter) *DeadlineExceededCounter {
+	counter, err := meter.SyncInt64().Counter("operation_deadline_exceeded_total",
+		instrument.WithDescription("measures the number of operations which have hit their deadline"),
+		instrument.WithUnit(unit.Dimensionless))
+	if err != nil {
+		panic(err)
+	}
+
+	return &DeadlineExceededCounter{
+		counter: counter,
+	}
+}
+
+// HandleDeadlineExceeded increments the counter of the operation. It could be used as percona.DeadlineExceededHandler.
+func (c *DeadlineExceededCounter) HandleDeadlineExceeded(ctx context.Context, op percona.Operation) {
+	c.counter.Add(ctx, 1, attribute.String("operation", string(op)))
+}
@@ -2,10 +2,13 @@ package metrics
 
 import (
 	"bytes"
+	"io"
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/go-chi/chi/v5"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/instrument"
@@ -20,6 +23,7 @@ type response struct {
 	wrapped http.ResponseWriter
 
 	statusCode int
+	size       int64
 }
 
 // Header returns the header map that will be sent by
@@ -31,7 +35,10 @@ func (resp *response) Header() http.Header {
 
 // Write writes the data to the connection as part of an HTTP reply.
 func (resp *response) Write(bb []byte) (int, error) {
-	return resp.wrapped.Write(bb)
+	n, err := resp.wrapped.Write(bb)
+	resp.size += int64(n)
+
+	return n, err // nolint:wrapcheck
 }
 
 // WriteHeader sends an HTTP response header with the provided
@@ -42,83 +49,236 @@ func (resp *response) WriteHeader(statusCode int) {
 	resp.wrapped.WriteHeader(resp.statusCode)
 }
 
-func HTTPHandler(meter metric.Meter, attrs ...attribute.KeyValue) func(next http.Handler) http.Handler {
-	requestCount, err := meter.SyncInt64().Counter("active_requests",
+var _ io.ReadCloser = (*body)(nil)
+
+// body counts the bytes of the request body which are read by the handler.
+type body struct {
+	wrapped io.ReadCloser
+
+	size int64
+}
+
+// Read reads up to len(bb) bytes of the request body.
+func (b *body) Read(bb []byte) (int, error) {
+	n, err := b.wrapped.Read(bb)
+	b.size += int64(n)
+
+	return n, err // nolint:wrapcheck
+}
+
+// Close closes the request body.
+func (b *body) Close() error {
+	return b.wrapped.Close() // nolint:wrapcheck
+}
+
+// UnmatchedRoute is the http.route attribute value for the requests which match no route.
+const UnmatchedRoute = "unmatched"
+
+type httpMetrics struct {
+	cfg *httpHandlerConfig
+
+	activeRequests  syncint64.UpDownCounter
+	requestDuration syncint64.Histogram
+	requestSize     syncint64.Histogram
+	responseSize    syncint64.Histogram
+
+	activeLimiter       *AttributeLimiter
+	durationLimiter     *AttributeLimiter
+	requestSizeLimiter  *AttributeLimiter
+	responseSizeLimiter *AttributeLimiter
+}
+
+// HTTPHandler measures the inbound HTTP requests. The requests are reported by the matched route pattern,
+// so the requests to the different resources of the same route have the same attributes.
+func HTTPHandler(meter metric.Meter, opts ...HTTPHandlerOption) func(next http.Handler) http.Handler {
+	cfg := &httpHandlerConfig{
+		attrs:     nil,
+		clientIP:  false,
+		userAgent: false,
+		maxValues: DefaultMaxAttributeValues,
+	}
+
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+
+	activeRequests, err := meter.SyncInt64().UpDownCounter("http.server.active_requests",
 		instrument.WithDescription("measures the number of concurrent HTTP requests that are currently in-flight"),
 		instrument.WithUnit(unit.Dimensionless))
 	if err != nil {
 		panic(err)
 	}
 
-	requestDuration, err := meter.SyncInt64().Histogram("duration",
+	requestDuration, err := meter.SyncInt64().Histogram("http.server.duration",
 		instrument.WithDescription("measures the duration of the inbound HTTP request"),
 		instrument.WithUnit(unit.Milliseconds))
 	if err != nil {
 		panic(err)
 	}
 
-	return httpHandler(requestCount, requestDuration, attrs...)
+	// The histogram boundaries are shared by all instruments of the exporter, so the sizes are
+	// bucketed with the same boundaries as the durations.
+	requestSize, err := meter.SyncInt64().Histogram("http.server.request.size",
+		instrument.WithDescription("measures the size of the inbound HTTP request body which was read by the handler"),
+		instrument.WithUnit(unit.Bytes))
+	if err != nil {
+		panic(err)
+	}
+
+	responseSize, err := meter.SyncInt64().Histogram("http.server.response.size",
+		instrument.WithDescription("measures the size of the HTTP response body"),
+		instrument.WithUnit(unit.Bytes))
+	if err != nil {
+		panic(err)
+	}
+
+	droppedCounter, err := meter.SyncInt64().Counter("http.server.attribute_values.dropped",
+		instrument.WithDescription("measures the number of measurements which attribute values were folded because "+
+			"of the limit"),
+		instrument.WithUnit(unit.Dimensionless))
+	if err != nil {
+		panic(err)
+	}
+
+	// The method and the host are sent by the client as well as the others.
+	folded := []attribute.Key{
+		semconv.HTTPHostKey, semconv.HTTPMethodKey, semconv.HTTPUserAgentKey, semconv.HTTPClientIPKey,
+	}
+
+	metrics := &httpMetrics{
+		cfg: cfg,
+
+		activeRequests:  activeRequests,
+		requestDuration: requestDuration,
+		requestSize:     requestSize,
+		responseSize:    responseSize,
+
+		activeLimiter: NewAttributeLimiter(cfg.maxValues, droppedCounter, "http.server.active_requests",
+			folded...),
+		durationLimiter: NewAttributeLimiter(cfg.maxValues, droppedCounter, "http.server.duration", folded...),
+		requestSizeLimiter: NewAttributeLimiter(cfg.maxValues, droppedCounter, "http.server.request.size",
+			folded...),
+		responseSizeLimiter: NewAttributeLimiter(cfg.maxValues, droppedCounter, "http.server.response.size",
+			folded...),
+	}
+
+	return metrics.handler
 }
 
-func httpHandler(
-	counter syncint64.Counter,
-	histogram syncint64.Histogram,
-	attrs ...attribute.KeyValue,
-) func(next http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-			var (
-				resp = &response{
-					wrapped:    writer,
-					statusCode: http.StatusOK,
-				}
-				rattrs = attrs
-			)
-
-			userInfo := request.URL.User
-			request.URL.User = nil
-
-			rattrs = append(rattrs, semconv.HTTPURLKey.String(request.URL.String()),
-				semconv.HTTPTargetKey.String(request.URL.Path))
-
-			request.URL.User = userInfo
-
-			_, _, elapsed := trackOfTime(func() {
-				next.ServeHTTP(resp, request)
-			})
-
-			if ua := request.UserAgent(); ua != "" {
-				rattrs = append(rattrs, semconv.HTTPUserAgentKey.String(ua))
-			}
+func (m *httpMetrics) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		resp := &response{
+			wrapped: writer,
 
-			if request.Host != "" {
-				rattrs = append(rattrs, semconv.HTTPHostKey.String(request.Host))
-			}
+			statusCode: http.StatusOK,
+			size:       0,
+		}
 
-			flavor := new(bytes.Buffer)
-			_, _ = flavor.WriteString(strconv.Itoa(request.ProtoMajor))
+		var reqBody *body
 
-			if request.ProtoMajor == 1 {
-				_, _ = flavor.WriteRune('.')
-				_, _ = flavor.WriteString(strconv.Itoa(request.ProtoMinor))
-			}
+		if request.Body != nil {
+			reqBody = &body{
+				wrapped: request.Body,
 
-			if val := flavor.String(); val != "" {
-				attrs = append(attrs, semconv.HTTPFlavorKey.String(val))
+				size: 0,
 			}
 
-			schema := semconv.HTTPSchemeHTTP
-			if request.TLS != nil {
-				schema = semconv.HTTPSchemeHTTPS
-			}
+			request.Body = reqBody
+		}
+
+		var (
+			ctx = request.Context()
+
+			clientIP = takeClientIP(request)
+
+			// The active requests are measured with the attributes which are known before the request
+			// is served, so the increment and the decrement are applied to the same series.
+			aattrs = append(make([]attribute.KeyValue, 0, len(m.cfg.attrs)+4), m.cfg.attrs...) // nolint:gomnd
+		)
 
-			clientIP, _, _ := net.SplitHostPort(request.RemoteAddr)
+		if request.Host != "" {
+			aattrs = append(aattrs, semconv.HTTPHostKey.String(request.Host))
+		}
 
-			rattrs = append(rattrs, semconv.HTTPMethodKey.String(request.Method), schema,
-				semconv.HTTPClientIPKey.String(clientIP), semconv.HTTPStatusCodeKey.Int(resp.statusCode))
+		if val := takeHTTPFlavor(request); val != "" {
+			aattrs = append(aattrs, semconv.HTTPFlavorKey.String(val))
+		}
 
-			counter.Add(request.Context(), 1, rattrs...)
-			histogram.Record(request.Context(), elapsed.Milliseconds(), rattrs...)
+		aattrs = append(aattrs, semconv.HTTPMethodKey.String(request.Method), takeHTTPScheme(request))
+		aattrs = m.activeLimiter.Limit(ctx, aattrs)
+
+		m.activeRequests.Add(ctx, 1, aattrs...)
+
+		_, _, elapsed := trackOfTime(func() {
+			defer m.activeRequests.Add(ctx, -1, aattrs...)
+
+			next.ServeHTTP(resp, request)
 		})
+
+		rattrs := append(make([]attribute.KeyValue, 0, len(aattrs)+4), aattrs...) // nolint:gomnd
+		rattrs = append(rattrs, semconv.HTTPRouteKey.String(routePattern(request)),
+			semconv.HTTPStatusCodeKey.Int(resp.statusCode))
+
+		if ua := request.UserAgent(); m.cfg.userAgent && ua != "" {
+			rattrs = append(rattrs, semconv.HTTPUserAgentKey.String(ua))
+		}
+
+		if m.cfg.clientIP {
+			rattrs = append(rattrs, semconv.HTTPClientIPKey.String(clientIP))
+		}
+
+		m.requestDuration.Record(ctx, elapsed.Milliseconds(), m.durationLimiter.Limit(ctx, rattrs)...)
+
+		if reqBody != nil {
+			m.requestSize.Record(ctx, reqBody.size, m.requestSizeLimiter.Limit(ctx, rattrs)...)
+		}
+
+		m.responseSize.Record(ctx, resp.size, m.responseSizeLimiter.Limit(ctx, rattrs)...)
+	})
+}
+
+// takeClientIP returns the host of the remote address. The address has no port when it was replaced with
+// the forwarded one.
+func takeClientIP(request *http.Request) string {
+	if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
+		return host
+	}
+
+	return request.RemoteAddr
+}
+
+func takeHTTPFlavor(request *http.Request) string {
+	flavor := new(bytes.Buffer)
+	_, _ = flavor.WriteString(strconv.Itoa(request.ProtoMajor))
+
+	if request.ProtoMajor == 1 {
+		_, _ = flavor.WriteRune('.')
+		_, _ = flavor.WriteString(strconv.Itoa(request.ProtoMinor))
+	}
+
+	return flavor.String()
+}
+
+func takeHTTPScheme(request *http.Request) attribute.KeyValue {
+	if request.TLS != nil {
+		return semconv.HTTPSchemeHTTPS
 	}
+
+	return semconv.HTTPSchemeHTTP
+}
+
+// routePattern returns the pattern of the route which has handled the request. The routing is finished
+// after the request is served, so the pattern is not available before.
+func routePattern(request *http.Request) string {
+	routeContext := chi.RouteContext(request.Context())
+	if routeContext == nil {
+		return UnmatchedRoute
+	}
+
+	// The mount pattern and the root pattern of the sub-router are joined with the double slash.
+	if pattern := routeContext.RoutePattern(); pattern != "" {
+		return strings.ReplaceAll(pattern, "//", "/")
+	}
+
+	return UnmatchedRoute
 }
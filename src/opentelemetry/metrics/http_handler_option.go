@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// HTTPHandlerOption represents an option for configure HTTP handler metrics.
+type HTTPHandlerOption interface {
+	apply(handler *httpHandlerConfig)
+}
+
+type httpHandlerOptionFunc func(handler *httpHandlerConfig)
+
+func (fn httpHandlerOptionFunc) apply(handler *httpHandlerConfig) {
+	fn(handler)
+}
+
+type httpHandlerConfig struct {
+	attrs     []attribute.KeyValue
+	clientIP  bool
+	userAgent bool
+	maxValues int
+}
+
+// WithAttributes adds the attributes to every measurement.
+func WithAttributes(attrs ...attribute.KeyValue) HTTPHandlerOption {
+	return httpHandlerOptionFunc(func(handler *httpHandlerConfig) {
+		handler.attrs = append(handler.attrs, attrs...)
+	})
+}
+
+// WithClientIPAttribute adds the client IP attribute. Every client creates the new time series,
+// so it should be enabled only when the clients are known.
+func WithClientIPAttribute() HTTPHandlerOption {
+	return httpHandlerOptionFunc(func(handler *httpHandlerConfig) {
+		handler.clientIP = true
+	})
+}
+
+// WithUserAgentAttribute adds the user agent attribute. The value is controlled by the clients,
+// so it should be enabled only when the clients are known.
+func WithUserAgentAttribute() HTTPHandlerOption {
+	return httpHandlerOptionFunc(func(handler *httpHandlerConfig) {
+		handler.userAgent = true
+	})
+}
+
+// WithMaxAttributeValues sets up the number of distinct attribute sets which are measured per instrument.
+// The client controlled attributes of the sets over the limit are reported as OtherAttributeValue.
+func WithMaxAttributeValues(maxValues int) HTTPHandlerOption {
+	return httpHandlerOptionFunc(func(handler *httpHandlerConfig) {
+		handler.maxValues = maxValues
+	})
+}
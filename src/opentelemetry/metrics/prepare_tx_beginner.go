@@ -87,6 +87,6 @@ func (svc *PrepareTxBeginner) PrepareContext(ctx context.Context, query string)
 
 		errorCounter:  svc.errorCounter,
 		queryDuration: svc.queryDuration,
-		attrs:         svc.attrs,
+		attrs:         queryAttributes(percona.FingerprintContext(ctx, query), svc.attrs),
 	}, nil
 }
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+// SlowQueryCounter counts queries which execution took longer than the threshold.
+type SlowQueryCounter struct {
+	counter syncint64.Counter
+
+	attrs []attribute.KeyValue
+}
+
+// NewSlowQueryCounter returns a new instance of SlowQueryCounter.
+func NewSlowQueryCounter(meter metric.Meter, attrs ...attribute.KeyValue) *SlowQueryCounter {
+	counter, err := meter.SyncInt64().Counter("slow_queries_total",
+		instrument.WithDescription("measures the number of queries which execution took longer than the threshold"),
+		instrument.WithUnit(unit.Dimensionless))
+	if err != nil {
+		panic(err)
+	}
+
+	return &SlowQueryCounter{
+		counter: counter,
+
+		attrs: attrs,
+	}
+}
+
+// HandleSlowQuery increments the counter of the query. It could be used as percona.SlowQueryHandler.
+func (c *SlowQueryCounter) HandleSlowQuery(ctx context.Context, query percona.SlowQuery) {
+	c.counter.Add(ctx, 1, queryAttributes(query.Fingerprint, c.attrs)...)
+}
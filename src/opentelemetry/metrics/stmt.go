@@ -20,6 +20,16 @@ type stmt struct {
 	attrs         []attribute.KeyValue
 }
 
+// queryAttributes returns a copy of attrs extended by the attributes of the query fingerprint.
+func queryAttributes(fingerprint percona.QueryFingerprint, attrs []attribute.KeyValue) []attribute.KeyValue {
+	queryAttrs := make([]attribute.KeyValue, 0, len(attrs)+2) // nolint:gomnd
+	queryAttrs = append(queryAttrs, attrs...)
+
+	return append(queryAttrs,
+		attribute.String("db.operation", fingerprint.Operation),
+		attribute.String("db.query.name", fingerprint.Name))
+}
+
 // ExecContext executes a prepared statement with the given arguments and
 // returns a Result summarizing the effect of the statement.
 func (stmt *stmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
@@ -44,8 +54,8 @@ func (stmt *stmt) ExecContext(ctx context.Context, args ...any) (sql.Result, err
 }
 
 // QueryRowContext executes a prepared query statement with the given arguments.
-func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
-	var row *sql.Row
+func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) percona.Row {
+	var row percona.Row
 
 	_, _, elapsed := trackOfTime(func() {
 		row = stmt.wrapped.QueryRowContext(ctx, args...)
@@ -57,7 +67,7 @@ func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
 		stmt.errorCounter.Add(ctx, 1, stmt.attrs...)
 	}
 
-	return stmt.wrapped.QueryRowContext(ctx, args...)
+	return row
 }
 
 // QueryContext executes a prepared query statement with the given arguments
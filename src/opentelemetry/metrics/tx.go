@@ -31,16 +31,16 @@ func (tx *tx) PrepareContext(ctx context.Context, query string) (percona.Stmt, e
 
 		errorCounter:  tx.errorCounter,
 		queryDuration: tx.queryDuration,
-		attrs:         tx.attrs,
+		attrs:         queryAttributes(percona.FingerprintContext(ctx, query), tx.attrs),
 	}, nil
 }
 
 // Commit commits the transaction.
-func (tx *tx) Commit() error {
-	return tx.wrapped.Commit()
+func (tx *tx) Commit(ctx context.Context) error {
+	return tx.wrapped.Commit(ctx)
 }
 
 // Rollback aborts the transaction.
-func (tx *tx) Rollback() error {
-	return tx.wrapped.Rollback()
+func (tx *tx) Rollback(ctx context.Context) error {
+	return tx.wrapped.Rollback(ctx)
 }
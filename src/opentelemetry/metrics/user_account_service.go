@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+var _ otelexample.UserAccountService = (*UserAccountService)(nil)
+
+// UserAccountService measures the rate, the errors and the duration of the user account operations.
+type UserAccountService struct {
+	wrapped otelexample.UserAccountService
+
+	operationCounter  syncint64.Counter
+	errorCounter      syncint64.Counter
+	operationDuration syncint64.Histogram
+
+	attrs []attribute.KeyValue
+}
+
+// NewUserAccountService returns a new instance of UserAccountService.
+func NewUserAccountService(
+	svc otelexample.UserAccountService,
+	meter metric.Meter,
+	attrs ...attribute.KeyValue,
+) *UserAccountService {
+	var (
+		wrapper = &UserAccountService{
+			wrapped: svc,
+
+			operationCounter:  nil,
+			errorCounter:      nil,
+			operationDuration: nil,
+
+			attrs: attrs,
+		}
+
+		err error
+	)
+
+	wrapper.operationCounter, err = meter.SyncInt64().Counter("service.operations",
+		instrument.WithDescription("measures the number of the service operations"),
+		instrument.WithUnit(unit.Dimensionless))
+	if err != nil {
+		panic(err)
+	}
+
+	wrapper.errorCounter, err = meter.SyncInt64().Counter("service.errors",
+		instrument.WithDescription("measures the number of the failed service operations by the error code"),
+		instrument.WithUnit(unit.Dimensionless))
+	if err != nil {
+		panic(err)
+	}
+
+	wrapper.operationDuration, err = meter.SyncInt64().Histogram("service.duration",
+		instrument.WithDescription("measures the duration of the service operation"),
+		instrument.WithUnit(unit.Milliseconds))
+	if err != nil {
+		panic(err)
+	}
+
+	return wrapper
+}
+
+// CreateUserAccount creates a new user account.
+func (svc *UserAccountService) CreateUserAccount(ctx context.Context, ua *otelexample.UserAccount) error {
+	var err error
+
+	_, _, elapsed := trackOfTime(func() {
+		err = svc.wrapped.CreateUserAccount(ctx, ua)
+	})
+
+	svc.record(ctx, "CreateUserAccount", elapsed, err)
+
+	return err // nolint:wrapcheck
+}
+
+// FindUserAccounts returns a list of user accounts.
+func (svc *UserAccountService) FindUserAccounts(
+	ctx context.Context,
+	opts otelexample.FindOptions,
+) (
+	*otelexample.FindUserAccountsResult,
+	error,
+) {
+	var (
+		result *otelexample.FindUserAccountsResult
+		err    error
+	)
+
+	_, _, elapsed := trackOfTime(func() {
+		result, err = svc.wrapped.FindUserAccounts(ctx, opts)
+	})
+
+	svc.record(ctx, "FindUserAccounts", elapsed, err)
+
+	return result, err // nolint:wrapcheck
+}
+
+// FindUserAccountByID returns user account by unique identifier.
+func (svc *UserAccountService) FindUserAccountByID(
+	ctx context.Context,
+	id otelexample.ID,
+) (
+	*otelexample.UserAccount,
+	error,
+) {
+	var (
+		ua  *otelexample.UserAccount
+		err error
+	)
+
+	_, _, elapsed := trackOfTime(func() {
+		ua, err = svc.wrapped.FindUserAccountByID(ctx, id)
+	})
+
+	svc.record(ctx, "FindUserAccountByID", elapsed, err)
+
+	return ua, err // nolint:wrapcheck
+}
+
+func (svc *UserAccountService) record(ctx context.Context, method string, elapsed time.Duration, err error) {
+	attrs := append(make([]attribute.KeyValue, 0, len(svc.attrs)+2), svc.attrs...) // nolint:gomnd
+	attrs = append(attrs, attribute.String("method", method))
+
+	svc.operationCounter.Add(ctx, 1, attrs...)
+	svc.operationDuration.Record(ctx, elapsed.Milliseconds(), attrs...)
+
+	if err == nil {
+		return
+	}
+
+	svc.errorCounter.Add(ctx, 1, append(attrs, attribute.String("error.code",
+		otelexample.ErrorCodeFromError(err).String()))...)
+}
@@ -0,0 +1,110 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/inmem"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/metrics"
+	otelprometheus "github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/prometheus"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/otelexampletest"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+func TestUserAccountService_ErrorCode(t *testing.T) {
+	t.Parallel()
+
+	registry := prom.NewRegistry()
+
+	exporter, err := otelprometheus.NewExporter(registry, registry)
+	if err != nil {
+		t.Fatalf("exporter: %v", err)
+	}
+
+	var (
+		ctx = otelexample.NewContextWithTenantID(context.Background(), "tenant")
+		svc = metrics.NewUserAccountService(inmem.NewUserAccountService(
+			otelexampletest.NewSequenceIdentifierGenerator("id-"),
+			otelexampletest.NewStepTimer(time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), time.Second)),
+			exporter.MeterProvider().Meter("test"))
+	)
+
+	for i := 0; i < 2; i++ {
+		ua := &otelexample.UserAccount{
+			ID:       otelexample.EmptyID,
+			Username: "alice",
+			User: &otelexample.User{
+				ID:        otelexample.EmptyID,
+				FirstName: "Alice",
+				LastName:  "Liddell",
+				CreatedAt: time.Time{},
+			},
+			CreatedAt: time.Time{},
+		}
+
+		// The second account conflicts with the first one by the username.
+		if err := svc.CreateUserAccount(ctx, ua); (err != nil) != (i == 1) {
+			t.Fatalf("create user account %d: %v", i, err)
+		}
+	}
+
+	if _, err := svc.FindUserAccountByID(ctx, "unknown"); err == nil {
+		t.Fatal("user account is found")
+	}
+
+	for _, tc := range []struct {
+		labels   map[string]string
+		expected float64
+	}{
+		{
+			labels:   map[string]string{"method": "CreateUserAccount", "error_code": "conflict"},
+			expected: 1,
+		},
+		{
+			labels:   map[string]string{"method": "FindUserAccountByID", "error_code": "not_found"},
+			expected: 1,
+		},
+		{
+			labels:   map[string]string{"error_code": "internal"},
+			expected: 0,
+		},
+	} {
+		if value := counterValue(t, registry, "service_errors", tc.labels); value != tc.expected {
+			t.Errorf("service_errors%v: expected %v, got %v", tc.labels, tc.expected, value)
+		}
+	}
+}
+
+// counterValue returns the value of the counter with the given labels.
+func counterValue(t *testing.T, gatherer prom.Gatherer, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			matched := 0
+
+			for _, pair := range metric.GetLabel() {
+				if value, ok := labels[pair.GetName()]; ok && value == pair.GetValue() {
+					matched++
+				}
+			}
+
+			if matched == len(labels) {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
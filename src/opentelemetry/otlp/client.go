@@ -0,0 +1,201 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnexpectedStatus is returned when the collector has rejected the request.
+var ErrUnexpectedStatus = errors.New("unexpected status")
+
+const (
+	contentTypeJSON     = "application/json"
+	contentTypeProtobuf = "application/x-protobuf"
+)
+
+// Client sends the telemetry data to the OpenTelemetry collector with OTLP/HTTP
+// protocol.
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+	headers    map[string]string
+	gzip       bool
+
+	maxRetries    int
+	retryBackoff  time.Duration
+	maxRetryDelay time.Duration
+}
+
+// NewClient returns a new Client instance. The endpoint is the base URL of the
+// collector like http://localhost:4318.
+func NewClient(endpoint string, opts ...ClientOption) *Client {
+	client := &Client{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		httpClient: &http.Client{ // nolint:exhaustivestruct
+			Timeout: DefaultTimeout,
+		},
+		headers: nil,
+		gzip:    false,
+
+		maxRetries:    DefaultMaxRetries,
+		retryBackoff:  DefaultRetryBackoff,
+		maxRetryDelay: DefaultMaxRetryDelay,
+	}
+
+	for _, opt := range opts {
+		opt.apply(client)
+	}
+
+	return client
+}
+
+// Export sends the request to the collector path like /v1/traces in JSON encoding.
+func (c *Client) Export(ctx context.Context, path string, request any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	if err = c.send(ctx, path, contentTypeJSON, body); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	return nil
+}
+
+// ExportProtobuf sends the request which is already encoded with protobuf to
+// the collector path like /v1/metrics.
+func (c *Client) ExportProtobuf(ctx context.Context, path string, body []byte) error {
+	if err := c.send(ctx, path, contentTypeProtobuf, body); err != nil {
+		return fmt.Errorf("export protobuf: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) send(ctx context.Context, path, contentType string, body []byte) error {
+	var contentEncoding string
+
+	if c.gzip {
+		var (
+			buf    bytes.Buffer
+			writer = gzip.NewWriter(&buf)
+		)
+
+		if _, err := writer.Write(body); err != nil {
+			return err
+		}
+
+		if err := writer.Close(); err != nil {
+			return err
+		}
+
+		body, contentEncoding = buf.Bytes(), "gzip"
+	}
+
+	backoff := c.retryBackoff
+
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.post(ctx, path, contentType, contentEncoding, body)
+		if err == nil || retryAfter < 0 || attempt >= c.maxRetries {
+			return err
+		}
+
+		// The collector asks to wait longer than the backoff when it is overloaded.
+		if retryAfter < backoff {
+			retryAfter = backoff
+		}
+
+		if retryAfter > c.maxRetryDelay {
+			retryAfter = c.maxRetryDelay
+		}
+
+		timer := time.NewTimer(retryAfter)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("%w: %v", err, ctx.Err()) // nolint:errorlint
+		case <-timer.C:
+		}
+
+		backoff *= 2
+	}
+}
+
+// retryBudget returns the longest period of time the request is sent with all the retries. The zero budget
+// means that the request is not limited in time.
+func (c *Client) retryBudget() time.Duration {
+	if c.httpClient.Timeout <= 0 {
+		return 0
+	}
+
+	// Every retry could be delayed for the longest time, when the collector asks to wait.
+	return c.httpClient.Timeout*time.Duration(c.maxRetries+1) + c.maxRetryDelay*time.Duration(c.maxRetries)
+}
+
+// post sends the single request. The non-negative delay is returned when the
+// request could be retried.
+func (c *Client) post(
+	ctx context.Context,
+	path string,
+	contentType string,
+	contentEncoding string,
+	body []byte,
+) (
+	time.Duration,
+	error,
+) {
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+
+	httpRequest.Header.Set("Content-Type", contentType)
+
+	if contentEncoding != "" {
+		httpRequest.Header.Set("Content-Encoding", contentEncoding)
+	}
+
+	for key, value := range c.headers {
+		httpRequest.Header.Set(key, value)
+	}
+
+	response, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return -1, err
+	}
+
+	defer response.Body.Close()
+
+	// The body is drained, so the connection could be reused.
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices {
+		return 0, nil
+	}
+
+	err = fmt.Errorf("%w: %s", ErrUnexpectedStatus, response.Status)
+
+	// Only throttling and temporary unavailability are retryable according to the OTLP specification.
+	if response.StatusCode != http.StatusTooManyRequests && response.StatusCode != http.StatusServiceUnavailable {
+		return -1, err
+	}
+
+	seconds, parseErr := strconv.Atoi(response.Header.Get("Retry-After"))
+	if parseErr != nil || seconds < 0 {
+		return 0, err
+	}
+
+	return time.Duration(seconds) * time.Second, err
+}
@@ -0,0 +1,70 @@
+package otlp
+
+import (
+	"time"
+)
+
+// ClientOption represents an option for configure Client instance.
+type ClientOption interface {
+	apply(client *Client)
+}
+
+type clientOptionFunc func(client *Client)
+
+func (fn clientOptionFunc) apply(client *Client) {
+	fn(client)
+}
+
+// DefaultTimeout is the maximum period of time for the single export request.
+const DefaultTimeout = time.Second * 10
+
+// WithTimeout sets up the maximum period of time for the single export request.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return clientOptionFunc(func(client *Client) {
+		client.httpClient.Timeout = timeout
+	})
+}
+
+// WithHeaders sets up the headers which are sent with every export request, like the authorization.
+func WithHeaders(headers map[string]string) ClientOption {
+	return clientOptionFunc(func(client *Client) {
+		client.headers = headers
+	})
+}
+
+// WithGzip enables compression of the export requests.
+func WithGzip() ClientOption {
+	return clientOptionFunc(func(client *Client) {
+		client.gzip = true
+	})
+}
+
+// DefaultMaxRetries is the maximum count of retries of the throttled export request.
+const DefaultMaxRetries = 5
+
+// WithMaxRetries sets up the maximum count of retries of the throttled export request.
+func WithMaxRetries(retries int) ClientOption {
+	return clientOptionFunc(func(client *Client) {
+		client.maxRetries = retries
+	})
+}
+
+// DefaultRetryBackoff is the delay before the first retry. The delay is doubled after every retry.
+const DefaultRetryBackoff = time.Millisecond * 500
+
+// WithRetryBackoff sets up the delay before the first retry. The delay is doubled after every retry.
+func WithRetryBackoff(backoff time.Duration) ClientOption {
+	return clientOptionFunc(func(client *Client) {
+		client.retryBackoff = backoff
+	})
+}
+
+// DefaultMaxRetryDelay is the maximum delay before the retry, even if the collector asks to wait longer.
+const DefaultMaxRetryDelay = time.Second * 5
+
+// WithMaxRetryDelay sets up the maximum delay before the retry, even if the collector asks to wait longer.
+func WithMaxRetryDelay(delay time.Duration) ClientOption {
+	return clientOptionFunc(func(client *Client) {
+		client.maxRetryDelay = delay
+	})
+}
@@ -0,0 +1,107 @@
+package otlp
+
+import (
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// KeyValue is the attribute in OTLP JSON encoding.
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// AnyValue is the attribute value in OTLP JSON encoding. Only one of the fields is set.
+type AnyValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    *string     `json:"intValue,omitempty"`
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+	ArrayValue  *ArrayValue `json:"arrayValue,omitempty"`
+}
+
+// ArrayValue is the list of attribute values in OTLP JSON encoding.
+type ArrayValue struct {
+	Values []AnyValue `json:"values"`
+}
+
+// Resource is the entity which produces the telemetry in OTLP JSON encoding.
+type Resource struct {
+	Attributes []KeyValue `json:"attributes"`
+}
+
+// Scope is the instrumentation scope in OTLP JSON encoding.
+type Scope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// NewResource returns the resource in OTLP JSON encoding.
+func NewResource(res *resource.Resource) Resource {
+	return Resource{
+		Attributes: NewKeyValues(res.Attributes()),
+	}
+}
+
+// NewKeyValues returns the attributes in OTLP JSON encoding.
+func NewKeyValues(attrs []attribute.KeyValue) []KeyValue {
+	kvs := make([]KeyValue, 0, len(attrs))
+
+	for _, attr := range attrs {
+		kvs = append(kvs, KeyValue{
+			Key:   string(attr.Key),
+			Value: NewAnyValue(attr.Value),
+		})
+	}
+
+	return kvs
+}
+
+// NewAnyValue returns the attribute value in OTLP JSON encoding.
+// nolint:exhaustive
+func NewAnyValue(value attribute.Value) AnyValue {
+	var anyValue AnyValue
+
+	switch value.Type() {
+	case attribute.BOOL:
+		val := value.AsBool()
+		anyValue.BoolValue = &val
+	case attribute.INT64:
+		val := strconv.FormatInt(value.AsInt64(), 10)
+		anyValue.IntValue = &val
+	case attribute.FLOAT64:
+		val := value.AsFloat64()
+		anyValue.DoubleValue = &val
+	case attribute.BOOLSLICE, attribute.INT64SLICE, attribute.FLOAT64SLICE, attribute.STRINGSLICE:
+		anyValue.ArrayValue = newArrayValue(value)
+	default:
+		val := value.Emit()
+		anyValue.StringValue = &val
+	}
+
+	return anyValue
+}
+
+func newArrayValue(value attribute.Value) *ArrayValue {
+	var values []AnyValue
+
+	for _, val := range newArrayValues(value) {
+		values = append(values, NewAnyValue(val))
+	}
+
+	return &ArrayValue{
+		Values: values,
+	}
+}
+
+// UnixNano returns the time in OTLP JSON encoding, where 64-bit integers are encoded as strings.
+func UnixNano(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,325 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The field numbers of the messages from opentelemetry/proto/metrics/v1/metrics.proto
+// and opentelemetry/proto/collector/metrics/v1/metrics_service.proto.
+const (
+	requestResourceMetricsField protowire.Number = 1
+
+	resourceMetricsResourceField     protowire.Number = 1
+	resourceMetricsScopeMetricsField protowire.Number = 2
+
+	scopeMetricsScopeField   protowire.Number = 1
+	scopeMetricsMetricsField protowire.Number = 2
+
+	metricNameField        protowire.Number = 1
+	metricDescriptionField protowire.Number = 2
+	metricUnitField        protowire.Number = 3
+	metricGaugeField       protowire.Number = 5
+	metricSumField         protowire.Number = 7
+	metricHistogramField   protowire.Number = 9
+
+	dataPointsField        protowire.Number = 1
+	temporalityField       protowire.Number = 2
+	sumIsMonotonicField    protowire.Number = 3
+	startTimeUnixNanoField protowire.Number = 2
+	timeUnixNanoField      protowire.Number = 3
+
+	numberDataPointAsDoubleField   protowire.Number = 4
+	numberDataPointAsIntField      protowire.Number = 6
+	numberDataPointAttributesField protowire.Number = 7
+
+	histogramDataPointCountField          protowire.Number = 4
+	histogramDataPointSumField            protowire.Number = 5
+	histogramDataPointBucketCountsField   protowire.Number = 6
+	histogramDataPointExplicitBoundsField protowire.Number = 7
+	histogramDataPointAttributesField     protowire.Number = 9
+)
+
+// The values of AggregationTemporality enum.
+const (
+	temporalityDelta      = 1
+	temporalityCumulative = 2
+)
+
+// The names of the temporality selectors.
+const (
+	TemporalityCumulative = "cumulative"
+	TemporalityDelta      = "delta"
+	TemporalityStateless  = "stateless"
+)
+
+// ErrUnknownTemporality is returned when the temporality name is not supported.
+var ErrUnknownTemporality = errors.New("temporality should be cumulative, delta or stateless")
+
+// ParseTemporality returns the temporality selector by its name. The stateless
+// selector exports the synchronous instruments as delta and the asynchronous ones
+// as cumulative, so the SDK does not keep the state between collections.
+func ParseTemporality(name string) (aggregation.TemporalitySelector, error) {
+	switch name {
+	case TemporalityCumulative:
+		return aggregation.CumulativeTemporalitySelector(), nil
+	case TemporalityDelta:
+		return aggregation.DeltaTemporalitySelector(), nil
+	case TemporalityStateless:
+		return aggregation.StatelessTemporalitySelector(), nil
+	}
+
+	return nil, fmt.Errorf("%q: %w", name, ErrUnknownTemporality)
+}
+
+var _ export.Exporter = (*MetricExporter)(nil)
+
+// MetricExporter pushes the metrics to the collector with OTLP/HTTP protocol in protobuf encoding.
+type MetricExporter struct {
+	client *Client
+	aggregation.TemporalitySelector
+}
+
+// NewMetricExporter returns a new MetricExporter instance.
+func NewMetricExporter(client *Client, temporality aggregation.TemporalitySelector) *MetricExporter {
+	return &MetricExporter{
+		client:              client,
+		TemporalitySelector: temporality,
+	}
+}
+
+// NewController returns the controller which collects the metrics and pushes
+// them with the exporter every interval. The controller should be started and
+// stopped by the caller, the stop pushes the metrics for the last time. The push
+// is limited by the time which the client takes with all the retries.
+func NewController(exporter *MetricExporter, interval time.Duration, boundaries []float64) *controller.Controller {
+	return controller.New(
+		processor.NewFactory(
+			selector.NewWithHistogramDistribution(
+				histogram.WithExplicitBoundaries(boundaries),
+			),
+			exporter,
+			processor.WithMemory(true),
+		),
+		controller.WithExporter(exporter),
+		controller.WithCollectPeriod(interval),
+		controller.WithPushTimeout(exporter.client.retryBudget()),
+		controller.WithResource(
+			resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String("server"),
+				semconv.ServiceVersionKey.String("1.0.0"),
+				semconv.DeploymentEnvironmentKey.String("production"),
+			),
+		),
+	)
+}
+
+// Export pushes the metrics which were collected by the controller.
+func (exp *MetricExporter) Export(
+	ctx context.Context,
+	res *resource.Resource,
+	reader export.InstrumentationLibraryReader,
+) error {
+	body, err := exp.encode(res, reader)
+	if err != nil {
+		return fmt.Errorf("export metrics: %w", err)
+	}
+
+	// The collection without any data is not pushed.
+	if body == nil {
+		return nil
+	}
+
+	if err = exp.client.ExportProtobuf(ctx, "/v1/metrics", body); err != nil {
+		return fmt.Errorf("export metrics: %w", err)
+	}
+
+	return nil
+}
+
+func (exp *MetricExporter) encode(res *resource.Resource, reader export.InstrumentationLibraryReader) ([]byte, error) {
+	var scopes []byte
+
+	err := reader.ForEach(func(library instrumentation.Library, reader export.Reader) error {
+		var metrics []byte
+
+		err := reader.ForEach(exp, func(record export.Record) error {
+			var err error
+
+			metrics, err = exp.appendMetric(metrics, record)
+
+			return err
+		})
+		if err != nil || metrics == nil {
+			return err
+		}
+
+		scopes = AppendMessage(scopes, resourceMetricsScopeMetricsField, func(bb []byte) []byte {
+			bb = AppendScope(bb, scopeMetricsScopeField, library.Name, library.Version)
+
+			return append(bb, metrics...)
+		})
+
+		return nil
+	})
+	if err != nil || scopes == nil {
+		return nil, err
+	}
+
+	return AppendMessage(nil, requestResourceMetricsField, func(bb []byte) []byte {
+		bb = AppendResource(bb, resourceMetricsResourceField, res)
+
+		return append(bb, scopes...)
+	}), nil
+}
+
+func (exp *MetricExporter) appendMetric(bb []byte, record export.Record) ([]byte, error) {
+	var (
+		desc = record.Descriptor()
+
+		data []byte
+		num  protowire.Number
+		err  error
+	)
+
+	// The histogram is checked first, because it implements the sum as well.
+	switch agg := record.Aggregation().(type) {
+	case aggregation.Histogram:
+		num = metricHistogramField
+		data, err = appendHistogram(record, agg, exp.temporality(desc, agg.Kind()))
+	case aggregation.Sum:
+		num = metricSumField
+		data, err = appendSum(record, agg, exp.temporality(desc, agg.Kind()))
+	case aggregation.LastValue:
+		num = metricGaugeField
+		data, err = appendGauge(record, agg)
+	default:
+		return bb, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", desc.Name(), err)
+	}
+
+	return AppendMessage(bb, scopeMetricsMetricsField, func(bb []byte) []byte {
+		bb = AppendString(bb, metricNameField, desc.Name())
+		bb = AppendString(bb, metricDescriptionField, desc.Description())
+		bb = AppendString(bb, metricUnitField, string(desc.Unit()))
+
+		bb = protowire.AppendTag(bb, num, protowire.BytesType)
+
+		return protowire.AppendBytes(bb, data)
+	}), nil
+}
+
+func (exp *MetricExporter) temporality(desc *sdkapi.Descriptor, kind aggregation.Kind) uint64 {
+	if exp.TemporalityFor(desc, kind) == aggregation.DeltaTemporality {
+		return temporalityDelta
+	}
+
+	return temporalityCumulative
+}
+
+func appendSum(record export.Record, agg aggregation.Sum, temporality uint64) ([]byte, error) {
+	sum, err := agg.Sum()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		bb        = appendNumberDataPoint(nil, record, sum, record.StartTime())
+		monotonic = record.Descriptor().InstrumentKind().Monotonic()
+	)
+
+	bb = AppendVarint(bb, temporalityField, temporality)
+
+	return AppendVarint(bb, sumIsMonotonicField, protowire.EncodeBool(monotonic)), nil
+}
+
+func appendGauge(record export.Record, agg aggregation.LastValue) ([]byte, error) {
+	value, _, err := agg.LastValue()
+	if err != nil {
+		return nil, err
+	}
+
+	// The gauge has no start time.
+	return appendNumberDataPoint(nil, record, value, time.Time{}), nil
+}
+
+func appendNumberDataPoint(bb []byte, record export.Record, value number.Number, start time.Time) []byte {
+	return AppendMessage(bb, dataPointsField, func(bb []byte) []byte {
+		bb = AppendKeyValues(bb, numberDataPointAttributesField, record.Attributes().ToSlice())
+		bb = appendTimes(bb, start, record.EndTime())
+
+		if kind := record.Descriptor().NumberKind(); kind == number.Int64Kind {
+			return AppendFixed64(bb, numberDataPointAsIntField, uint64(value.AsInt64()))
+		}
+
+		return AppendDouble(bb, numberDataPointAsDoubleField, value.AsFloat64())
+	})
+}
+
+func appendHistogram(record export.Record, agg aggregation.Histogram, temporality uint64) ([]byte, error) {
+	count, err := agg.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	sum, err := agg.Sum()
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := agg.Histogram()
+	if err != nil {
+		return nil, err
+	}
+
+	bb := AppendMessage(nil, dataPointsField, func(bb []byte) []byte {
+		bb = AppendKeyValues(bb, histogramDataPointAttributesField, record.Attributes().ToSlice())
+		bb = appendTimes(bb, record.StartTime(), record.EndTime())
+		bb = AppendFixed64(bb, histogramDataPointCountField, count)
+		bb = AppendDouble(bb, histogramDataPointSumField, sum.CoerceToFloat64(record.Descriptor().NumberKind()))
+
+		bb = protowire.AppendTag(bb, histogramDataPointBucketCountsField, protowire.BytesType)
+		bb = protowire.AppendVarint(bb, uint64(len(buckets.Counts)*8)) // nolint:gomnd
+
+		for _, bucketCount := range buckets.Counts {
+			bb = protowire.AppendFixed64(bb, bucketCount)
+		}
+
+		bb = protowire.AppendTag(bb, histogramDataPointExplicitBoundsField, protowire.BytesType)
+		bb = protowire.AppendVarint(bb, uint64(len(buckets.Boundaries)*8)) // nolint:gomnd
+
+		for _, boundary := range buckets.Boundaries {
+			bb = protowire.AppendFixed64(bb, math.Float64bits(boundary))
+		}
+
+		return bb
+	})
+
+	return AppendVarint(bb, temporalityField, temporality), nil
+}
+
+func appendTimes(bb []byte, start, end time.Time) []byte {
+	if !start.IsZero() {
+		bb = AppendFixed64(bb, startTimeUnixNanoField, uint64(start.UnixNano()))
+	}
+
+	return AppendFixed64(bb, timeUnixNanoField, uint64(end.UnixNano()))
+}
@@ -0,0 +1,598 @@
+package otlp_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/otlp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// collectorResponse is the scripted response of the fake collector.
+type collectorResponse struct {
+	statusCode int
+	retryAfter string
+}
+
+// collectedRequest is the request received by the fake collector with the decompressed body.
+type collectedRequest struct {
+	receivedAt time.Time
+	header     http.Header
+	body       []byte
+}
+
+// collector is the fake OpenTelemetry collector which answers with the scripted responses and then
+// accepts everything.
+type collector struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	responses []collectorResponse
+	requests  []collectedRequest
+}
+
+func newCollector(t *testing.T, responses ...collectorResponse) (*collector, *httptest.Server) {
+	t.Helper()
+
+	c := &collector{
+		t: t,
+
+		mu:        sync.Mutex{},
+		responses: responses,
+		requests:  nil,
+	}
+
+	server := httptest.NewServer(c)
+	t.Cleanup(server.Close)
+
+	return c, server
+}
+
+func (c *collector) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	receivedAt := time.Now()
+
+	if request.URL.Path != "/v1/metrics" || request.Header.Get("Content-Type") != "application/x-protobuf" {
+		c.t.Errorf("unexpected request %s with content type %q", request.URL.Path,
+			request.Header.Get("Content-Type"))
+		writer.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	var reader io.Reader = request.Body
+
+	if request.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(request.Body)
+		if err != nil {
+			c.t.Errorf("gzip: %v", err)
+			writer.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		reader = gzipReader
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		c.t.Errorf("read body: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = append(c.requests, collectedRequest{
+		receivedAt: receivedAt,
+		header:     request.Header.Clone(),
+		body:       body,
+	})
+
+	if len(c.responses) == 0 {
+		writer.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	response := c.responses[0]
+	c.responses = c.responses[1:]
+
+	if response.retryAfter != "" {
+		writer.Header().Set("Retry-After", response.retryAfter)
+	}
+
+	writer.WriteHeader(response.statusCode)
+}
+
+func (c *collector) collected() []collectedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]collectedRequest(nil), c.requests...)
+}
+
+// field is the decoded protobuf field.
+type field struct {
+	num   protowire.Number
+	typ   protowire.Type
+	bytes []byte
+	value uint64
+}
+
+// decode returns the fields of the message, the wire types are asserted by the callers.
+func decode(t *testing.T, bb []byte) []field {
+	t.Helper()
+
+	var ff []field
+
+	for len(bb) > 0 {
+		num, typ, n := protowire.ConsumeTag(bb)
+		if n < 0 {
+			t.Fatalf("consume tag: %v", protowire.ParseError(n))
+		}
+
+		bb = bb[n:]
+		f := field{num: num, typ: typ, bytes: nil, value: 0}
+
+		switch typ {
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(bb)
+		case protowire.VarintType:
+			f.value, n = protowire.ConsumeVarint(bb)
+		case protowire.Fixed64Type:
+			f.value, n = protowire.ConsumeFixed64(bb)
+		case protowire.Fixed32Type, protowire.StartGroupType, protowire.EndGroupType:
+			t.Fatalf("unexpected wire type %d of field %d", typ, num)
+		}
+
+		if n < 0 {
+			t.Fatalf("consume field %d: %v", num, protowire.ParseError(n))
+		}
+
+		bb = bb[n:]
+		ff = append(ff, f)
+	}
+
+	return ff
+}
+
+// fieldsOf returns the fields with the number, which should be encoded with the wire type.
+func fieldsOf(t *testing.T, ff []field, num protowire.Number, typ protowire.Type) []field {
+	t.Helper()
+
+	var found []field
+
+	for _, f := range ff {
+		if f.num != num {
+			continue
+		}
+
+		if f.typ != typ {
+			t.Fatalf("field %d: expected wire type %d, got %d", num, typ, f.typ)
+		}
+
+		found = append(found, f)
+	}
+
+	return found
+}
+
+// single returns the only field with the number.
+func single(t *testing.T, ff []field, num protowire.Number, typ protowire.Type) field {
+	t.Helper()
+
+	found := fieldsOf(t, ff, num, typ)
+	if len(found) != 1 {
+		t.Fatalf("field %d: expected 1 occurrence, got %d", num, len(found))
+	}
+
+	return found[0]
+}
+
+// packedFixed64 returns the values of the packed repeated fixed64 or double field.
+func packedFixed64(t *testing.T, bb []byte) []uint64 {
+	t.Helper()
+
+	values := make([]uint64, 0, len(bb)/8)
+
+	for len(bb) > 0 {
+		value, n := protowire.ConsumeFixed64(bb)
+		if n < 0 {
+			t.Fatalf("consume packed: %v", protowire.ParseError(n))
+		}
+
+		values, bb = append(values, value), bb[n:]
+	}
+
+	return values
+}
+
+// stringAttributes returns the string attributes of KeyValue list.
+func stringAttributes(t *testing.T, ff []field) map[string]string {
+	t.Helper()
+
+	attrs := make(map[string]string, len(ff))
+
+	for _, f := range ff {
+		var (
+			kv    = decode(t, f.bytes)
+			key   = single(t, kv, 1, protowire.BytesType)
+			value = decode(t, single(t, kv, 2, protowire.BytesType).bytes)
+		)
+
+		if strs := fieldsOf(t, value, 1, protowire.BytesType); len(strs) == 1 {
+			attrs[string(key.bytes)] = string(strs[0].bytes)
+		}
+	}
+
+	return attrs
+}
+
+// decodedMetric is the metric of the export request with the fields which are checked by the tests.
+type decodedMetric struct {
+	scope string
+	data  map[protowire.Number][]field
+}
+
+// decodeRequest returns the metrics of ExportMetricsServiceRequest by name and the resource attributes.
+func decodeRequest(t *testing.T, body []byte) (map[string]decodedMetric, map[string]string) {
+	t.Helper()
+
+	var (
+		metrics  = make(map[string]decodedMetric)
+		resource = single(t, decode(t, body), 1, protowire.BytesType)
+		rm       = decode(t, resource.bytes)
+		attrs    = stringAttributes(t, fieldsOf(t, decode(t, single(t, rm, 1, protowire.BytesType).bytes), 1,
+			protowire.BytesType))
+	)
+
+	for _, sm := range fieldsOf(t, rm, 2, protowire.BytesType) {
+		var (
+			scopeMetrics = decode(t, sm.bytes)
+			scope        = decode(t, single(t, scopeMetrics, 1, protowire.BytesType).bytes)
+			scopeName    = string(single(t, scope, 1, protowire.BytesType).bytes)
+		)
+
+		for _, m := range fieldsOf(t, scopeMetrics, 2, protowire.BytesType) {
+			var (
+				metric = decode(t, m.bytes)
+				name   = string(single(t, metric, 1, protowire.BytesType).bytes)
+				data   = make(map[protowire.Number][]field)
+			)
+
+			for _, num := range []protowire.Number{5, 7, 9} {
+				for _, f := range fieldsOf(t, metric, num, protowire.BytesType) {
+					data[num] = decode(t, f.bytes)
+				}
+			}
+
+			metrics[name] = decodedMetric{
+				scope: scopeName,
+				data:  data,
+			}
+		}
+	}
+
+	return metrics, attrs
+}
+
+func TestMetricExporter_Export(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		opts     []otlp.ClientOption
+		encoding string
+	}{
+		{name: "Identity", opts: nil, encoding: ""},
+		{name: "Gzip", opts: []otlp.ClientOption{otlp.WithGzip()}, encoding: "gzip"},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				ctx          = context.Background()
+				fake, server = newCollector(t)
+				exporter     = otlp.NewMetricExporter(otlp.NewClient(server.URL, tc.opts...),
+					aggregation.CumulativeTemporalitySelector())
+				// The interval is long, so the metrics are pushed only by the final flush on stop.
+				ctrl = otlp.NewController(exporter, time.Hour, []float64{1, 10})
+			)
+
+			if err := ctrl.Start(ctx); err != nil {
+				t.Fatalf("start: %v", err)
+			}
+
+			meter := ctrl.Meter("test", metric.WithInstrumentationVersion("1.0.0"))
+
+			counter, err := meter.SyncInt64().Counter("requests")
+			if err != nil {
+				t.Fatalf("counter: %v", err)
+			}
+
+			histogram, err := meter.SyncFloat64().Histogram("duration")
+			if err != nil {
+				t.Fatalf("histogram: %v", err)
+			}
+
+			counter.Add(ctx, 3, attribute.String("method", "GET"))
+
+			for _, value := range []float64{0.5, 5, 50} {
+				histogram.Record(ctx, value, attribute.String("method", "GET"))
+			}
+
+			if err := ctrl.Stop(ctx); err != nil {
+				t.Fatalf("stop: %v", err)
+			}
+
+			requests := fake.collected()
+			if len(requests) != 1 {
+				t.Fatalf("expected the final flush, got %d requests", len(requests))
+			}
+
+			if encoding := requests[0].header.Get("Content-Encoding"); encoding != tc.encoding {
+				t.Errorf("unexpected content encoding: %q", encoding)
+			}
+
+			metrics, resourceAttrs := decodeRequest(t, requests[0].body)
+
+			if resourceAttrs["service.name"] != "server" {
+				t.Errorf("unexpected resource attributes: %v", resourceAttrs)
+			}
+
+			assertSum(t, metrics["requests"])
+			assertHistogram(t, metrics["duration"])
+		})
+	}
+}
+
+func TestNewController_PushTimeout(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name      string
+		responses []collectorResponse
+	}{
+		{
+			// The backoffs add up to more than the timeout of the single request.
+			name: "Backoff",
+			responses: []collectorResponse{
+				{statusCode: http.StatusServiceUnavailable, retryAfter: ""},
+				{statusCode: http.StatusServiceUnavailable, retryAfter: ""},
+			},
+		},
+		{
+			// The collector asks to wait longer than the push could last, so the delay is capped.
+			name: "RetryAfter",
+			responses: []collectorResponse{
+				{statusCode: http.StatusTooManyRequests, retryAfter: "3600"},
+			},
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				ctx          = context.Background()
+				fake, server = newCollector(t, tc.responses...)
+				client       = otlp.NewClient(server.URL, otlp.WithTimeout(time.Millisecond*100),
+					otlp.WithRetryBackoff(time.Millisecond*80), otlp.WithMaxRetries(2),
+					otlp.WithMaxRetryDelay(time.Millisecond*300))
+				ctrl = otlp.NewController(otlp.NewMetricExporter(client, aggregation.CumulativeTemporalitySelector()),
+					time.Hour, []float64{1, 10})
+			)
+
+			if err := ctrl.Start(ctx); err != nil {
+				t.Fatalf("start: %v", err)
+			}
+
+			counter, err := ctrl.Meter("test").SyncInt64().Counter("requests")
+			if err != nil {
+				t.Fatalf("counter: %v", err)
+			}
+
+			counter.Add(ctx, 1)
+
+			if err := ctrl.Stop(ctx); err != nil {
+				t.Fatalf("stop: %v", err)
+			}
+
+			if attempts := len(fake.collected()); attempts != len(tc.responses)+1 {
+				t.Errorf("expected %d attempts, got %d", len(tc.responses)+1, attempts)
+			}
+		})
+	}
+}
+
+func assertSum(t *testing.T, metric decodedMetric) {
+	t.Helper()
+
+	sum, ok := metric.data[7]
+	if !ok || metric.scope != "test" {
+		t.Fatalf("requests is not the sum of the scope: %+v", metric)
+	}
+
+	if temporality := single(t, sum, 2, protowire.VarintType).value; temporality != 2 {
+		t.Errorf("unexpected temporality: %d", temporality)
+	}
+
+	if monotonic := single(t, sum, 3, protowire.VarintType).value; monotonic != 1 {
+		t.Errorf("unexpected monotonic flag: %d", monotonic)
+	}
+
+	point := decode(t, single(t, sum, 1, protowire.BytesType).bytes)
+
+	if value := single(t, point, 6, protowire.Fixed64Type).value; value != 3 {
+		t.Errorf("unexpected value: %d", value)
+	}
+
+	var (
+		start = single(t, point, 2, protowire.Fixed64Type).value
+		end   = single(t, point, 3, protowire.Fixed64Type).value
+	)
+
+	if start == 0 || end < start {
+		t.Errorf("unexpected times: %d - %d", start, end)
+	}
+
+	if attrs := stringAttributes(t, fieldsOf(t, point, 7, protowire.BytesType)); attrs["method"] != "GET" {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+}
+
+func assertHistogram(t *testing.T, metric decodedMetric) {
+	t.Helper()
+
+	histogram, ok := metric.data[9]
+	if !ok {
+		t.Fatalf("duration is not the histogram: %+v", metric)
+	}
+
+	if temporality := single(t, histogram, 2, protowire.VarintType).value; temporality != 2 {
+		t.Errorf("unexpected temporality: %d", temporality)
+	}
+
+	point := decode(t, single(t, histogram, 1, protowire.BytesType).bytes)
+
+	if count := single(t, point, 4, protowire.Fixed64Type).value; count != 3 {
+		t.Errorf("unexpected count: %d", count)
+	}
+
+	if sum := math.Float64frombits(single(t, point, 5, protowire.Fixed64Type).value); sum != 55.5 {
+		t.Errorf("unexpected sum: %v", sum)
+	}
+
+	if counts := packedFixed64(t, single(t, point, 6, protowire.BytesType).bytes); len(counts) != 3 ||
+		counts[0] != 1 || counts[1] != 1 || counts[2] != 1 {
+		t.Errorf("unexpected bucket counts: %v", counts)
+	}
+
+	bounds := packedFixed64(t, single(t, point, 7, protowire.BytesType).bytes)
+	if len(bounds) != 2 || math.Float64frombits(bounds[0]) != 1 || math.Float64frombits(bounds[1]) != 10 {
+		t.Errorf("unexpected explicit bounds: %v", bounds)
+	}
+
+	if attrs := stringAttributes(t, fieldsOf(t, point, 9, protowire.BytesType)); attrs["method"] != "GET" {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+}
+
+func TestClient_ExportProtobuf_Retry(t *testing.T) {
+	t.Parallel()
+
+	const backoff = time.Millisecond * 50
+
+	var (
+		fake, server = newCollector(t,
+			collectorResponse{statusCode: http.StatusTooManyRequests, retryAfter: "1"},
+			collectorResponse{statusCode: http.StatusServiceUnavailable, retryAfter: ""},
+			collectorResponse{statusCode: http.StatusServiceUnavailable, retryAfter: ""})
+		client = otlp.NewClient(server.URL, otlp.WithRetryBackoff(backoff), otlp.WithMaxRetries(3))
+		body   = []byte("metrics")
+	)
+
+	if err := client.ExportProtobuf(context.Background(), "/v1/metrics", body); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	requests := fake.collected()
+	if len(requests) != 4 {
+		t.Fatalf("expected 4 attempts, got %d", len(requests))
+	}
+
+	// The collector asks to wait longer than the backoff at first, then the backoff is doubled after every retry.
+	for i, minDelay := range []time.Duration{time.Second, backoff * 2, backoff * 4} {
+		if delay := requests[i+1].receivedAt.Sub(requests[i].receivedAt); delay < minDelay {
+			t.Errorf("retry %d: expected delay at least %s, got %s", i+1, minDelay, delay)
+		}
+
+		if !bytes.Equal(requests[i+1].body, body) {
+			t.Errorf("retry %d: unexpected body %q", i+1, requests[i+1].body)
+		}
+	}
+}
+
+func TestClient_ExportProtobuf_NotRetried(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name      string
+		responses []collectorResponse
+		attempts  int
+	}{
+		{
+			name:      "BadRequest",
+			responses: []collectorResponse{{statusCode: http.StatusBadRequest, retryAfter: "1"}},
+			attempts:  1,
+		},
+		{
+			name: "RetriesExhausted",
+			responses: []collectorResponse{
+				{statusCode: http.StatusServiceUnavailable, retryAfter: ""},
+				{statusCode: http.StatusServiceUnavailable, retryAfter: ""},
+				{statusCode: http.StatusServiceUnavailable, retryAfter: ""},
+			},
+			attempts: 3,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				fake, server = newCollector(t, tc.responses...)
+				client       = otlp.NewClient(server.URL, otlp.WithRetryBackoff(time.Millisecond),
+					otlp.WithMaxRetries(2))
+			)
+
+			err := client.ExportProtobuf(context.Background(), "/v1/metrics", []byte("metrics"))
+			if !errors.Is(err, otlp.ErrUnexpectedStatus) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if attempts := len(fake.collected()); attempts != tc.attempts {
+				t.Errorf("expected %d attempts, got %d", tc.attempts, attempts)
+			}
+		})
+	}
+}
+
+func TestClient_ExportProtobuf_Canceled(t *testing.T) {
+	t.Parallel()
+
+	var (
+		_, server = newCollector(t,
+			collectorResponse{statusCode: http.StatusTooManyRequests, retryAfter: "60"})
+		client = otlp.NewClient(server.URL)
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	start := time.Now()
+
+	// The retry is not waited for when the context is done.
+	if err := client.ExportProtobuf(ctx, "/v1/metrics", []byte("metrics")); !errors.Is(err, otlp.ErrUnexpectedStatus) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second*5 {
+		t.Errorf("export was not canceled: %s", elapsed)
+	}
+}
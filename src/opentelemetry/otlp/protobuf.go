@@ -0,0 +1,150 @@
+package otlp
+
+import (
+	"math"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The field numbers of the messages from opentelemetry/proto/common/v1/common.proto
+// and opentelemetry/proto/resource/v1/resource.proto.
+const (
+	keyValueKeyField   protowire.Number = 1
+	keyValueValueField protowire.Number = 2
+
+	anyValueStringField protowire.Number = 1
+	anyValueBoolField   protowire.Number = 2
+	anyValueIntField    protowire.Number = 3
+	anyValueDoubleField protowire.Number = 4
+	anyValueArrayField  protowire.Number = 5
+
+	arrayValueValuesField protowire.Number = 1
+
+	scopeNameField    protowire.Number = 1
+	scopeVersionField protowire.Number = 2
+
+	resourceAttributesField protowire.Number = 1
+)
+
+// AppendMessage appends the embedded message which is encoded by the fn.
+func AppendMessage(bb []byte, num protowire.Number, fn func(bb []byte) []byte) []byte {
+	bb = protowire.AppendTag(bb, num, protowire.BytesType)
+
+	return protowire.AppendBytes(bb, fn(nil))
+}
+
+// AppendString appends the string field. The empty string is omitted like the default value.
+func AppendString(bb []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return bb
+	}
+
+	bb = protowire.AppendTag(bb, num, protowire.BytesType)
+
+	return protowire.AppendString(bb, value)
+}
+
+// AppendVarint appends the integer or enum field.
+func AppendVarint(bb []byte, num protowire.Number, value uint64) []byte {
+	bb = protowire.AppendTag(bb, num, protowire.VarintType)
+
+	return protowire.AppendVarint(bb, value)
+}
+
+// AppendFixed64 appends the fixed64 or sfixed64 field.
+func AppendFixed64(bb []byte, num protowire.Number, value uint64) []byte {
+	bb = protowire.AppendTag(bb, num, protowire.Fixed64Type)
+
+	return protowire.AppendFixed64(bb, value)
+}
+
+// AppendDouble appends the double field.
+func AppendDouble(bb []byte, num protowire.Number, value float64) []byte {
+	return AppendFixed64(bb, num, math.Float64bits(value))
+}
+
+// AppendResource appends the resource message.
+func AppendResource(bb []byte, num protowire.Number, res *resource.Resource) []byte {
+	return AppendMessage(bb, num, func(bb []byte) []byte {
+		return AppendKeyValues(bb, resourceAttributesField, res.Attributes())
+	})
+}
+
+// AppendScope appends the instrumentation scope message.
+func AppendScope(bb []byte, num protowire.Number, name, version string) []byte {
+	return AppendMessage(bb, num, func(bb []byte) []byte {
+		bb = AppendString(bb, scopeNameField, name)
+
+		return AppendString(bb, scopeVersionField, version)
+	})
+}
+
+// AppendKeyValues appends the repeated attributes field.
+func AppendKeyValues(bb []byte, num protowire.Number, attrs []attribute.KeyValue) []byte {
+	for _, attr := range attrs {
+		attr := attr
+
+		bb = AppendMessage(bb, num, func(bb []byte) []byte {
+			bb = AppendString(bb, keyValueKeyField, string(attr.Key))
+
+			return appendAnyValue(bb, keyValueValueField, attr.Value)
+		})
+	}
+
+	return bb
+}
+
+// nolint:exhaustive
+func appendAnyValue(bb []byte, num protowire.Number, value attribute.Value) []byte {
+	return AppendMessage(bb, num, func(bb []byte) []byte {
+		switch value.Type() {
+		case attribute.BOOL:
+			return AppendVarint(bb, anyValueBoolField, protowire.EncodeBool(value.AsBool()))
+		case attribute.INT64:
+			return AppendVarint(bb, anyValueIntField, uint64(value.AsInt64()))
+		case attribute.FLOAT64:
+			return AppendDouble(bb, anyValueDoubleField, value.AsFloat64())
+		case attribute.BOOLSLICE, attribute.INT64SLICE, attribute.FLOAT64SLICE, attribute.STRINGSLICE:
+			return AppendMessage(bb, anyValueArrayField, func(bb []byte) []byte {
+				for _, val := range newArrayValues(value) {
+					bb = appendAnyValue(bb, arrayValueValuesField, val)
+				}
+
+				return bb
+			})
+		default:
+			// The string field is written even when it is empty, so the value is not lost.
+			bb = protowire.AppendTag(bb, anyValueStringField, protowire.BytesType)
+
+			return protowire.AppendString(bb, value.Emit())
+		}
+	})
+}
+
+// nolint:exhaustive
+func newArrayValues(value attribute.Value) []attribute.Value {
+	var values []attribute.Value
+
+	switch value.Type() {
+	case attribute.BOOLSLICE:
+		for _, val := range value.AsBoolSlice() {
+			values = append(values, attribute.BoolValue(val))
+		}
+	case attribute.INT64SLICE:
+		for _, val := range value.AsInt64Slice() {
+			values = append(values, attribute.Int64Value(val))
+		}
+	case attribute.FLOAT64SLICE:
+		for _, val := range value.AsFloat64Slice() {
+			values = append(values, attribute.Float64Value(val))
+		}
+	case attribute.STRINGSLICE:
+		for _, val := range value.AsStringSlice() {
+			values = append(values, attribute.StringValue(val))
+		}
+	}
+
+	return values
+}
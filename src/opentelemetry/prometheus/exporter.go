@@ -3,6 +3,7 @@ package prometheus
 import (
 	"fmt"
 
+	client "github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
 	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
@@ -13,9 +14,20 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
 )
 
-func NewExporter() (*prometheus.Exporter, error) {
+// DefaultHistogramBoundaries are the histogram boundaries for durations which are measured in milliseconds.
+// nolint:gochecknoglobals,gomnd
+var DefaultHistogramBoundaries = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// NewExporter returns the exporter which collects the metrics through the
+// registerer and serves them from the gatherer.
+func NewExporter(registerer client.Registerer, gatherer client.Gatherer) (*prometheus.Exporter, error) {
 	var (
-		config prometheus.Config
+		config = prometheus.Config{
+			Registry:                   nil,
+			Registerer:                 registerer,
+			Gatherer:                   gatherer,
+			DefaultHistogramBoundaries: DefaultHistogramBoundaries,
+		}
 
 		ctrl = controller.New(
 			processor.NewFactory(
@@ -0,0 +1,239 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/otlp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var _ sdktrace.SpanExporter = (*WriterExporter)(nil)
+
+// WriterExporter writes the spans as OTLP JSON lines, so the output could be
+// read by OpenTelemetry collector with the file receiver.
+type WriterExporter struct {
+	mu      sync.Mutex
+	writer  io.Writer
+	encoder *json.Encoder
+}
+
+// NewWriterExporter returns a new WriterExporter instance. The writer is closed
+// on shutdown when it implements io.Closer.
+func NewWriterExporter(writer io.Writer) *WriterExporter {
+	return &WriterExporter{
+		mu:      sync.Mutex{},
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+// NewStdoutExporter returns the exporter which writes spans to the standard output.
+func NewStdoutExporter() *WriterExporter {
+	// The standard output is wrapped, so it is not closed on shutdown.
+	return NewWriterExporter(struct{ io.Writer }{Writer: os.Stdout})
+}
+
+// NewFileExporter returns the exporter which appends spans to the file.
+func NewFileExporter(path string) (*WriterExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) // nolint:gomnd
+	if err != nil {
+		return nil, fmt.Errorf("open trace file: %w", err)
+	}
+
+	return NewWriterExporter(file), nil
+}
+
+// ExportSpans exports a batch of spans.
+func (exp *WriterExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	if err := exp.encoder.Encode(newExportRequest(spans)); err != nil {
+		return fmt.Errorf("export spans: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown notifies the exporter of a pending halt to operations.
+func (exp *WriterExporter) Shutdown(context.Context) error {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	closer, ok := exp.writer.(io.Closer)
+	if !ok {
+		return nil
+	}
+
+	if err := closer.Close(); err != nil {
+		return fmt.Errorf("shutdown exporter: %w", err)
+	}
+
+	return nil
+}
+
+var _ sdktrace.SpanExporter = (*OTLPExporter)(nil)
+
+// OTLPExporter sends the spans to the OpenTelemetry collector with OTLP/HTTP protocol.
+type OTLPExporter struct {
+	client *otlp.Client
+}
+
+// NewOTLPExporter returns a new OTLPExporter instance.
+func NewOTLPExporter(client *otlp.Client) *OTLPExporter {
+	return &OTLPExporter{
+		client: client,
+	}
+}
+
+// ExportSpans exports a batch of spans.
+func (exp *OTLPExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if err := exp.client.Export(ctx, "/v1/traces", newExportRequest(spans)); err != nil {
+		return fmt.Errorf("export spans: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown notifies the exporter of a pending halt to operations.
+func (exp *OTLPExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+type exportRequest struct {
+	ResourceSpans []*resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   otlp.Resource `json:"resource"`
+	ScopeSpans []*scopeSpans `json:"scopeSpans"`
+}
+
+type scopeSpans struct {
+	Scope otlp.Scope `json:"scope"`
+	Spans []span     `json:"spans"`
+}
+
+type span struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlp.KeyValue `json:"attributes,omitempty"`
+	Events            []event         `json:"events,omitempty"`
+	Status            status          `json:"status"`
+}
+
+type event struct {
+	TimeUnixNano string          `json:"timeUnixNano"`
+	Name         string          `json:"name"`
+	Attributes   []otlp.KeyValue `json:"attributes,omitempty"`
+}
+
+type status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// OTLP status codes differ from the codes of the API.
+const (
+	statusCodeOk    = 1
+	statusCodeError = 2
+)
+
+func newExportRequest(spans []sdktrace.ReadOnlySpan) exportRequest {
+	var (
+		request = exportRequest{
+			ResourceSpans: nil,
+		}
+
+		resources = make(map[attribute.Distinct]*resourceSpans)
+		scopes    = make(map[attribute.Distinct]map[instrumentation.Library]*scopeSpans)
+	)
+
+	for _, ro := range spans {
+		key := ro.Resource().Equivalent()
+
+		rs, ok := resources[key]
+		if !ok {
+			rs = &resourceSpans{
+				Resource:   otlp.NewResource(ro.Resource()),
+				ScopeSpans: nil,
+			}
+
+			resources[key], scopes[key] = rs, make(map[instrumentation.Library]*scopeSpans)
+			request.ResourceSpans = append(request.ResourceSpans, rs)
+		}
+
+		library := ro.InstrumentationLibrary()
+
+		ss, ok := scopes[key][library]
+		if !ok {
+			ss = &scopeSpans{
+				Scope: otlp.Scope{
+					Name:    library.Name,
+					Version: library.Version,
+				},
+				Spans: nil,
+			}
+
+			scopes[key][library] = ss
+			rs.ScopeSpans = append(rs.ScopeSpans, ss)
+		}
+
+		ss.Spans = append(ss.Spans, newSpan(ro))
+	}
+
+	return request
+}
+
+func newSpan(ro sdktrace.ReadOnlySpan) span {
+	out := span{
+		TraceID:           ro.SpanContext().TraceID().String(),
+		SpanID:            ro.SpanContext().SpanID().String(),
+		ParentSpanID:      "",
+		Name:              ro.Name(),
+		Kind:              int(ro.SpanKind()),
+		StartTimeUnixNano: otlp.UnixNano(ro.StartTime()),
+		EndTimeUnixNano:   otlp.UnixNano(ro.EndTime()),
+		Attributes:        otlp.NewKeyValues(ro.Attributes()),
+		Events:            nil,
+		Status: status{
+			Code:    0,
+			Message: ro.Status().Description,
+		},
+	}
+
+	if ro.Parent().IsValid() {
+		out.ParentSpanID = ro.Parent().SpanID().String()
+	}
+
+	switch ro.Status().Code {
+	case codes.Ok:
+		out.Status.Code = statusCodeOk
+	case codes.Error:
+		out.Status.Code = statusCodeError
+	case codes.Unset:
+	}
+
+	for _, evt := range ro.Events() {
+		out.Events = append(out.Events, event{
+			TimeUnixNano: otlp.UnixNano(evt.Time),
+			Name:         evt.Name,
+			Attributes:   otlp.NewKeyValues(evt.Attributes),
+		})
+	}
+
+	return out
+}
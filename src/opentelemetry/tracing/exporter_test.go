@@ -0,0 +1,237 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/otlp"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// collectedRequest is the export request in OTLP/HTTP JSON encoding as the collector decodes it.
+type collectedRequest struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []collectedKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Scope struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"scope"`
+			Spans []collectedSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+type collectedKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string `json:"stringValue"`
+		IntValue    *string `json:"intValue"`
+	} `json:"value"`
+}
+
+type collectedSpan struct {
+	TraceID           string              `json:"traceId"`
+	SpanID            string              `json:"spanId"`
+	ParentSpanID      string              `json:"parentSpanId"`
+	Name              string              `json:"name"`
+	Kind              int                 `json:"kind"`
+	StartTimeUnixNano string              `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string              `json:"endTimeUnixNano"`
+	Attributes        []collectedKeyValue `json:"attributes"`
+	Events            []struct {
+		Name string `json:"name"`
+	} `json:"events"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+// collector is the fake OpenTelemetry collector which keeps the decoded export requests.
+type collector struct {
+	mu       sync.Mutex
+	requests []collectedRequest
+	headers  []http.Header
+}
+
+func (c *collector) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost || request.URL.Path != "/v1/traces" {
+		writer.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	var decoded collectedRequest
+	if err := json.NewDecoder(request.Body).Decode(&decoded); err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, decoded)
+	c.headers = append(c.headers, request.Header.Clone())
+	c.mu.Unlock()
+
+	writer.Header().Set("Content-Type", "application/json")
+	_, _ = writer.Write([]byte(`{}`))
+}
+
+func stringAttribute(kvs []collectedKeyValue, key string) string {
+	for _, kv := range kvs {
+		if kv.Key == key && kv.Value.StringValue != nil {
+			return *kv.Value.StringValue
+		}
+	}
+
+	return ""
+}
+
+func TestOTLPExporter_ExportSpans(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx    = context.Background()
+		fake   = &collector{mu: sync.Mutex{}, requests: nil, headers: nil}
+		server = httptest.NewServer(fake)
+	)
+
+	defer server.Close()
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(tracing.NewOTLPExporter(otlp.NewClient(server.URL+"/",
+			otlp.WithHeaders(map[string]string{"Authorization": "Bearer token"})))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String("server"))))
+
+	tracer := provider.Tracer("percona", trace.WithInstrumentationVersion("1.0.0"))
+
+	parentCtx, parent := tracer.Start(ctx, "parent", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(parentCtx, "child", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "mysql"), attribute.Int64("db.rows_affected", 3)))
+	child.AddEvent("retry")
+	child.SetStatus(codes.Error, "duplicate entry")
+	child.End()
+	parent.End()
+
+	if err := provider.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if len(fake.requests) != 2 {
+		t.Fatalf("expected 2 export requests, got %d", len(fake.requests))
+	}
+
+	if contentType := fake.headers[0].Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("unexpected content type: %q", contentType)
+	}
+
+	if authorization := fake.headers[0].Get("Authorization"); authorization != "Bearer token" {
+		t.Errorf("unexpected authorization: %q", authorization)
+	}
+
+	spans := make(map[string]collectedSpan)
+
+	for _, request := range fake.requests {
+		for _, rs := range request.ResourceSpans {
+			if name := stringAttribute(rs.Resource.Attributes, "service.name"); name != "server" {
+				t.Errorf("unexpected service name: %q", name)
+			}
+
+			for _, ss := range rs.ScopeSpans {
+				if ss.Scope.Name != "percona" || ss.Scope.Version != "1.0.0" {
+					t.Errorf("unexpected scope: %+v", ss.Scope)
+				}
+
+				for _, span := range ss.Spans {
+					spans[span.Name] = span
+				}
+			}
+		}
+	}
+
+	var (
+		collectedParent = spans["parent"]
+		collectedChild  = spans["child"]
+	)
+
+	if collectedParent.TraceID != parent.SpanContext().TraceID().String() ||
+		collectedParent.SpanID != parent.SpanContext().SpanID().String() || collectedParent.ParentSpanID != "" {
+		t.Errorf("unexpected parent identifiers: %+v", collectedParent)
+	}
+
+	if collectedChild.TraceID != collectedParent.TraceID || collectedChild.ParentSpanID != collectedParent.SpanID {
+		t.Errorf("child is not linked to parent: %+v", collectedChild)
+	}
+
+	// The kinds and the status codes are the values of OTLP enums.
+	if collectedParent.Kind != 2 || collectedChild.Kind != 3 {
+		t.Errorf("unexpected kinds: parent %d, child %d", collectedParent.Kind, collectedChild.Kind)
+	}
+
+	if collectedChild.Status.Code != 2 || collectedChild.Status.Message != "duplicate entry" {
+		t.Errorf("unexpected child status: %+v", collectedChild.Status)
+	}
+
+	if collectedParent.Status.Code != 0 {
+		t.Errorf("unexpected parent status: %+v", collectedParent.Status)
+	}
+
+	if len(collectedChild.Events) != 1 || collectedChild.Events[0].Name != "retry" {
+		t.Errorf("unexpected child events: %+v", collectedChild.Events)
+	}
+
+	if system := stringAttribute(collectedChild.Attributes, "db.system"); system != "mysql" {
+		t.Errorf("unexpected db.system: %q", system)
+	}
+
+	for _, kv := range collectedChild.Attributes {
+		// The 64-bit integers are encoded as strings.
+		if kv.Key == "db.rows_affected" && (kv.Value.IntValue == nil || *kv.Value.IntValue != "3") {
+			t.Errorf("unexpected db.rows_affected: %+v", kv.Value)
+		}
+	}
+
+	if collectedChild.StartTimeUnixNano == "" || collectedChild.EndTimeUnixNano < collectedChild.StartTimeUnixNano {
+		t.Errorf("unexpected child times: %s - %s", collectedChild.StartTimeUnixNano,
+			collectedChild.EndTimeUnixNano)
+	}
+}
+
+func TestOTLPExporter_ExportSpans_Rejected(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	var (
+		recorder = &spanRecorder{spans: nil}
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(recorder))
+	)
+
+	_, span := provider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+
+	// The bad request is not retried, so the error is returned at once.
+	err := tracing.NewOTLPExporter(otlp.NewClient(server.URL)).ExportSpans(context.Background(), recorder.spans)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
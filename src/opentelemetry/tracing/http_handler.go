@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ http.ResponseWriter = (*response)(nil)
+
+type response struct {
+	wrapped http.ResponseWriter
+
+	statusCode int
+}
+
+// Header returns the header map that will be sent by
+// WriteHeader. The Header map also is the mechanism with which
+// Handlers can set HTTP trailers.
+func (resp *response) Header() http.Header {
+	return resp.wrapped.Header()
+}
+
+// Write writes the data to the connection as part of an HTTP reply.
+func (resp *response) Write(bb []byte) (int, error) {
+	return resp.wrapped.Write(bb)
+}
+
+// WriteHeader sends an HTTP response header with the provided
+// status code.
+func (resp *response) WriteHeader(statusCode int) {
+	resp.statusCode = statusCode
+
+	resp.wrapped.WriteHeader(resp.statusCode)
+}
+
+// HTTPHandler starts the server span for every request. The parent span is
+// extracted from the request headers like W3C traceparent by the propagator.
+func HTTPHandler(tracer trace.Tracer, propagator propagation.TextMapPropagator) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			ctx := propagator.Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+
+			// The route is not known until the request is routed, so the span is renamed after that.
+			ctx, span := tracer.Start(ctx, "HTTP "+request.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", request)...),
+				trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", "", request)...))
+			defer span.End()
+
+			resp := &response{
+				wrapped: writer,
+
+				statusCode: http.StatusOK,
+			}
+
+			next.ServeHTTP(resp, request.WithContext(ctx))
+
+			if pattern := routePattern(ctx); pattern != "" {
+				span.SetName(request.Method + " " + pattern)
+				span.SetAttributes(semconv.HTTPRouteKey.String(pattern))
+			}
+
+			span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.statusCode)...)
+
+			code, message := semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(resp.statusCode, trace.SpanKindServer)
+			if code == codes.Error {
+				span.SetStatus(code, message)
+			}
+		})
+	}
+}
+
+// routePattern returns the pattern of the route which has handled the request
+// or the empty string when the request matches no route.
+func routePattern(ctx context.Context) string {
+	routeContext := chi.RouteContext(ctx)
+	if routeContext == nil {
+		return ""
+	}
+
+	// The mount pattern and the root pattern of the sub-router are joined with the double slash.
+	return strings.ReplaceAll(routeContext.RoutePattern(), "//", "/")
+}
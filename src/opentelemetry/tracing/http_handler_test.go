@@ -0,0 +1,108 @@
+package tracing_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	t.Parallel()
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+
+	for _, tc := range []struct {
+		name        string
+		traceparent string
+		statusCode  int
+		status      codes.Code
+	}{
+		{
+			name:        "Propagated",
+			traceparent: "00-" + traceID + "-" + parentSpanID + "-01",
+			statusCode:  http.StatusOK,
+			status:      codes.Unset,
+		},
+		{
+			name:        "ServerError",
+			traceparent: "00-" + traceID + "-" + parentSpanID + "-01",
+			statusCode:  http.StatusInternalServerError,
+			status:      codes.Error,
+		},
+		{
+			name:        "Root",
+			traceparent: "",
+			statusCode:  http.StatusNotFound,
+			status:      codes.Unset,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				recorder = &spanRecorder{spans: nil}
+				provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(recorder))
+				router   = chi.NewRouter()
+				handled  trace.SpanContext
+			)
+
+			router.Use(tracing.HTTPHandler(provider.Tracer("http"), propagation.TraceContext{}))
+			router.Get("/users/{id}", func(writer http.ResponseWriter, request *http.Request) {
+				handled = trace.SpanContextFromContext(request.Context())
+
+				writer.WriteHeader(tc.statusCode)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			if tc.traceparent != "" {
+				request.Header.Set("traceparent", tc.traceparent)
+			}
+
+			router.ServeHTTP(httptest.NewRecorder(), request)
+
+			if len(recorder.spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(recorder.spans))
+			}
+
+			span := recorder.spans[0]
+
+			if !handled.Equal(span.SpanContext()) {
+				t.Errorf("handler context does not carry the server span")
+			}
+
+			if span.Name() != "GET /users/{id}" || span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("unexpected span %q of kind %s", span.Name(), span.SpanKind())
+			}
+
+			if span.Status().Code != tc.status {
+				t.Errorf("unexpected status: %s", span.Status().Code)
+			}
+
+			if tc.traceparent == "" {
+				if span.Parent().IsValid() {
+					t.Errorf("unexpected parent: %s", span.Parent().SpanID())
+				}
+
+				return
+			}
+
+			if span.SpanContext().TraceID().String() != traceID || span.Parent().SpanID().String() != parentSpanID ||
+				!span.Parent().IsRemote() {
+				t.Errorf("span is not continued from traceparent: trace %s, parent %s",
+					span.SpanContext().TraceID(), span.Parent().SpanID())
+			}
+		})
+	}
+}
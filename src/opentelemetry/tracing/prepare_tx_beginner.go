@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ percona.PrepareTxBeginner = (*PrepareTxBeginner)(nil)
+
+type PrepareTxBeginner struct {
+	wrapped percona.PrepareTxBeginner
+	tracer  trace.Tracer
+
+	attrs []attribute.KeyValue
+}
+
+// NewPrepareTxBeginner returns a new instance of PrepareTxBeginner. The attributes
+// describe the database like db.system and db.name.
+func NewPrepareTxBeginner(
+	svc percona.PrepareTxBeginner,
+	tracer trace.Tracer,
+	attrs ...attribute.KeyValue,
+) *PrepareTxBeginner {
+	return &PrepareTxBeginner{
+		wrapped: svc,
+		tracer:  tracer,
+
+		attrs: attrs,
+	}
+}
+
+// PrepareContext creates a prepared statement for later queries or executions.
+func (svc *PrepareTxBeginner) PrepareContext(ctx context.Context, query string) (percona.Stmt, error) {
+	return prepare(ctx, svc.wrapped, svc.tracer, svc.attrs, query)
+}
+
+// BeginTx starts a transaction.
+func (svc *PrepareTxBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (percona.Tx, error) {
+	_, span := startSpan(ctx, svc.tracer, "begin", svc.attrs)
+	defer span.End()
+
+	perconaTx, err := svc.wrapped.BeginTx(ctx, opts)
+	if err != nil {
+		recordSQLError(span, err)
+
+		return nil, err
+	}
+
+	return &tx{
+		wrapped: perconaTx,
+		tracer:  svc.tracer,
+		attrs:   svc.attrs,
+	}, nil
+}
+
+func prepare(
+	ctx context.Context,
+	preparer percona.Preparer,
+	tracer trace.Tracer,
+	attrs []attribute.KeyValue,
+	query string,
+) (
+	percona.Stmt,
+	error,
+) {
+	var (
+		fingerprint = percona.FingerprintContext(ctx, query)
+		stmtAttrs   = append(make([]attribute.KeyValue, 0, len(attrs)+3), attrs...) // nolint:gomnd
+	)
+
+	// The normalized statement is used, so literal values never get into the trace.
+	stmtAttrs = append(stmtAttrs, semconv.DBStatementKey.String(fingerprint.Normalized),
+		semconv.DBOperationKey.String(fingerprint.Operation), attribute.String("db.query.name", fingerprint.Name))
+
+	_, span := startSpan(ctx, tracer, "prepare "+fingerprint.Name, stmtAttrs)
+	defer span.End()
+
+	perconaStmt, err := preparer.PrepareContext(ctx, query)
+	if err != nil {
+		recordSQLError(span, err)
+
+		return nil, err
+	}
+
+	return &stmt{
+		wrapped: perconaStmt,
+		tracer:  tracer,
+
+		name:  fingerprint.Name,
+		attrs: stmtAttrs,
+	}, nil
+}
+
+func startSpan(
+	ctx context.Context,
+	tracer trace.Tracer,
+	name string,
+	attrs []attribute.KeyValue,
+) (
+	context.Context,
+	trace.Span,
+) {
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func recordSQLError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
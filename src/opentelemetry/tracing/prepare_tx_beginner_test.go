@@ -0,0 +1,104 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/opentelemetry/tracing"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/otelexampletest"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona/perconatest"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestPrepareTxBeginner_Conflict(t *testing.T) {
+	t.Parallel()
+
+	var (
+		recorder = &spanRecorder{spans: nil}
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(recorder))
+	)
+
+	createConflictingUserAccount(t, tracing.NewPrepareTxBeginner(connectClient(t, newDuplicateEntryScript(t)),
+		provider.Tracer("percona")))
+
+	statuses := make(map[string]codes.Code, len(recorder.spans))
+	for _, span := range recorder.spans {
+		statuses[span.Name()] = span.Status().Code
+	}
+
+	for name, code := range map[string]codes.Code{
+		"begin":                       codes.Unset,
+		"check_user_account_exists":   codes.Unset,
+		"create_user":                 codes.Unset,
+		"create_user_account":         codes.Error,
+		"prepare create_user_account": codes.Unset,
+		"rollback":                    codes.Unset,
+	} {
+		if actual, ok := statuses[name]; !ok || actual != code {
+			t.Errorf("span %q: expected status %s, got %s (recorded %t)", name, code, actual, ok)
+		}
+	}
+}
+
+// connectClient returns the client which follows the script.
+func connectClient(t *testing.T, script *perconatest.Script) *percona.Client {
+	t.Helper()
+
+	client := percona.NewClient(script.DSN(), percona.WithDriverName(perconatest.DriverName))
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := client.Close(); err != nil {
+			t.Errorf("close: %v", err)
+		}
+	})
+
+	return client
+}
+
+// createConflictingUserAccount creates the user account which violates the unique key of the username.
+func createConflictingUserAccount(t *testing.T, prepareTxBeginner percona.PrepareTxBeginner) {
+	t.Helper()
+
+	var (
+		ctx = otelexample.NewContextWithTenantID(context.Background(), "tenant")
+		svc = percona.NewUserAccountService(prepareTxBeginner, otelexampletest.NewSequenceIdentifierGenerator("id-"),
+			otelexampletest.NewStepTimer(time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), time.Second))
+		ua = &otelexample.UserAccount{
+			ID:       otelexample.EmptyID,
+			Username: "alice",
+			User: &otelexample.User{
+				ID:        otelexample.EmptyID,
+				FirstName: "Alice",
+				LastName:  "Liddell",
+				CreatedAt: time.Time{},
+			},
+			CreatedAt: time.Time{},
+		}
+	)
+
+	err := svc.CreateUserAccount(ctx, ua)
+	if code := otelexample.ErrorCodeFromError(err); code != otelexample.ErrorCodeConflict {
+		t.Fatalf("unexpected error code %q: %v", code, err)
+	}
+}
+
+// newDuplicateEntryScript returns the script of the user account creation which fails with the duplicate entry.
+func newDuplicateEntryScript(t *testing.T) *perconatest.Script {
+	t.Helper()
+
+	script := perconatest.NewScript(t)
+	script.ExpectBegin()
+	script.ExpectQuery(`^SELECT EXISTS`).WillReturnRows(perconatest.NewRows("is_exists").AddRow(int64(0)))
+	script.ExpectExec(`^INSERT INTO users `)
+	script.ExpectExec(`^INSERT INTO user_accounts `).WillReturnError(perconatest.MySQLError(1062, "Duplicate entry"))
+	script.ExpectRollback()
+
+	return script
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ percona.Stmt = (*stmt)(nil)
+
+// stmt is a prepared statement.
+type stmt struct {
+	wrapped percona.Stmt
+	tracer  trace.Tracer
+
+	name  string
+	attrs []attribute.KeyValue
+}
+
+// ExecContext executes a prepared statement with the given arguments and
+// returns a Result summarizing the effect of the statement.
+func (stmt *stmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	ctx, span := startSpan(ctx, stmt.tracer, stmt.name, stmt.attrs)
+	defer span.End()
+
+	result, err := stmt.wrapped.ExecContext(ctx, args...)
+	if err != nil {
+		recordSQLError(span, err)
+
+		return nil, err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", rows))
+	}
+
+	return result, nil
+}
+
+// QueryRowContext executes a prepared query statement with the given arguments.
+func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) percona.Row {
+	ctx, span := startSpan(ctx, stmt.tracer, stmt.name, stmt.attrs)
+	defer span.End()
+
+	row := stmt.wrapped.QueryRowContext(ctx, args...)
+	if err := row.Err(); err != nil {
+		recordSQLError(span, err)
+	}
+
+	return row
+}
+
+// QueryContext executes a prepared query statement with the given arguments
+// and returns the query results as a *Rows. The span does not cover reading of the rows.
+func (stmt *stmt) QueryContext(ctx context.Context, args ...any) (*sql.Rows, error) {
+	ctx, span := startSpan(ctx, stmt.tracer, stmt.name, stmt.attrs)
+	defer span.End()
+
+	rows, err := stmt.wrapped.QueryContext(ctx, args...)
+	if err != nil {
+		recordSQLError(span, err)
+
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// Close closes the statement.
+func (stmt *stmt) Close(ctx context.Context) error {
+	return stmt.wrapped.Close(ctx)
+}
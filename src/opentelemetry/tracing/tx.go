@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ percona.Tx = (*tx)(nil)
+
+// tx is an in-progress database transaction.
+type tx struct {
+	wrapped percona.Tx
+	tracer  trace.Tracer
+	attrs   []attribute.KeyValue
+}
+
+// PrepareContext creates a prepared statement for later queries or executions.
+func (tx *tx) PrepareContext(ctx context.Context, query string) (percona.Stmt, error) {
+	return prepare(ctx, tx.wrapped, tx.tracer, tx.attrs, query)
+}
+
+// Commit commits the transaction.
+func (tx *tx) Commit(ctx context.Context) error {
+	ctx, span := startSpan(ctx, tx.tracer, "commit", tx.attrs)
+	defer span.End()
+
+	if err := tx.wrapped.Commit(ctx); err != nil {
+		recordSQLError(span, err)
+
+		return err
+	}
+
+	return nil
+}
+
+// Rollback aborts the transaction.
+func (tx *tx) Rollback(ctx context.Context) error {
+	ctx, span := startSpan(ctx, tx.tracer, "rollback", tx.attrs)
+	defer span.End()
+
+	if err := tx.wrapped.Rollback(ctx); err != nil {
+		recordSQLError(span, err)
+
+		return err
+	}
+
+	return nil
+}
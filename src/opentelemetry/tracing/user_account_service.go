@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ otelexample.UserAccountService = (*UserAccountService)(nil)
+
+// UserAccountService represents a service for managing UserAccount data.
+type UserAccountService struct {
+	wrapped otelexample.UserAccountService
+	tracer  trace.Tracer
+}
+
+// NewUserAccountService returns a new instance of UserAccountService.
+func NewUserAccountService(svc otelexample.UserAccountService, tracer trace.Tracer) *UserAccountService {
+	return &UserAccountService{
+		wrapped: svc,
+		tracer:  tracer,
+	}
+}
+
+// CreateUserAccount creates a new user account.
+func (svc *UserAccountService) CreateUserAccount(ctx context.Context, ua *otelexample.UserAccount) error {
+	ctx, span := svc.start(ctx, "UserAccountService.CreateUserAccount")
+	defer span.End()
+
+	err := svc.wrapped.CreateUserAccount(ctx, ua)
+	if err != nil {
+		recordError(span, err)
+
+		return err // nolint:wrapcheck
+	}
+
+	span.SetAttributes(attribute.String("user_account.id", ua.ID.String()))
+
+	return nil
+}
+
+// FindUserAccounts returns a list of user accounts.
+func (svc *UserAccountService) FindUserAccounts(
+	ctx context.Context,
+	opts otelexample.FindOptions,
+) (
+	*otelexample.FindUserAccountsResult,
+	error,
+) {
+	ctx, span := svc.start(ctx, "UserAccountService.FindUserAccounts",
+		attribute.Int64("find.limit", int64(opts.Limit())), attribute.Int64("find.offset", int64(opts.Offset())))
+	defer span.End()
+
+	result, err := svc.wrapped.FindUserAccounts(ctx, opts)
+	if err != nil {
+		recordError(span, err)
+
+		return nil, err // nolint:wrapcheck
+	}
+
+	span.SetAttributes(attribute.Int("find.data_size", len(result.Data)),
+		attribute.Int64("find.total", int64(result.Total)), attribute.Bool("find.has_next", result.HasNext))
+
+	return result, nil
+}
+
+// FindUserAccountByID returns user account by unique identifier.
+func (svc *UserAccountService) FindUserAccountByID(
+	ctx context.Context,
+	id otelexample.ID,
+) (
+	*otelexample.UserAccount,
+	error,
+) {
+	ctx, span := svc.start(ctx, "UserAccountService.FindUserAccountByID",
+		attribute.String("user_account.id", id.String()))
+	defer span.End()
+
+	ua, err := svc.wrapped.FindUserAccountByID(ctx, id)
+	if err != nil {
+		recordError(span, err)
+
+		return nil, err // nolint:wrapcheck
+	}
+
+	return ua, nil
+}
+
+func (svc *UserAccountService) start(
+	ctx context.Context,
+	name string,
+	attrs ...attribute.KeyValue,
+) (
+	context.Context,
+	trace.Span,
+) {
+	if tenantID, ok := otelexample.TenantIDFromContext(ctx); ok {
+		attrs = append(attrs, attribute.String("tenant.id", tenantID.String()))
+	}
+
+	return svc.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attrs...))
+}
+
+// recordError marks the span as failed. The errors which are caused by the
+// client, like not found, do not fail the span, but they are recorded with
+// their code.
+func recordError(span trace.Span, err error) {
+	code := otelexample.ErrorCodeFromError(err)
+
+	span.SetAttributes(attribute.String("error.code", code.String()))
+
+	if code == otelexample.ErrorCodeInternal || code == otelexample.ErrorCodeUnavailable {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
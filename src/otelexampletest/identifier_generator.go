@@ -0,0 +1,49 @@
+package otelexampletest
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+)
+
+var _ otelexample.IdentifierGenerator = (*SequenceIdentifierGenerator)(nil)
+
+// SequenceIdentifierGenerator generates identifiers from the sequence, so
+// tests could check which identifier was assigned.
+type SequenceIdentifierGenerator struct {
+	mu sync.Mutex
+
+	prefix string
+	issued []otelexample.ID
+}
+
+// NewSequenceIdentifierGenerator returns a new SequenceIdentifierGenerator instance.
+func NewSequenceIdentifierGenerator(prefix string) *SequenceIdentifierGenerator {
+	return &SequenceIdentifierGenerator{
+		mu: sync.Mutex{},
+
+		prefix: prefix,
+		issued: nil,
+	}
+}
+
+// GenerateIdentifier returns a new unique identifier.
+func (svc *SequenceIdentifierGenerator) GenerateIdentifier(_ context.Context) otelexample.ID {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	id := otelexample.ID(svc.prefix + strconv.Itoa(len(svc.issued)+1))
+	svc.issued = append(svc.issued, id)
+
+	return id
+}
+
+// Issued returns all identifiers which were generated.
+func (svc *SequenceIdentifierGenerator) Issued() []otelexample.ID {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	return append([]otelexample.ID(nil), svc.issued...)
+}
@@ -0,0 +1,53 @@
+package otelexampletest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+)
+
+var _ otelexample.Timer = (*StepTimer)(nil)
+
+// StepTimer returns the time which is moved forward by the step on every
+// call. The times are rounded to milliseconds, because storages keep them
+// with the millisecond precision.
+type StepTimer struct {
+	mu sync.Mutex
+
+	next   time.Time
+	step   time.Duration
+	issued []time.Time
+}
+
+// NewStepTimer returns a new StepTimer instance.
+func NewStepTimer(start time.Time, step time.Duration) *StepTimer {
+	return &StepTimer{
+		mu: sync.Mutex{},
+
+		next:   start.Truncate(time.Millisecond),
+		step:   step,
+		issued: nil,
+	}
+}
+
+// Time returns the current time.
+func (svc *StepTimer) Time(_ context.Context) time.Time {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	now := svc.next
+	svc.next = svc.next.Add(svc.step)
+	svc.issued = append(svc.issued, now)
+
+	return now
+}
+
+// Issued returns all times which were returned.
+func (svc *StepTimer) Issued() []time.Time {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	return append([]time.Time(nil), svc.issued...)
+}
@@ -0,0 +1,349 @@
+// Package otelexampletest provides the contract tests for implementations of
+// domain services, so storages and decorator chains could be proven to
+// behave the same way.
+package otelexampletest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+)
+
+const (
+	// SuiteTenantID is the tenant which is used by the suite for all operations.
+	SuiteTenantID = otelexample.TenantID("suite-tenant")
+
+	// SuiteOtherTenantID is the tenant which is used by the suite for isolation checks.
+	SuiteOtherTenantID = otelexample.TenantID("suite-other-tenant")
+)
+
+// UserAccountServiceFactory returns a new empty UserAccountService which
+// uses the given identifier generator and timer. Resources should be
+// released with t.Cleanup.
+type UserAccountServiceFactory func(
+	t *testing.T,
+	identifierGenerator otelexample.IdentifierGenerator,
+	timer otelexample.Timer,
+) otelexample.UserAccountService
+
+type userAccountServiceSuite struct {
+	svc                 otelexample.UserAccountService
+	identifierGenerator *SequenceIdentifierGenerator
+	timer               *StepTimer
+}
+
+// RunUserAccountServiceSuite checks that UserAccountService implementation
+// follows the contract. Every case gets its own service from the factory.
+func RunUserAccountServiceSuite(t *testing.T, factory UserAccountServiceFactory) {
+	t.Helper()
+
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, suite *userAccountServiceSuite)
+	}{
+		{name: "CreateAssignsIdentifiersAndTimestamps", run: testCreateAssignsIdentifiersAndTimestamps},
+		{name: "CreateConflict", run: testCreateConflict},
+		{name: "CreateSameUsernameInOtherTenant", run: testCreateSameUsernameInOtherTenant},
+		{name: "FindByID", run: testFindByID},
+		{name: "FindByIDNotFound", run: testFindByIDNotFound},
+		{name: "FindByIDOtherTenant", run: testFindByIDOtherTenant},
+		{name: "FindByIDReturnsCopy", run: testFindByIDReturnsCopy},
+		{name: "FindEmpty", run: testFindEmpty},
+		{name: "FindPagination", run: testFindPagination},
+		{name: "FindOtherTenant", run: testFindOtherTenant},
+		{name: "TenantNotSpecified", run: testTenantNotSpecified},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				identifierGenerator = NewSequenceIdentifierGenerator("suite-id-")
+				timer               = NewStepTimer(time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+					time.Second)
+			)
+
+			tc.run(t, &userAccountServiceSuite{
+				svc:                 factory(t, identifierGenerator, timer),
+				identifierGenerator: identifierGenerator,
+				timer:               timer,
+			})
+		})
+	}
+}
+
+func tenantContext(tenantID otelexample.TenantID) context.Context {
+	return otelexample.NewContextWithTenantID(context.Background(), tenantID)
+}
+
+func newUserAccount(username string) *otelexample.UserAccount {
+	return &otelexample.UserAccount{
+		ID:       otelexample.EmptyID,
+		Username: username,
+		User: &otelexample.User{
+			ID:        otelexample.EmptyID,
+			FirstName: "First " + username,
+			LastName:  "Last " + username,
+			CreatedAt: time.Time{},
+		},
+		CreatedAt: time.Time{},
+	}
+}
+
+func (suite *userAccountServiceSuite) create(
+	t *testing.T,
+	tenantID otelexample.TenantID,
+	username string,
+) *otelexample.UserAccount {
+	t.Helper()
+
+	ua := newUserAccount(username)
+	if err := suite.svc.CreateUserAccount(tenantContext(tenantID), ua); err != nil {
+		t.Fatalf("create user account %q: %v", username, err)
+	}
+
+	return ua
+}
+
+func assertErrorCode(t *testing.T, err error, code otelexample.ErrorCode) {
+	t.Helper()
+
+	if err == nil {
+		t.Fatalf("expected error with code %q, got nil", code)
+	}
+
+	if actual := otelexample.ErrorCodeFromError(err); actual != code {
+		t.Fatalf("expected error with code %q, got %q: %v", code, actual, err)
+	}
+}
+
+func assertUserAccountEqual(t *testing.T, expected, actual *otelexample.UserAccount) {
+	t.Helper()
+
+	if actual == nil {
+		t.Fatalf("expected user account %q, got nil", expected.ID)
+	}
+
+	if actual.ID != expected.ID || actual.Username != expected.Username ||
+		!actual.CreatedAt.Equal(expected.CreatedAt) {
+		t.Fatalf("expected user account %+v, got %+v", expected, actual)
+	}
+
+	if actual.User == nil {
+		t.Fatalf("expected user of account %q, got nil", expected.ID)
+	}
+
+	if actual.User.ID != expected.User.ID || actual.User.FirstName != expected.User.FirstName ||
+		actual.User.LastName != expected.User.LastName || !actual.User.CreatedAt.Equal(expected.User.CreatedAt) {
+		t.Fatalf("expected user %+v, got %+v", expected.User, actual.User)
+	}
+}
+
+func testCreateAssignsIdentifiersAndTimestamps(t *testing.T, suite *userAccountServiceSuite) {
+	ua := suite.create(t, SuiteTenantID, "alice")
+
+	issuedIDs := make(map[otelexample.ID]struct{})
+	for _, id := range suite.identifierGenerator.Issued() {
+		issuedIDs[id] = struct{}{}
+	}
+
+	for name, id := range map[string]otelexample.ID{"account": ua.ID, "user": ua.User.ID} {
+		if _, ok := issuedIDs[id]; !ok {
+			t.Errorf("%s identifier %q was not generated by the identifier generator", name, id)
+		}
+	}
+
+	if ua.ID == ua.User.ID {
+		t.Errorf("account and user have the same identifier %q", ua.ID)
+	}
+
+	issuedTimes := suite.timer.Issued()
+
+	for name, createdAt := range map[string]time.Time{"account": ua.CreatedAt, "user": ua.User.CreatedAt} {
+		if !containsTime(issuedTimes, createdAt) {
+			t.Errorf("%s creation time %s was not returned by the timer", name, createdAt)
+		}
+	}
+}
+
+func containsTime(tt []time.Time, value time.Time) bool {
+	for _, tm := range tt {
+		if tm.Equal(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func testCreateConflict(t *testing.T, suite *userAccountServiceSuite) {
+	suite.create(t, SuiteTenantID, "alice")
+
+	err := suite.svc.CreateUserAccount(tenantContext(SuiteTenantID), newUserAccount("alice"))
+	assertErrorCode(t, err, otelexample.ErrorCodeConflict)
+
+	result, err := suite.svc.FindUserAccounts(tenantContext(SuiteTenantID), otelexample.NewFindOptions(0, 0))
+	if err != nil {
+		t.Fatalf("find user accounts: %v", err)
+	}
+
+	if result.Total != 1 {
+		t.Fatalf("expected 1 user account after conflict, got %d", result.Total)
+	}
+}
+
+func testCreateSameUsernameInOtherTenant(t *testing.T, suite *userAccountServiceSuite) {
+	first := suite.create(t, SuiteTenantID, "alice")
+	second := suite.create(t, SuiteOtherTenantID, "alice")
+
+	if first.ID == second.ID {
+		t.Fatalf("accounts of different tenants have the same identifier %q", first.ID)
+	}
+}
+
+func testFindByID(t *testing.T, suite *userAccountServiceSuite) {
+	expected := suite.create(t, SuiteTenantID, "alice")
+	suite.create(t, SuiteTenantID, "bob")
+
+	actual, err := suite.svc.FindUserAccountByID(tenantContext(SuiteTenantID), expected.ID)
+	if err != nil {
+		t.Fatalf("find user account by id: %v", err)
+	}
+
+	assertUserAccountEqual(t, expected, actual)
+}
+
+func testFindByIDNotFound(t *testing.T, suite *userAccountServiceSuite) {
+	suite.create(t, SuiteTenantID, "alice")
+
+	_, err := suite.svc.FindUserAccountByID(tenantContext(SuiteTenantID), "missing")
+	assertErrorCode(t, err, otelexample.ErrorCodeNotFound)
+}
+
+func testFindByIDOtherTenant(t *testing.T, suite *userAccountServiceSuite) {
+	ua := suite.create(t, SuiteTenantID, "alice")
+
+	_, err := suite.svc.FindUserAccountByID(tenantContext(SuiteOtherTenantID), ua.ID)
+	assertErrorCode(t, err, otelexample.ErrorCodeNotFound)
+}
+
+func testFindByIDReturnsCopy(t *testing.T, suite *userAccountServiceSuite) {
+	expected := suite.create(t, SuiteTenantID, "alice")
+
+	// Changes of the created value should not affect the stored one.
+	created := expected.Clone()
+	expected.Username, expected.User.FirstName = "changed", "changed"
+
+	found, err := suite.svc.FindUserAccountByID(tenantContext(SuiteTenantID), created.ID)
+	if err != nil {
+		t.Fatalf("find user account by id: %v", err)
+	}
+
+	found.Username, found.User.FirstName = "changed", "changed"
+
+	actual, err := suite.svc.FindUserAccountByID(tenantContext(SuiteTenantID), created.ID)
+	if err != nil {
+		t.Fatalf("find user account by id: %v", err)
+	}
+
+	assertUserAccountEqual(t, created, actual)
+}
+
+func testFindEmpty(t *testing.T, suite *userAccountServiceSuite) {
+	result, err := suite.svc.FindUserAccounts(tenantContext(SuiteTenantID), otelexample.NewFindOptions(0, 0))
+	if err != nil {
+		t.Fatalf("find user accounts: %v", err)
+	}
+
+	if result.Total != 0 || result.HasNext || len(result.Data) != 0 {
+		t.Fatalf("expected empty result, got total %d, has next %t, %d accounts", result.Total, result.HasNext,
+			len(result.Data))
+	}
+}
+
+func testFindPagination(t *testing.T, suite *userAccountServiceSuite) {
+	const count = 5
+
+	created := make([]*otelexample.UserAccount, count)
+	for i := range created {
+		created[i] = suite.create(t, SuiteTenantID, fmt.Sprintf("user-%d", i))
+	}
+
+	for _, tc := range []struct {
+		limit, offset uint64
+		from, to      int
+		hasNext       bool
+	}{
+		{limit: 2, offset: 0, from: 0, to: 2, hasNext: true},
+		{limit: 2, offset: 2, from: 2, to: 4, hasNext: true},
+		{limit: 2, offset: 3, from: 3, to: 5, hasNext: false},
+		{limit: 2, offset: 4, from: 4, to: 5, hasNext: false},
+		{limit: 5, offset: 0, from: 0, to: 5, hasNext: false},
+		{limit: 10, offset: 0, from: 0, to: 5, hasNext: false},
+		{limit: 2, offset: 5, from: 5, to: 5, hasNext: false},
+		{limit: 2, offset: 10, from: 5, to: 5, hasNext: false},
+	} {
+		opts := otelexample.NewFindOptions(tc.limit, tc.offset)
+
+		result, err := suite.svc.FindUserAccounts(tenantContext(SuiteTenantID), opts)
+		if err != nil {
+			t.Fatalf("limit %d, offset %d: find user accounts: %v", tc.limit, tc.offset, err)
+		}
+
+		if result.Total != count {
+			t.Errorf("limit %d, offset %d: expected total %d, got %d", tc.limit, tc.offset, count, result.Total)
+		}
+
+		if result.HasNext != tc.hasNext {
+			t.Errorf("limit %d, offset %d: expected has next %t, got %t", tc.limit, tc.offset, tc.hasNext,
+				result.HasNext)
+		}
+
+		if result.Options != opts {
+			t.Errorf("limit %d, offset %d: expected options %+v, got %+v", tc.limit, tc.offset, opts,
+				result.Options)
+		}
+
+		expected := created[tc.from:tc.to]
+		if len(result.Data) != len(expected) {
+			t.Fatalf("limit %d, offset %d: expected %d accounts, got %d", tc.limit, tc.offset, len(expected),
+				len(result.Data))
+		}
+
+		for i := range expected {
+			assertUserAccountEqual(t, expected[i], result.Data[i])
+		}
+	}
+}
+
+func testFindOtherTenant(t *testing.T, suite *userAccountServiceSuite) {
+	suite.create(t, SuiteTenantID, "alice")
+	suite.create(t, SuiteTenantID, "bob")
+	other := suite.create(t, SuiteOtherTenantID, "carol")
+
+	result, err := suite.svc.FindUserAccounts(tenantContext(SuiteOtherTenantID), otelexample.NewFindOptions(0, 0))
+	if err != nil {
+		t.Fatalf("find user accounts: %v", err)
+	}
+
+	if result.Total != 1 || len(result.Data) != 1 {
+		t.Fatalf("expected only account of the tenant, got total %d, %d accounts", result.Total,
+			len(result.Data))
+	}
+
+	assertUserAccountEqual(t, other, result.Data[0])
+}
+
+func testTenantNotSpecified(t *testing.T, suite *userAccountServiceSuite) {
+	ctx := context.Background()
+
+	assertErrorCode(t, suite.svc.CreateUserAccount(ctx, newUserAccount("alice")), otelexample.ErrorCodeInvalid)
+
+	_, err := suite.svc.FindUserAccounts(ctx, otelexample.NewFindOptions(0, 0))
+	assertErrorCode(t, err, otelexample.ErrorCodeInvalid)
+
+	_, err = suite.svc.FindUserAccountByID(ctx, "missing")
+	assertErrorCode(t, err, otelexample.ErrorCodeInvalid)
+}
@@ -0,0 +1,440 @@
+package percona
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+)
+
+var _ fmt.Stringer = (*CircuitBreakerState)(nil)
+
+// CircuitBreakerState is the state of the circuit breaker.
+type CircuitBreakerState int
+
+const (
+	// CircuitBreakerClosed is the state when all calls are passed through.
+	CircuitBreakerClosed CircuitBreakerState = iota
+
+	// CircuitBreakerOpen is the state when all calls are rejected.
+	CircuitBreakerOpen
+
+	// CircuitBreakerHalfOpen is the state when the limited number of calls
+	// are passed through to check if the database has recovered.
+	CircuitBreakerHalfOpen
+)
+
+// The String method is used to print values passed as an operand
+// to any format that accepts a string or to an unformatted printer
+// such as Print.
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerClosed:
+		return "closed"
+	case CircuitBreakerOpen:
+		return "open"
+	case CircuitBreakerHalfOpen:
+		return "half_open"
+	}
+
+	return "unknown"
+}
+
+// CircuitBreakerStateChangeHandler is called when the circuit breaker changes its state.
+type CircuitBreakerStateChangeHandler func(from, to CircuitBreakerState)
+
+var _ error = (*CircuitBreakerOpenError)(nil)
+
+// CircuitBreakerOpenError is returned when the call was rejected by the circuit breaker.
+type CircuitBreakerOpenError struct {
+	retryAfter time.Duration
+}
+
+func (e *CircuitBreakerOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker is open, retry after %s", e.retryAfter)
+}
+
+// RetryAfter returns the time after which the call could be retried.
+func (e *CircuitBreakerOpenError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+var _ PrepareTxBeginner = (*CircuitBreaker)(nil)
+
+// CircuitBreaker stops calling the database when the ratio of failed calls
+// within the window exceeds the threshold, so callers fail fast instead of
+// waiting for their deadlines.
+type CircuitBreaker struct {
+	wrapped PrepareTxBeginner
+
+	mu               sync.Mutex
+	state            CircuitBreakerState
+	openedAt         time.Time
+	windowStart      time.Time
+	total            uint64
+	failures         uint64
+	halfOpenInFlight int
+	generation       uint64
+
+	failureRatio        float64
+	minRequests         uint64
+	window              time.Duration
+	openTimeout         time.Duration
+	halfOpenMaxRequests int
+	stateChangeHandlers []CircuitBreakerStateChangeHandler
+}
+
+// NewCircuitBreaker returns a new CircuitBreaker instance.
+func NewCircuitBreaker(svc PrepareTxBeginner, opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		wrapped: svc,
+
+		mu:               sync.Mutex{},
+		state:            CircuitBreakerClosed,
+		openedAt:         time.Time{},
+		windowStart:      time.Now(),
+		total:            0,
+		failures:         0,
+		halfOpenInFlight: 0,
+		generation:       0,
+
+		failureRatio:        DefaultCircuitBreakerFailureRatio,
+		minRequests:         DefaultCircuitBreakerMinRequests,
+		window:              DefaultCircuitBreakerWindow,
+		openTimeout:         DefaultCircuitBreakerOpenTimeout,
+		halfOpenMaxRequests: DefaultCircuitBreakerHalfOpenMaxRequests,
+		stateChangeHandlers: nil,
+	}
+
+	for _, opt := range opts {
+		opt.apply(cb)
+	}
+
+	return cb
+}
+
+// State returns the current state of the circuit breaker.
+func (cb *CircuitBreaker) State() CircuitBreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.state
+}
+
+// PrepareContext creates a prepared statement for later queries or executions.
+func (cb *CircuitBreaker) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	permit, err := cb.allow(false)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, rt := withRoundTrip(ctx)
+
+	perconaStmt, err := cb.wrapped.PrepareContext(ctx, query)
+	cb.donePrepare(permit, rt, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &circuitBreakerStmt{
+		wrapped: perconaStmt,
+		breaker: cb,
+	}, nil
+}
+
+// BeginTx starts a transaction.
+func (cb *CircuitBreaker) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	permit, err := cb.allow(false)
+	if err != nil {
+		return nil, err
+	}
+
+	perconaTx, err := cb.wrapped.BeginTx(ctx, opts)
+	cb.done(permit, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &circuitBreakerTx{
+		wrapped: perconaTx,
+		breaker: cb,
+	}, nil
+}
+
+// circuitBreakerPermit is issued for the call which is passed through, so only
+// the results of the admitted calls are recorded.
+type circuitBreakerPermit struct {
+	// generation is the generation of the state the call was admitted in. The
+	// results of the calls which were admitted before the state was changed are
+	// not recorded.
+	generation uint64
+
+	// probe is the flag that the call holds the half-open slot.
+	probe bool
+}
+
+// allow checks that the call could be passed through. Only the queries could
+// probe the database in the half-open state, because the statement could be
+// prepared from the cache without the database round trip. The preparations
+// are passed through while the probe slots are available, so the queries
+// could be executed.
+func (cb *CircuitBreaker) allow(query bool) (circuitBreakerPermit, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+
+	switch cb.state {
+	case CircuitBreakerClosed:
+		return circuitBreakerPermit{
+			generation: cb.generation,
+			probe:      false,
+		}, nil
+	case CircuitBreakerOpen:
+		if elapsed := now.Sub(cb.openedAt); elapsed < cb.openTimeout {
+			return circuitBreakerPermit{}, cb.unavailableError(cb.openTimeout - elapsed) // nolint:exhaustivestruct
+		}
+
+		cb.setState(CircuitBreakerHalfOpen, now)
+	case CircuitBreakerHalfOpen:
+	}
+
+	if cb.halfOpenInFlight >= cb.halfOpenMaxRequests {
+		return circuitBreakerPermit{}, cb.unavailableError(time.Second) // nolint:exhaustivestruct
+	}
+
+	if query {
+		cb.halfOpenInFlight++
+	}
+
+	return circuitBreakerPermit{
+		generation: cb.generation,
+		probe:      query,
+	}, nil
+}
+
+// done records the result of the admitted call. The breaker is closed only
+// when the probe query succeeds.
+func (cb *CircuitBreaker) done(permit circuitBreakerPermit, err error) {
+	failed := isCircuitBreakerFailure(err)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if permit.generation != cb.generation {
+		return
+	}
+
+	now := time.Now()
+
+	switch cb.state {
+	case CircuitBreakerOpen:
+		return
+	case CircuitBreakerHalfOpen:
+		if permit.probe {
+			cb.halfOpenInFlight--
+		}
+
+		if failed {
+			cb.setState(CircuitBreakerOpen, now)
+
+			return
+		}
+
+		if permit.probe {
+			cb.setState(CircuitBreakerClosed, now)
+		}
+
+		return
+	case CircuitBreakerClosed:
+	}
+
+	if now.Sub(cb.windowStart) > cb.window {
+		cb.windowStart, cb.total, cb.failures = now, 0, 0
+	}
+
+	cb.total++
+
+	if failed {
+		cb.failures++
+	}
+
+	if cb.total >= cb.minRequests && float64(cb.failures)/float64(cb.total) >= cb.failureRatio {
+		cb.setState(CircuitBreakerOpen, now)
+	}
+}
+
+// donePrepare records the result of the admitted preparation. The statement
+// which is taken from the cache does not reach the database, so it is not
+// recorded, otherwise the cache hits would dilute the failure ratio.
+func (cb *CircuitBreaker) donePrepare(permit circuitBreakerPermit, rt *roundTrip, err error) {
+	if err == nil && !rt.Made() {
+		return
+	}
+
+	cb.done(permit, err)
+}
+
+// setState changes the state. It should be called under the lock.
+func (cb *CircuitBreaker) setState(state CircuitBreakerState, now time.Time) {
+	from := cb.state
+
+	cb.state = state
+	cb.generation++
+	cb.windowStart, cb.total, cb.failures, cb.halfOpenInFlight = now, 0, 0, 0
+
+	if state == CircuitBreakerOpen {
+		cb.openedAt = now
+	}
+
+	for _, handler := range cb.stateChangeHandlers {
+		handler(from, state)
+	}
+}
+
+func (cb *CircuitBreaker) unavailableError(retryAfter time.Duration) error {
+	return &otelexample.Error{
+		Code:    otelexample.ErrorCodeUnavailable,
+		Message: "database is temporarily unavailable",
+		Err: &CircuitBreakerOpenError{
+			retryAfter: retryAfter,
+		},
+	}
+}
+
+// isCircuitBreakerFailure checks that error means the database is not
+// available, so errors like sql.ErrNoRows or constraint violations are not
+// counted as failures.
+func isCircuitBreakerFailure(err error) bool {
+	return isConnError(err) || errors.Is(err, context.DeadlineExceeded)
+}
+
+var _ Stmt = (*circuitBreakerStmt)(nil)
+
+// circuitBreakerStmt is a prepared statement which reports its results to the circuit breaker.
+type circuitBreakerStmt struct {
+	wrapped Stmt
+	breaker *CircuitBreaker
+}
+
+// ExecContext executes a prepared statement with the given arguments and
+// returns a Result summarizing the effect of the statement.
+func (stmt *circuitBreakerStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	permit, err := stmt.breaker.allow(true)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := stmt.wrapped.ExecContext(ctx, args...)
+	stmt.breaker.done(permit, err)
+
+	return result, err
+}
+
+// QueryRowContext executes a prepared query statement with the given arguments.
+func (stmt *circuitBreakerStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	permit, err := stmt.breaker.allow(true)
+	if err != nil {
+		return &rejectedRow{err: err}
+	}
+
+	row := stmt.wrapped.QueryRowContext(ctx, args...)
+	stmt.breaker.done(permit, row.Err())
+
+	return row
+}
+
+// QueryContext executes a prepared query statement with the given arguments
+// and returns the query results as a *Rows.
+func (stmt *circuitBreakerStmt) QueryContext(ctx context.Context, args ...any) (*sql.Rows, error) {
+	permit, err := stmt.breaker.allow(true)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.wrapped.QueryContext(ctx, args...)
+	stmt.breaker.done(permit, err)
+
+	return rows, err
+}
+
+// Close closes the statement.
+func (stmt *circuitBreakerStmt) Close(ctx context.Context) error {
+	return stmt.wrapped.Close(ctx)
+}
+
+var _ Row = (*rejectedRow)(nil)
+
+// rejectedRow is the row of the query which was rejected by the circuit breaker.
+type rejectedRow struct {
+	err error
+}
+
+// Scan returns the error of the rejection.
+func (row *rejectedRow) Scan(...any) error {
+	return row.err
+}
+
+// Err returns the error of the rejection.
+func (row *rejectedRow) Err() error {
+	return row.err
+}
+
+var _ Tx = (*circuitBreakerTx)(nil)
+
+// circuitBreakerTx is an in-progress database transaction which reports its
+// results to the circuit breaker.
+type circuitBreakerTx struct {
+	wrapped Tx
+	breaker *CircuitBreaker
+}
+
+// PrepareContext creates a prepared statement for later queries or executions.
+func (tx *circuitBreakerTx) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	permit, err := tx.breaker.allow(false)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, rt := withRoundTrip(ctx)
+
+	perconaStmt, err := tx.wrapped.PrepareContext(ctx, query)
+	tx.breaker.donePrepare(permit, rt, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &circuitBreakerStmt{
+		wrapped: perconaStmt,
+		breaker: tx.breaker,
+	}, nil
+}
+
+// Commit commits the transaction.
+func (tx *circuitBreakerTx) Commit(ctx context.Context) error {
+	permit, err := tx.breaker.allow(true)
+	if err != nil {
+		// The transaction is rolled back, so it does not hold the connection.
+		_ = tx.wrapped.Rollback(ctx)
+
+		return err
+	}
+
+	err = tx.wrapped.Commit(ctx)
+	tx.breaker.done(permit, err)
+
+	return err
+}
+
+// Rollback aborts the transaction. The rollback is always passed through, so
+// the transaction does not hold the connection when the breaker is open.
+func (tx *circuitBreakerTx) Rollback(ctx context.Context) error {
+	return tx.wrapped.Rollback(ctx)
+}
@@ -0,0 +1,76 @@
+package percona
+
+import (
+	"time"
+)
+
+// CircuitBreakerOption represents an option for configure CircuitBreaker instance.
+type CircuitBreakerOption interface {
+	apply(cb *CircuitBreaker)
+}
+
+type circuitBreakerOptionFunc func(cb *CircuitBreaker)
+
+func (fn circuitBreakerOptionFunc) apply(cb *CircuitBreaker) {
+	fn(cb)
+}
+
+// DefaultCircuitBreakerFailureRatio is the ratio of failed calls within the window which opens the circuit breaker.
+const DefaultCircuitBreakerFailureRatio = 0.5
+
+// WithCircuitBreakerFailureRatio sets up the ratio of failed calls within the window which opens the circuit breaker.
+func WithCircuitBreakerFailureRatio(ratio float64) CircuitBreakerOption {
+	return circuitBreakerOptionFunc(func(cb *CircuitBreaker) {
+		cb.failureRatio = ratio
+	})
+}
+
+// DefaultCircuitBreakerMinRequests is the minimum number of calls within the window before the failure ratio is
+// taken into account.
+const DefaultCircuitBreakerMinRequests = 10
+
+// WithCircuitBreakerMinRequests sets up the minimum number of calls within the window before the failure ratio is
+// taken into account.
+func WithCircuitBreakerMinRequests(minRequests uint64) CircuitBreakerOption {
+	return circuitBreakerOptionFunc(func(cb *CircuitBreaker) {
+		cb.minRequests = minRequests
+	})
+}
+
+// DefaultCircuitBreakerWindow is the period of time during which the calls are counted.
+const DefaultCircuitBreakerWindow = time.Second * 10
+
+// WithCircuitBreakerWindow sets up the period of time during which the calls are counted.
+func WithCircuitBreakerWindow(window time.Duration) CircuitBreakerOption {
+	return circuitBreakerOptionFunc(func(cb *CircuitBreaker) {
+		cb.window = window
+	})
+}
+
+// DefaultCircuitBreakerOpenTimeout is the period of time during which the open circuit breaker rejects calls.
+const DefaultCircuitBreakerOpenTimeout = time.Second * 5
+
+// WithCircuitBreakerOpenTimeout sets up the period of time during which the open circuit breaker rejects calls.
+func WithCircuitBreakerOpenTimeout(timeout time.Duration) CircuitBreakerOption {
+	return circuitBreakerOptionFunc(func(cb *CircuitBreaker) {
+		cb.openTimeout = timeout
+	})
+}
+
+// DefaultCircuitBreakerHalfOpenMaxRequests is the number of calls passed through by the half-open circuit breaker.
+const DefaultCircuitBreakerHalfOpenMaxRequests = 1
+
+// WithCircuitBreakerHalfOpenMaxRequests sets up the number of calls passed through by the half-open circuit breaker.
+func WithCircuitBreakerHalfOpenMaxRequests(maxRequests int) CircuitBreakerOption {
+	return circuitBreakerOptionFunc(func(cb *CircuitBreaker) {
+		cb.halfOpenMaxRequests = maxRequests
+	})
+}
+
+// WithCircuitBreakerStateChangeHandler adds the function which is called when the circuit breaker changes its
+// state. The handler is called synchronously, so it should not block.
+func WithCircuitBreakerStateChangeHandler(handler CircuitBreakerStateChangeHandler) CircuitBreakerOption {
+	return circuitBreakerOptionFunc(func(cb *CircuitBreaker) {
+		cb.stateChangeHandlers = append(cb.stateChangeHandlers, handler)
+	})
+}
@@ -0,0 +1,234 @@
+package percona_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona/perconatest"
+)
+
+const testOpenTimeout = time.Millisecond * 20
+
+func newCircuitBreaker(client *percona.Client) *percona.CircuitBreaker {
+	return percona.NewCircuitBreaker(client,
+		percona.WithCircuitBreakerMinRequests(1),
+		percona.WithCircuitBreakerFailureRatio(0.5),
+		percona.WithCircuitBreakerOpenTimeout(testOpenTimeout))
+}
+
+func exec(ctx context.Context, t *testing.T, cb *percona.CircuitBreaker, query string) error {
+	t.Helper()
+
+	stmt, err := cb.PrepareContext(ctx, query)
+	if err != nil {
+		return err // nolint:wrapcheck
+	}
+
+	defer func() {
+		if err := stmt.Close(ctx); err != nil {
+			t.Errorf("close stmt: %v", err)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx)
+
+	return err // nolint:wrapcheck
+}
+
+func assertState(t *testing.T, cb *percona.CircuitBreaker, expected percona.CircuitBreakerState) {
+	t.Helper()
+
+	if actual := cb.State(); actual != expected {
+		t.Fatalf("unexpected state: %s, expected %s", actual, expected)
+	}
+}
+
+func TestCircuitBreaker_CachedPrepareDoesNotClose(t *testing.T) {
+	t.Parallel()
+
+	script := perconatest.NewScript(t)
+	script.ExpectPrepare(`UPDATE accounts`)
+	script.ExpectExec(`UPDATE accounts`).WillReturnError(context.DeadlineExceeded)
+	script.ExpectExec(`UPDATE accounts`)
+
+	var (
+		ctx = context.Background()
+		cb  = newCircuitBreaker(connectClient(t, script, percona.WithStmtCacheSize(1)))
+	)
+
+	if err := exec(ctx, t, cb, `UPDATE accounts`); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertState(t, cb, percona.CircuitBreakerOpen)
+
+	time.Sleep(testOpenTimeout)
+
+	// The statement is taken from the cache, so the database is not probed.
+	stmt, err := cb.PrepareContext(ctx, `UPDATE accounts`)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	assertState(t, cb, percona.CircuitBreakerHalfOpen)
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+
+	assertState(t, cb, percona.CircuitBreakerClosed)
+
+	if err := stmt.Close(ctx); err != nil {
+		t.Fatalf("close stmt: %v", err)
+	}
+}
+
+func TestCircuitBreaker_CachedPrepareIsNotCounted(t *testing.T) {
+	t.Parallel()
+
+	// The failed execution invalidates the cached statement, so the next
+	// preparations reach the database.
+	script := perconatest.NewScript(t)
+	script.ExpectPrepare(`UPDATE accounts`)
+	script.ExpectExec(`UPDATE accounts`)
+	script.ExpectExec(`UPDATE accounts`).WillReturnError(context.DeadlineExceeded)
+	script.ExpectPrepare(`UPDATE accounts`).WillReturnError(context.DeadlineExceeded)
+	script.ExpectPrepare(`UPDATE accounts`).WillReturnError(context.DeadlineExceeded)
+
+	var (
+		ctx = context.Background()
+		cb  = percona.NewCircuitBreaker(connectClient(t, script, percona.WithStmtCacheSize(1)),
+			percona.WithCircuitBreakerMinRequests(1),
+			percona.WithCircuitBreakerFailureRatio(0.6),
+			percona.WithCircuitBreakerOpenTimeout(testOpenTimeout))
+	)
+
+	if err := exec(ctx, t, cb, `UPDATE accounts`); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+
+	// The first statement is taken from the cache, so only its execution is counted.
+	for i, expected := range []percona.CircuitBreakerState{
+		percona.CircuitBreakerClosed, // 1 of 3 calls failed
+		percona.CircuitBreakerClosed, // 2 of 4 calls failed
+		percona.CircuitBreakerOpen,   // 3 of 5 calls failed
+	} {
+		if err := exec(ctx, t, cb, `UPDATE accounts`); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("unexpected error of exec %d: %v", i, err)
+		}
+
+		assertState(t, cb, expected)
+	}
+}
+
+func TestCircuitBreaker_OpenRejectsQueryRow(t *testing.T) {
+	t.Parallel()
+
+	script := perconatest.NewScript(t)
+	script.ExpectPrepare(`SELECT username`)
+	script.ExpectQuery(`SELECT username`).WillReturnError(context.DeadlineExceeded)
+
+	var (
+		ctx = context.Background()
+		cb  = newCircuitBreaker(connectClient(t, script, percona.WithStmtCacheSize(1)))
+	)
+
+	stmt, err := cb.PrepareContext(ctx, `SELECT username`)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	defer func() {
+		if err := stmt.Close(ctx); err != nil {
+			t.Errorf("close stmt: %v", err)
+		}
+	}()
+
+	var username string
+
+	if err := stmt.QueryRowContext(ctx).Scan(&username); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertState(t, cb, percona.CircuitBreakerOpen)
+
+	// The rejected query is not passed to the database, so the script expects no more queries.
+	err = stmt.QueryRowContext(ctx).Scan(&username)
+	if code := otelexample.ErrorCodeFromError(err); code != otelexample.ErrorCodeUnavailable {
+		t.Fatalf("unexpected error code %s: %v", code, err)
+	}
+
+	if retryAfter := otelexample.RetryAfterFromError(err); retryAfter <= 0 || retryAfter > testOpenTimeout {
+		t.Fatalf("unexpected retry after: %s", retryAfter)
+	}
+}
+
+func TestCircuitBreaker_StragglerDoesNotClose(t *testing.T) {
+	t.Parallel()
+
+	script := perconatest.NewScript(t)
+	script.ExpectPrepare(`UPDATE accounts`)
+	script.ExpectExec(`UPDATE accounts`).WillDelayFor(testOpenTimeout * 10)
+	script.ExpectExec(`UPDATE accounts`).WillReturnError(context.DeadlineExceeded)
+
+	var (
+		ctx    = context.Background()
+		client = connectClient(t, script, percona.WithStmtCacheSize(1))
+		cb     = newCircuitBreaker(client)
+		wg     = sync.WaitGroup{}
+	)
+
+	stmt, err := cb.PrepareContext(ctx, `UPDATE accounts`)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	defer func() {
+		if err := stmt.Close(ctx); err != nil {
+			t.Errorf("close stmt: %v", err)
+		}
+	}()
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		if _, err := stmt.ExecContext(ctx); err != nil {
+			t.Errorf("straggler: %v", err)
+		}
+	}()
+
+	// The straggler takes the connection, so the failed call is made after it.
+	for client.Stats().InUse == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := stmt.ExecContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertState(t, cb, percona.CircuitBreakerOpen)
+
+	time.Sleep(testOpenTimeout)
+
+	// The straggler was admitted before the breaker was opened, so its success
+	// does not close the breaker.
+	cached, err := cb.PrepareContext(ctx, `UPDATE accounts`)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	wg.Wait()
+
+	assertState(t, cb, percona.CircuitBreakerHalfOpen)
+
+	if err := cached.Close(ctx); err != nil {
+		t.Fatalf("close stmt: %v", err)
+	}
+}
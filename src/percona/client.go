@@ -3,22 +3,20 @@ package percona
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
 )
 
-const (
-	// DriverName is the name of driver which will be used for work with sql data storage.
-	DriverName = "mysql"
+// DriverName is the name of driver which will be used for work with sql data storage.
+const DriverName = "mysql"
 
-	// ConnectTimeout is the maximum time for waiting until connect operation will be finished.
-	ConnectTimeout = time.Second
-
-	// PingTimeout is the maximum time for waiting until ping operation will be finished.
-	PingTimeout = time.Millisecond * 100
-)
+// ErrReplicationStopped is returned when the replica does not replicate data from the primary.
+var ErrReplicationStopped = errors.New("replication is stopped")
 
 // Preparer represents a service that can create a prepared statement.
 type Preparer interface {
@@ -41,14 +39,22 @@ var _ PrepareTxBeginner = (*Client)(nil)
 
 // Client represents an object for basic manipulation with Percona MySQL Database System.
 type Client struct {
-	db  *sql.DB
-	dsn string
+	db         *sql.DB
+	dsn        string
+	driverName string
 
 	connMaxLifetime time.Duration
 	maxIdleConns    int
 	connMaxIdleTime time.Duration
 	maxOpenConns    int
 
+	stmtCacheSize int
+	stmtCache     *stmtCache
+
+	connectTimeout          time.Duration
+	pingTimeout             time.Duration
+	deadlineExceededHandler DeadlineExceededHandler
+
 	dbName string
 	dbUser string
 }
@@ -56,14 +62,22 @@ type Client struct {
 // NewClient returns a new Client instance.
 func NewClient(dsn string, opts ...ClientOption) *Client {
 	client := &Client{
-		db:  nil,
-		dsn: dsn,
+		db:         nil,
+		dsn:        dsn,
+		driverName: DriverName,
 
 		connMaxLifetime: DefaultConnMaxLifetime,
 		maxIdleConns:    DefaultMaxIdleConns,
 		connMaxIdleTime: DefaultConnMaxIdleTime,
 		maxOpenConns:    DefaultMaxOpenConns,
 
+		stmtCacheSize: DefaultStmtCacheSize,
+		stmtCache:     nil,
+
+		connectTimeout:          DefaultConnectTimeout,
+		pingTimeout:             DefaultPingTimeout,
+		deadlineExceededHandler: nopDeadlineExceededHandler,
+
 		dbName: "",
 		dbUser: "",
 	}
@@ -72,6 +86,10 @@ func NewClient(dsn string, opts ...ClientOption) *Client {
 		opt.apply(client)
 	}
 
+	if client.stmtCacheSize > 0 {
+		client.stmtCache = newStmtCache(client.stmtCacheSize)
+	}
+
 	return client
 }
 
@@ -87,7 +105,7 @@ func (c *Client) DBUser() string {
 
 // Connect connects to a database.
 func (c *Client) Connect(ctx context.Context) error {
-	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(ConnectTimeout))
+	ctx, cancel := withOperationTimeout(ctx, c.connectTimeout)
 	defer cancel()
 
 	config, err := mysql.ParseDSN(c.dsn)
@@ -97,7 +115,7 @@ func (c *Client) Connect(ctx context.Context) error {
 
 	c.dbName, c.dbUser = config.DBName, config.User
 
-	if c.db, err = sql.Open(DriverName, c.dsn); err != nil {
+	if c.db, err = sql.Open(c.driverName, c.dsn); err != nil {
 		return fmt.Errorf("connect: %w", err)
 	}
 
@@ -106,7 +124,14 @@ func (c *Client) Connect(ctx context.Context) error {
 	c.db.SetConnMaxIdleTime(c.connMaxIdleTime)
 	c.db.SetMaxOpenConns(c.maxOpenConns)
 
-	if err = c.Ping(ctx); err != nil {
+	pingCtx, cancel := withOperationTimeout(ctx, c.pingTimeout)
+	defer cancel()
+
+	// The ping context is done when either the ping or the connect deadline is
+	// hit, so the hit is counted once as the connect one.
+	defer checkDeadline(pingCtx, OperationConnect, c.deadlineExceededHandler)
+
+	if err = c.ping(pingCtx); err != nil {
 		return fmt.Errorf("connect: %w", err)
 	}
 
@@ -116,9 +141,15 @@ func (c *Client) Connect(ctx context.Context) error {
 // Ping verifies a connection to the database is still alive,
 // establishing a connection if necessary.
 func (c *Client) Ping(ctx context.Context) error {
-	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(PingTimeout))
+	ctx, cancel := withOperationTimeout(ctx, c.pingTimeout)
 	defer cancel()
 
+	defer checkDeadline(ctx, OperationPing, c.deadlineExceededHandler)
+
+	return c.ping(ctx)
+}
+
+func (c *Client) ping(ctx context.Context) error {
 	if err := c.db.PingContext(ctx); err != nil {
 		return fmt.Errorf("ping: %w", err)
 	}
@@ -135,12 +166,60 @@ func (c *Client) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
 
 	return &tx{
 		sqlTx: sqlTx,
+
+		db:        c.db,
+		stmtCache: c.stmtCache,
+
+		uncached: nil,
 	}, nil
 }
 
 // PrepareContext creates a prepared statement for later queries or executions.
+//
+// When the statement cache is enabled the statement is taken from the cache
+// and prepared only if it is missing there.
 func (c *Client) PrepareContext(ctx context.Context, query string) (Stmt, error) {
-	sqlStmt, err := c.db.PrepareContext(ctx, query)
+	if c.stmtCache == nil {
+		return c.Uncached().PrepareContext(ctx, query)
+	}
+
+	entry, err := acquireStmt(ctx, c.db, c.stmtCache, query)
+	if err != nil {
+		return nil, fmt.Errorf("prepare: %w", err)
+	}
+
+	return &cachedStmt{
+		cache: c.stmtCache,
+		entry: entry,
+
+		sqlStmt: entry.sqlStmt,
+
+		closeOnce: sync.Once{},
+		closeErr:  nil,
+	}, nil
+}
+
+// Uncached returns the preparer which bypasses the statement cache. It is used
+// for one-off statements, which would evict the application statements from
+// the cache otherwise.
+func (c *Client) Uncached() Preparer {
+	return &uncachedPreparer{
+		client: c,
+	}
+}
+
+var _ Preparer = (*uncachedPreparer)(nil)
+
+// uncachedPreparer prepares the statements which are closed with Stmt.Close.
+type uncachedPreparer struct {
+	client *Client
+}
+
+// PrepareContext creates a prepared statement for later queries or executions.
+func (p *uncachedPreparer) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	markRoundTrip(ctx)
+
+	sqlStmt, err := p.client.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("prepare: %w", err)
 	}
@@ -149,3 +228,110 @@ func (c *Client) PrepareContext(ctx context.Context, query string) (Stmt, error)
 		sqlStmt: sqlStmt,
 	}, nil
 }
+
+// StmtCacheStats returns the prepared statement cache statistics.
+func (c *Client) StmtCacheStats() StmtCacheStats {
+	if c.stmtCache == nil {
+		return StmtCacheStats{
+			Size:      0,
+			Capacity:  0,
+			Hits:      0,
+			Misses:    0,
+			Evictions: 0,
+		}
+	}
+
+	return c.stmtCache.stats()
+}
+
+// Close closes the cached statements and the database.
+func (c *Client) Close() error {
+	if c.stmtCache != nil {
+		c.stmtCache.purge()
+	}
+
+	if c.db == nil {
+		return nil
+	}
+
+	if err := c.db.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+
+	return nil
+}
+
+func acquireStmt(ctx context.Context, db *sql.DB, cache *stmtCache, query string) (*stmtCacheEntry, error) {
+	return cache.acquire(query, func() (*sql.Stmt, error) {
+		markRoundTrip(ctx)
+
+		// The context is used only for preparation, so the cached statement
+		// is not bound to the caller cancellation.
+		return db.PrepareContext(ctx, query)
+	})
+}
+
+// ReplicationLag returns how far the replica is behind the primary. It returns
+// zero lag when the database is not a replica.
+func (c *Client) ReplicationLag(ctx context.Context) (time.Duration, error) {
+	rows, err := c.db.QueryContext(ctx, `SHOW REPLICA STATUS`)
+	if err != nil {
+		return 0, fmt.Errorf("replication lag: %w", err)
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("replication lag: %w", err)
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, fmt.Errorf("replication lag: %w", err)
+		}
+
+		return 0, nil
+	}
+
+	var (
+		values = make([]sql.NullString, len(columns))
+		dest   = make([]any, len(columns))
+	)
+
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return 0, fmt.Errorf("replication lag: %w", err)
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+
+		if !values[i].Valid {
+			return 0, fmt.Errorf("replication lag: %w", ErrReplicationStopped)
+		}
+
+		seconds, err := strconv.ParseInt(values[i].String, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("replication lag: %w", err)
+		}
+
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return 0, nil
+}
+
+// Stats returns database connection pool statistics.
+func (c *Client) Stats() sql.DBStats {
+	if c.db == nil {
+		return sql.DBStats{} // nolint:exhaustivestruct
+	}
+
+	return c.db.Stats()
+}
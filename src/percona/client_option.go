@@ -15,6 +15,13 @@ func (fn clientOptionFunc) apply(client *Client) {
 	fn(client)
 }
 
+// WithDriverName sets up the name of the registered database/sql driver. The DSN should still be in MySQL format.
+func WithDriverName(name string) ClientOption {
+	return clientOptionFunc(func(c *Client) {
+		c.driverName = name
+	})
+}
+
 // DefaultConnMaxLifetime is the maximum amount of time a connection may be reused.
 const DefaultConnMaxLifetime = time.Minute
 
@@ -54,3 +61,42 @@ func WithConnMaxIdleTime(connMaxIdleTime time.Duration) ClientOption {
 		c.connMaxIdleTime = connMaxIdleTime
 	})
 }
+
+// DefaultStmtCacheSize is the maximum number of prepared statements are cached by the client.
+const DefaultStmtCacheSize = 64
+
+// WithStmtCacheSize sets up the maximum number of prepared statements are cached by the client. Zero value disables
+// the cache, so every statement is prepared and closed on demand.
+func WithStmtCacheSize(stmtCacheSize int) ClientOption {
+	return clientOptionFunc(func(c *Client) {
+		c.stmtCacheSize = stmtCacheSize
+	})
+}
+
+// DefaultConnectTimeout is the maximum time for waiting until connect operation will be finished.
+const DefaultConnectTimeout = time.Second
+
+// WithConnectTimeout sets up the maximum time for waiting until connect operation will be finished.
+func WithConnectTimeout(timeout time.Duration) ClientOption {
+	return clientOptionFunc(func(c *Client) {
+		c.connectTimeout = timeout
+	})
+}
+
+// DefaultPingTimeout is the maximum time for waiting until ping operation will be finished.
+const DefaultPingTimeout = time.Millisecond * 100
+
+// WithPingTimeout sets up the maximum time for waiting until ping operation will be finished.
+func WithPingTimeout(timeout time.Duration) ClientOption {
+	return clientOptionFunc(func(c *Client) {
+		c.pingTimeout = timeout
+	})
+}
+
+// WithDeadlineExceededHandler sets up the function which is called when connect or ping operation has hit its
+// deadline.
+func WithDeadlineExceededHandler(handler DeadlineExceededHandler) ClientOption {
+	return clientOptionFunc(func(c *Client) {
+		c.deadlineExceededHandler = handler
+	})
+}
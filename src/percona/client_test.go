@@ -0,0 +1,266 @@
+package percona_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona/perconatest"
+)
+
+func TestClient_PrepareContext_Cached(t *testing.T) {
+	t.Parallel()
+
+	script := perconatest.NewScript(t)
+	script.ExpectPrepare(`SELECT 1`)
+	script.ExpectQuery(`SELECT 1`)
+	script.ExpectQuery(`SELECT 1`)
+
+	var (
+		ctx    = context.Background()
+		client = connectClient(t, script, percona.WithStmtCacheSize(1))
+	)
+
+	for i := 0; i < 2; i++ {
+		stmt, err := client.PrepareContext(ctx, `SELECT 1`)
+		if err != nil {
+			t.Fatalf("prepare: %v", err)
+		}
+
+		rows, err := stmt.QueryContext(ctx)
+		if err != nil {
+			t.Fatalf("query: %v", err)
+		}
+
+		if err := rows.Close(); err != nil {
+			t.Fatalf("close rows: %v", err)
+		}
+
+		if err := stmt.Close(ctx); err != nil {
+			t.Fatalf("close stmt: %v", err)
+		}
+	}
+
+	if stats := client.StmtCacheStats(); stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestTx_PrepareContext_SingleConnection(t *testing.T) {
+	t.Parallel()
+
+	script := perconatest.NewScript(t)
+	script.ExpectBegin()
+	script.ExpectExec(`INSERT INTO users`)
+	script.ExpectExec(`INSERT INTO users`)
+	script.ExpectCommit()
+
+	client := connectClient(t, script, percona.WithMaxOpenConns(1), percona.WithStmtCacheSize(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The statement is cached outside the transaction, so the second one is re-bound from the cache.
+	cached, err := client.PrepareContext(ctx, `INSERT INTO users VALUES (2)`)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	if err := cached.Close(ctx); err != nil {
+		t.Fatalf("close stmt: %v", err)
+	}
+
+	tx, err := client.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+
+	// The pool has the single connection which is held by the transaction, so the missing statement should be
+	// prepared on the transaction connection.
+	for _, query := range []string{`INSERT INTO users VALUES (1)`, `INSERT INTO users VALUES (2)`} {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			t.Fatalf("prepare %q: %v", query, err)
+		}
+
+		if _, err := stmt.ExecContext(ctx); err != nil {
+			t.Fatalf("exec %q: %v", query, err)
+		}
+
+		if err := stmt.Close(ctx); err != nil {
+			t.Fatalf("close stmt %q: %v", query, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	if stats := client.StmtCacheStats(); stats.Hits != 1 || stats.Misses != 2 || stats.Size != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestTx_PrepareContext_Cached(t *testing.T) {
+	t.Parallel()
+
+	script := perconatest.NewScript(t)
+
+	for i := 0; i < 2; i++ {
+		script.ExpectBegin()
+		script.ExpectExec(`INSERT INTO users`)
+		script.ExpectCommit()
+	}
+
+	var (
+		ctx    = context.Background()
+		client = connectClient(t, script, percona.WithMaxOpenConns(1), percona.WithStmtCacheSize(1))
+	)
+
+	// The statement which is missing in the first transaction is prepared on the transaction connection and cached
+	// after the commit, so the second one re-binds it.
+	for i := 0; i < 2; i++ {
+		tx, err := client.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("begin tx: %v", err)
+		}
+
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO users VALUES (1)`)
+		if err != nil {
+			t.Fatalf("prepare: %v", err)
+		}
+
+		if _, err := stmt.ExecContext(ctx); err != nil {
+			t.Fatalf("exec: %v", err)
+		}
+
+		if err := stmt.Close(ctx); err != nil {
+			t.Fatalf("close stmt: %v", err)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+	}
+
+	if stats := client.StmtCacheStats(); stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestClient_PrepareContext_Error(t *testing.T) {
+	t.Parallel()
+
+	script := perconatest.NewScript(t)
+	script.ExpectPrepare("").WillReturnError(perconatest.MySQLError(1064, "syntax error"))
+	script.ExpectPrepare("")
+
+	var (
+		ctx    = context.Background()
+		client = connectClient(t, script, percona.WithStmtCacheSize(1))
+	)
+
+	if _, err := client.PrepareContext(ctx, `SELEC 1`); err == nil {
+		t.Fatal("expected prepare error, got nil")
+	}
+
+	// The failed statement is not cached, so it is prepared again.
+	stmt, err := client.PrepareContext(ctx, `SELECT 1`)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	if err := stmt.Close(ctx); err != nil {
+		t.Fatalf("close stmt: %v", err)
+	}
+
+	if stats := client.StmtCacheStats(); stats.Misses != 2 || stats.Size != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestClient_ReplicationLag(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name  string
+		rows  *perconatest.Rows
+		lag   time.Duration
+		isErr func(err error) bool
+	}{
+		{
+			name:  "NotReplica",
+			rows:  perconatest.NewRows("Seconds_Behind_Source"),
+			lag:   0,
+			isErr: func(err error) bool { return err == nil },
+		},
+		{
+			name:  "Lagging",
+			rows:  perconatest.NewRows("Replica_IO_State", "Seconds_Behind_Source").AddRow("Waiting", "3"),
+			lag:   time.Second * 3,
+			isErr: func(err error) bool { return err == nil },
+		},
+		{
+			name:  "LegacyColumn",
+			rows:  perconatest.NewRows("Seconds_Behind_Master").AddRow("1"),
+			lag:   time.Second,
+			isErr: func(err error) bool { return err == nil },
+		},
+		{
+			name:  "Stopped",
+			rows:  perconatest.NewRows("Seconds_Behind_Source").AddRow(nil),
+			lag:   0,
+			isErr: func(err error) bool { return errors.Is(err, percona.ErrReplicationStopped) },
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			script := perconatest.NewScript(t)
+			script.ExpectQuery(`^SHOW REPLICA STATUS$`).WillReturnRows(tc.rows)
+
+			lag, err := connectClient(t, script).ReplicationLag(context.Background())
+			if !tc.isErr(err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if lag != tc.lag {
+				t.Errorf("unexpected lag: %s, expected %s", lag, tc.lag)
+			}
+		})
+	}
+}
+
+func TestClient_Connect_DeadlineExceeded(t *testing.T) {
+	t.Parallel()
+
+	var operations []percona.Operation
+
+	client := percona.NewClient(perconatest.NewScript(t).DSN(),
+		percona.WithDriverName(perconatest.DriverName),
+		percona.WithDeadlineExceededHandler(func(_ context.Context, op percona.Operation) {
+			operations = append(operations, op)
+		}))
+
+	t.Cleanup(func() {
+		if err := client.Close(); err != nil {
+			t.Errorf("close: %v", err)
+		}
+	})
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+
+	if err := client.Connect(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The ping is the part of the connect operation, so the deadline hit is counted once.
+	if len(operations) != 1 || operations[0] != percona.OperationConnect {
+		t.Errorf("unexpected operations: %v", operations)
+	}
+}
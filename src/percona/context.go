@@ -0,0 +1,111 @@
+package percona
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+type contextKey int
+
+const (
+	primaryContextKey contextKey = iota
+	sessionContextKey
+	shardContextKey
+	shardKeyContextKey
+	roundTripContextKey
+)
+
+// session tracks whether the write was made within the request.
+type session struct {
+	written int32
+}
+
+// roundTrip tracks whether the call reached the database.
+type roundTrip struct {
+	made int32
+}
+
+// Made returns true when the database was called.
+func (rt *roundTrip) Made() bool {
+	return atomic.LoadInt32(&rt.made) == 1
+}
+
+// WithPrimary returns a copy of parent context which forces all reads to go to the primary.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey, true)
+}
+
+// WithReadYourWrites returns a copy of parent context which forces reads to
+// go to the primary after the first write made with this context.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(sessionContextKey).(*session); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, sessionContextKey, &session{
+		written: 0,
+	})
+}
+
+// ReadYourWritesHandler is the middleware which makes reads after a write within
+// the same HTTP request go to the primary.
+func ReadYourWritesHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		next.ServeHTTP(writer, request.WithContext(WithReadYourWrites(request.Context())))
+	})
+}
+
+func markWrite(ctx context.Context) {
+	if sess, ok := ctx.Value(sessionContextKey).(*session); ok {
+		atomic.StoreInt32(&sess.written, 1)
+	}
+}
+
+// withRoundTrip returns a copy of parent context which tracks whether the
+// statement was prepared by the database or taken from the cache.
+func withRoundTrip(ctx context.Context) (context.Context, *roundTrip) {
+	rt := &roundTrip{
+		made: 0,
+	}
+
+	return context.WithValue(ctx, roundTripContextKey, rt), rt
+}
+
+func markRoundTrip(ctx context.Context) {
+	if rt, ok := ctx.Value(roundTripContextKey).(*roundTrip); ok {
+		atomic.StoreInt32(&rt.made, 1)
+	}
+}
+
+func isPrimaryForced(ctx context.Context) bool {
+	if forced, _ := ctx.Value(primaryContextKey).(bool); forced {
+		return true
+	}
+
+	sess, ok := ctx.Value(sessionContextKey).(*session)
+
+	return ok && atomic.LoadInt32(&sess.written) == 1
+}
+
+// WithShard returns a copy of parent context which routes queries to the shard.
+func WithShard(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, shardContextKey, name)
+}
+
+// WithShardKey returns a copy of parent context which routes queries to the shard the key is located on.
+func WithShardKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, shardKeyContextKey, key)
+}
+
+func shardFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(shardContextKey).(string)
+
+	return name, ok
+}
+
+func shardKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(shardKeyContextKey).(string)
+
+	return key, ok
+}
@@ -0,0 +1,36 @@
+package percona
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Operation is the name of operation which is executed with its own deadline.
+type Operation string
+
+const (
+	OperationConnect = Operation("connect")
+	OperationPing    = Operation("ping")
+	OperationCreate  = Operation("create")
+	OperationList    = Operation("list")
+	OperationGet     = Operation("get")
+)
+
+// DeadlineExceededHandler is called when the operation has hit its deadline.
+type DeadlineExceededHandler func(ctx context.Context, op Operation)
+
+func nopDeadlineExceededHandler(context.Context, Operation) {}
+
+// withOperationTimeout returns a copy of the parent context with the operation
+// deadline. The parent deadline takes priority when it is shorter.
+func withOperationTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithDeadline(ctx, time.Now().Add(timeout))
+}
+
+// checkDeadline calls handler when the operation context has hit its deadline.
+func checkDeadline(ctx context.Context, op Operation, handler DeadlineExceededHandler) {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		handler(ctx, op)
+	}
+}
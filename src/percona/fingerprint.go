@@ -0,0 +1,365 @@
+package percona
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// QueryFingerprint describes the query regardless of its literal values and formatting.
+type QueryFingerprint struct {
+	// Normalized is the query text without comments and literals and with collapsed whitespaces.
+	Normalized string
+
+	// Digest is the stable hash of the normalized query.
+	Digest string
+
+	// Operation is the SQL command of the query like SELECT or INSERT.
+	Operation string
+
+	// Name is the short query name. It is taken from the context when it was
+	// set by WithQueryName, otherwise it is built from the operation and the
+	// main table like select_user_accounts.
+	Name string
+}
+
+type queryNameContextKey struct{}
+
+// WithQueryName returns a copy of parent context which carries the name of the query which will be prepared.
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameContextKey{}, name)
+}
+
+// FingerprintContext returns the query fingerprint with the query name from the context.
+func FingerprintContext(ctx context.Context, query string) QueryFingerprint {
+	fingerprint := Fingerprint(query)
+
+	if name, ok := ctx.Value(queryNameContextKey{}).(string); ok && name != "" {
+		fingerprint.Name = name
+	}
+
+	return fingerprint
+}
+
+// maxCachedFingerprints is the maximum number of fingerprints are kept in memory.
+const maxCachedFingerprints = 4096
+
+// nolint:gochecknoglobals
+var fingerprints = struct {
+	sync.RWMutex
+	values map[string]QueryFingerprint
+}{
+	RWMutex: sync.RWMutex{},
+	values:  make(map[string]QueryFingerprint),
+}
+
+// Fingerprint returns the query fingerprint. Fingerprints of the queries
+// are cached, because the application uses the limited set of them.
+func Fingerprint(query string) QueryFingerprint {
+	fingerprints.RLock()
+	fingerprint, ok := fingerprints.values[query]
+	fingerprints.RUnlock()
+
+	if ok {
+		return fingerprint
+	}
+
+	fingerprint = newFingerprint(query)
+
+	fingerprints.Lock()
+	if len(fingerprints.values) < maxCachedFingerprints {
+		fingerprints.values[query] = fingerprint
+	}
+	fingerprints.Unlock()
+
+	return fingerprint
+}
+
+func newFingerprint(query string) QueryFingerprint {
+	tokens := tokenizeQuery(query)
+	normalized := normalizeTokens(tokens)
+	sum := sha256.Sum256([]byte(normalized))
+
+	const digestSize = 8
+
+	operation, table := describeQuery(tokens)
+
+	name := strings.ToLower(operation)
+	if table != "" {
+		name += "_" + table
+	}
+
+	if name == "" {
+		name = "unknown"
+	}
+
+	return QueryFingerprint{
+		Normalized: normalized,
+		Digest:     hex.EncodeToString(sum[:digestSize]),
+		Operation:  operation,
+		Name:       name,
+	}
+}
+
+type queryTokenKind int
+
+const (
+	queryTokenWord queryTokenKind = iota
+	queryTokenIdentifier
+	queryTokenLiteral
+	queryTokenPunct
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+}
+
+// nolint:cyclop,funlen
+func tokenizeQuery(query string) []queryToken {
+	var (
+		tokens = make([]queryToken, 0, len(query)/4) // nolint:gomnd
+		pos    = 0
+	)
+
+	for pos < len(query) {
+		char := query[pos]
+
+		switch {
+		case isQuerySpace(char):
+			pos++
+		case char == '#' || strings.HasPrefix(query[pos:], "--"):
+			if end := strings.IndexByte(query[pos:], '\n'); end >= 0 {
+				pos += end + 1
+			} else {
+				pos = len(query)
+			}
+		case strings.HasPrefix(query[pos:], "/*"):
+			if end := strings.Index(query[pos+2:], "*/"); end >= 0 {
+				pos += end + 4 // nolint:gomnd
+			} else {
+				pos = len(query)
+			}
+		case char == '\'' || char == '"':
+			pos = skipQueryString(query, pos)
+			tokens = append(tokens, queryToken{kind: queryTokenLiteral, text: "?"})
+		case char == '`':
+			end := strings.IndexByte(query[pos+1:], '`')
+			if end < 0 {
+				end = len(query) - pos - 1
+			}
+
+			tokens = append(tokens, queryToken{kind: queryTokenIdentifier, text: query[pos+1 : pos+1+end]})
+			pos += end + 2 // nolint:gomnd
+		case char == '?':
+			tokens = append(tokens, queryToken{kind: queryTokenLiteral, text: "?"})
+			pos++
+		case isQueryDigit(char) || (char == '.' && pos+1 < len(query) && isQueryDigit(query[pos+1])):
+			pos = skipQueryNumber(query, pos)
+			tokens = append(tokens, queryToken{kind: queryTokenLiteral, text: "?"})
+		case isQueryWordChar(char):
+			start := pos
+			for pos < len(query) && isQueryWordChar(query[pos]) {
+				pos++
+			}
+
+			tokens = append(tokens, queryToken{kind: queryTokenWord, text: strings.ToLower(query[start:pos])})
+		case strings.IndexByte("<>=!", char) >= 0:
+			start := pos
+			for pos < len(query) && strings.IndexByte("<>=!", query[pos]) >= 0 {
+				pos++
+			}
+
+			tokens = append(tokens, queryToken{kind: queryTokenPunct, text: query[start:pos]})
+		default:
+			tokens = append(tokens, queryToken{kind: queryTokenPunct, text: string(char)})
+			pos++
+		}
+	}
+
+	return tokens
+}
+
+func skipQueryString(query string, pos int) int {
+	quote := query[pos]
+
+	for pos++; pos < len(query); pos++ {
+		switch query[pos] {
+		case '\\':
+			pos++
+		case quote:
+			if pos+1 < len(query) && query[pos+1] == quote {
+				pos++
+
+				continue
+			}
+
+			return pos + 1
+		}
+	}
+
+	return pos
+}
+
+func skipQueryNumber(query string, pos int) int {
+	if strings.HasPrefix(query[pos:], "0x") || strings.HasPrefix(query[pos:], "0X") {
+		pos += 2
+	}
+
+	for pos < len(query) {
+		char := query[pos]
+
+		switch {
+		case isQueryWordChar(char) || char == '.':
+			pos++
+		case (char == '+' || char == '-') && (query[pos-1] == 'e' || query[pos-1] == 'E'):
+			pos++
+		default:
+			return pos
+		}
+	}
+
+	return pos
+}
+
+func isQuerySpace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r' || char == '\f'
+}
+
+func isQueryDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+func isQueryWordChar(char byte) bool {
+	return char == '_' || char == '$' || isQueryDigit(char) || (char >= 'a' && char <= 'z') ||
+		(char >= 'A' && char <= 'Z')
+}
+
+// nolint:gochecknoglobals
+var (
+	inListRegexp     = regexp.MustCompile(`\bin \(\?(?:, \?)*\)`)
+	valuesListRegexp = regexp.MustCompile(`\bvalues (\([?, ]+\))(?:, \([?, ]+\))+`)
+)
+
+func normalizeTokens(tokens []queryToken) string {
+	var builder strings.Builder
+
+	for i, token := range tokens {
+		if i > 0 && needsQuerySpace(tokens[i-1], token) {
+			builder.WriteByte(' ')
+		}
+
+		if token.kind == queryTokenIdentifier {
+			builder.WriteString(strings.ToLower(token.text))
+
+			continue
+		}
+
+		builder.WriteString(token.text)
+	}
+
+	normalized := inListRegexp.ReplaceAllString(builder.String(), "in (?+)")
+
+	return valuesListRegexp.ReplaceAllString(normalized, "values $1")
+}
+
+func needsQuerySpace(prev, token queryToken) bool {
+	switch {
+	case token.text == ")" || token.text == "," || token.text == ".":
+		return false
+	case prev.text == "(" || prev.text == ".":
+		return false
+	case token.text == "(":
+		// The word before the bracket is a function call or a column list unless it is a keyword.
+		return prev.kind == queryTokenPunct || prev.kind == queryTokenLiteral ||
+			(prev.kind == queryTokenWord && isSpacedKeyword(prev.text))
+	}
+
+	return true
+}
+
+func isSpacedKeyword(word string) bool {
+	switch word {
+	case "in", "values", "from", "join", "on", "and", "or", "not", "exists", "as", "where", "select":
+		return true
+	}
+
+	return false
+}
+
+// describeQuery returns the operation and the main table of the query.
+func describeQuery(tokens []queryToken) (string, string) {
+	var operation string
+
+	for i, token := range tokens {
+		if token.kind != queryTokenWord {
+			continue
+		}
+
+		operation = strings.ToUpper(token.text)
+
+		var marker string
+
+		switch token.text {
+		case "select", "delete":
+			marker = "from"
+		case "insert", "replace":
+			marker = "into"
+		case "update":
+			return operation, tableAfter(tokens[i+1:], "")
+		default:
+			return operation, ""
+		}
+
+		return operation, tableAfter(tokens[i+1:], marker)
+	}
+
+	return operation, ""
+}
+
+// tableAfter returns the first table name which follows the marker keyword.
+func tableAfter(tokens []queryToken, marker string) string {
+	for i := 0; i < len(tokens); i++ {
+		if marker != "" {
+			if tokens[i].kind != queryTokenWord || tokens[i].text != marker {
+				continue
+			}
+
+			i++
+		}
+
+		for ; i < len(tokens) && tokens[i].kind == queryTokenWord && isTableModifier(tokens[i].text); i++ {
+		}
+
+		if i >= len(tokens) || (tokens[i].kind != queryTokenWord && tokens[i].kind != queryTokenIdentifier) {
+			if marker == "" {
+				return ""
+			}
+
+			continue
+		}
+
+		table := tokens[i].text
+
+		// The table could be qualified with the schema name.
+		if i+2 < len(tokens) && tokens[i+1].text == "." {
+			table = tokens[i+2].text
+		}
+
+		return strings.ToLower(table)
+	}
+
+	return ""
+}
+
+func isTableModifier(word string) bool {
+	switch word {
+	case "low_priority", "ignore", "quick", "delayed", "high_priority":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,175 @@
+package percona_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+)
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name       string
+		query      string
+		normalized string
+		operation  string
+		queryName  string
+	}{
+		{
+			name: "Literals",
+			query: `SELECT * FROM users WHERE name = 'O''Brien' AND note = "a\"b" AND age > 42 ` +
+				`AND score = 1.5e-3 AND flags = 0xFF`,
+			normalized: "select * from users where name = ? and note = ? and age > ? and score = ? and flags = ?",
+			operation:  "SELECT",
+			queryName:  "select_users",
+		},
+		{
+			name:       "Comments",
+			query:      "/* app */ SELECT id -- trailing\nFROM users # hash\nWHERE id = ?",
+			normalized: "select id from users where id = ?",
+			operation:  "SELECT",
+			queryName:  "select_users",
+		},
+		{
+			name:       "BacktickIdentifiers",
+			query:      "SELECT `ID` FROM `User_Accounts` WHERE `id` = 1",
+			normalized: "select id from user_accounts where id = ?",
+			operation:  "SELECT",
+			queryName:  "select_user_accounts",
+		},
+		{
+			name:       "InList",
+			query:      "SELECT id FROM users WHERE id IN (1, 2, 3)",
+			normalized: "select id from users where id in (?+)",
+			operation:  "SELECT",
+			queryName:  "select_users",
+		},
+		{
+			name:       "InListOfOne",
+			query:      "SELECT id FROM users WHERE id IN (?)",
+			normalized: "select id from users where id in (?+)",
+			operation:  "SELECT",
+			queryName:  "select_users",
+		},
+		{
+			name:       "ValuesList",
+			query:      "INSERT INTO users (id, name) VALUES (?, ?), (?, ?), (1, 'a')",
+			normalized: "insert into users(id, name) values (?, ?)",
+			operation:  "INSERT",
+			queryName:  "insert_users",
+		},
+		{
+			name:       "SchemaQualifiedTable",
+			query:      "SELECT id FROM `app`.`users`",
+			normalized: "select id from app.users",
+			operation:  "SELECT",
+			queryName:  "select_users",
+		},
+		{
+			name:       "TableModifier",
+			query:      "UPDATE LOW_PRIORITY users SET name = ? WHERE id = ?",
+			normalized: "update low_priority users set name = ? where id = ?",
+			operation:  "UPDATE",
+			queryName:  "update_users",
+		},
+		{
+			name:       "UnterminatedQuote",
+			query:      "SELECT id FROM users WHERE name = 'abc",
+			normalized: "select id from users where name = ?",
+			operation:  "SELECT",
+			queryName:  "select_users",
+		},
+		{
+			name:       "UnterminatedIdentifier",
+			query:      "SELECT `id FROM users",
+			normalized: "select id from users",
+			operation:  "SELECT",
+			queryName:  "select",
+		},
+		{
+			name:       "UnterminatedComment",
+			query:      "SELECT id FROM users /* comment",
+			normalized: "select id from users",
+			operation:  "SELECT",
+			queryName:  "select_users",
+		},
+		{
+			name:       "Empty",
+			query:      "",
+			normalized: "",
+			operation:  "",
+			queryName:  "unknown",
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fingerprint := percona.Fingerprint(tc.query)
+
+			if fingerprint.Normalized != tc.normalized {
+				t.Errorf("unexpected normalized query %q", fingerprint.Normalized)
+			}
+
+			if fingerprint.Operation != tc.operation {
+				t.Errorf("unexpected operation %q", fingerprint.Operation)
+			}
+
+			if fingerprint.Name != tc.queryName {
+				t.Errorf("unexpected name %q", fingerprint.Name)
+			}
+
+			if len(fingerprint.Digest) != 16 {
+				t.Errorf("unexpected digest %q", fingerprint.Digest)
+			}
+		})
+	}
+}
+
+func TestFingerprint_Digest(t *testing.T) {
+	t.Parallel()
+
+	// The queries which differ only in the literals and formatting have the same digest.
+	for _, queries := range [][2]string{
+		{"SELECT id FROM users WHERE id IN (1, 2, 3)", "select id\nfrom users where id in (?)"},
+		{"INSERT INTO users (id) VALUES (1), (2)", "/* batch */ INSERT INTO `users` (`id`) VALUES (?)"},
+		{"SELECT id FROM app.users WHERE name = 'alice'", "SELECT id FROM `app`.`users` WHERE name = ?"},
+	} {
+		if lhs, rhs := percona.Fingerprint(queries[0]), percona.Fingerprint(queries[1]); lhs.Digest != rhs.Digest {
+			t.Errorf("digests of %q and %q differ: %s, %s", lhs.Normalized, rhs.Normalized, lhs.Digest, rhs.Digest)
+		}
+	}
+
+	lhs, rhs := percona.Fingerprint("SELECT id FROM users"), percona.Fingerprint("SELECT id FROM accounts")
+	if lhs.Digest == rhs.Digest {
+		t.Errorf("digests of the different queries are equal: %s", lhs.Digest)
+	}
+}
+
+func TestFingerprintContext(t *testing.T) {
+	t.Parallel()
+
+	const query = "SELECT id FROM users WHERE id = ?"
+
+	ctx := percona.WithQueryName(context.Background(), "find_user_by_id")
+
+	fingerprint := percona.FingerprintContext(ctx, query)
+	if fingerprint.Name != "find_user_by_id" {
+		t.Errorf("unexpected name %q", fingerprint.Name)
+	}
+
+	// The name does not change the other fields and does not leak into the cached fingerprint.
+	if expected := percona.Fingerprint(query); expected.Name != "select_users" ||
+		fingerprint.Digest != expected.Digest || fingerprint.Normalized != expected.Normalized {
+		t.Errorf("unexpected fingerprint %+v, expected %+v", fingerprint, expected)
+	}
+
+	// The empty name is ignored.
+	fingerprint = percona.FingerprintContext(percona.WithQueryName(context.Background(), ""), query)
+	if fingerprint.Name != "select_users" {
+		t.Errorf("unexpected name %q", fingerprint.Name)
+	}
+}
@@ -0,0 +1,30 @@
+package percona_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona/perconatest"
+)
+
+// connectClient returns the client which follows the script.
+func connectClient(t *testing.T, script *perconatest.Script, opts ...percona.ClientOption) *percona.Client {
+	t.Helper()
+
+	client := percona.NewClient(script.DSN(), append([]percona.ClientOption{
+		percona.WithDriverName(perconatest.DriverName),
+	}, opts...)...)
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := client.Close(); err != nil {
+			t.Errorf("close: %v", err)
+		}
+	})
+
+	return client
+}
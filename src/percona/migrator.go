@@ -0,0 +1,451 @@
+package percona
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// NilVersion is the schema version when no migrations were applied.
+const NilVersion int64 = -1
+
+var (
+	// ErrDirtySchema is returned when the previous migration was failed and
+	// the schema should be fixed manually and forced to the version.
+	ErrDirtySchema = errors.New("schema is dirty")
+
+	// ErrMigrationLocked is returned when the migration lock could not be acquired.
+	ErrMigrationLocked = errors.New("migration lock could not be acquired")
+
+	// ErrUnknownVersion is returned when the schema version does not match any migration.
+	ErrUnknownVersion = errors.New("unknown schema version")
+)
+
+// MigrationStatus describes the state of the database schema.
+type MigrationStatus struct {
+	// Version is the current schema version.
+	Version int64
+
+	// Dirty is the flag that the last migration was failed.
+	Dirty bool
+
+	// Latest is the version of the latest known migration.
+	Latest int64
+
+	// Pending is the list of migration versions which are not applied yet.
+	Pending []int64
+}
+
+// IsBehind checks that the schema is not migrated up to the latest version.
+func (s MigrationStatus) IsBehind() bool {
+	return s.Dirty || s.Version < s.Latest
+}
+
+type migration struct {
+	version int64
+	name    string
+	up      string
+	down    string
+}
+
+// Migrator applies schema migrations. The version is tracked in the table
+// which is compatible with golang-migrate, so both tools could be used
+// against the same database.
+type Migrator struct {
+	dsn    string
+	source fs.FS
+
+	table       string
+	lockTimeout time.Duration
+}
+
+// NewMigrator returns a new Migrator instance.
+func NewMigrator(dsn string, source fs.FS, opts ...MigratorOption) *Migrator {
+	migrator := &Migrator{
+		dsn:    dsn,
+		source: source,
+
+		table:       DefaultMigrationsTable,
+		lockTimeout: DefaultMigrationLockTimeout,
+	}
+
+	for _, opt := range opts {
+		opt.apply(migrator)
+	}
+
+	return migrator
+}
+
+// Up applies the given number of pending migrations. Zero steps applies all of them.
+func (m *Migrator) Up(ctx context.Context, steps int) error {
+	if err := m.withLock(ctx, func(conn *sql.Conn, migrations []migration) error {
+		return m.up(ctx, conn, migrations, steps)
+	}); err != nil {
+		return fmt.Errorf("migrate up: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) up(ctx context.Context, conn *sql.Conn, migrations []migration, steps int) error {
+	version, dirty, err := m.version(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("version %d: %w", version, ErrDirtySchema)
+	}
+
+	for _, mig := range migrations {
+		if mig.version <= version {
+			continue
+		}
+
+		if err := m.setVersion(ctx, conn, mig.version, true); err != nil {
+			return err
+		}
+
+		if err := m.exec(ctx, conn, mig.up); err != nil {
+			return fmt.Errorf("apply %d_%s: %w", mig.version, mig.name, err)
+		}
+
+		if err := m.setVersion(ctx, conn, mig.version, false); err != nil {
+			return err
+		}
+
+		if steps--; steps == 0 {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Down reverts the given number of applied migrations. Zero steps reverts all of them.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if err := m.withLock(ctx, func(conn *sql.Conn, migrations []migration) error {
+		return m.down(ctx, conn, migrations, steps)
+	}); err != nil {
+		return fmt.Errorf("migrate down: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) down(ctx context.Context, conn *sql.Conn, migrations []migration, steps int) error {
+	version, dirty, err := m.version(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("version %d: %w", version, ErrDirtySchema)
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		mig := migrations[i]
+		if mig.version > version {
+			continue
+		}
+
+		prev := NilVersion
+		if i > 0 {
+			prev = migrations[i-1].version
+		}
+
+		if err := m.setVersion(ctx, conn, prev, true); err != nil {
+			return err
+		}
+
+		if err := m.exec(ctx, conn, mig.down); err != nil {
+			return fmt.Errorf("revert %d_%s: %w", mig.version, mig.name, err)
+		}
+
+		if err := m.setVersion(ctx, conn, prev, false); err != nil {
+			return err
+		}
+
+		if steps--; steps == 0 {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Force sets the schema version and resets the dirty flag without applying migrations.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if err := m.withLock(ctx, func(conn *sql.Conn, migrations []migration) error {
+		if version == NilVersion {
+			return m.setVersion(ctx, conn, version, false)
+		}
+
+		for _, mig := range migrations {
+			if mig.version == version {
+				return m.setVersion(ctx, conn, version, false)
+			}
+		}
+
+		return fmt.Errorf("version %d: %w", version, ErrUnknownVersion)
+	}); err != nil {
+		return fmt.Errorf("migrate force: %w", err)
+	}
+
+	return nil
+}
+
+// Status returns the state of the database schema.
+func (m *Migrator) Status(ctx context.Context) (MigrationStatus, error) {
+	var status MigrationStatus
+
+	if err := m.withConn(ctx, func(conn *sql.Conn, migrations []migration) error {
+		var err error
+
+		if status.Version, status.Dirty, err = m.version(ctx, conn); err != nil {
+			return err
+		}
+
+		status.Latest = NilVersion
+
+		for _, mig := range migrations {
+			status.Latest = mig.version
+
+			if mig.version > status.Version {
+				status.Pending = append(status.Pending, mig.version)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return status, fmt.Errorf("migrate status: %w", err)
+	}
+
+	return status, nil
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, migrations []migration) error) error {
+	return m.withConn(ctx, func(conn *sql.Conn, migrations []migration) (err error) {
+		var acquired sql.NullInt64
+
+		err = conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, m.lockName(),
+			int64(m.lockTimeout/time.Second)).Scan(&acquired)
+		if err != nil {
+			return err
+		}
+
+		if !acquired.Valid || acquired.Int64 != 1 {
+			return ErrMigrationLocked
+		}
+
+		defer func(conn *sql.Conn, err *error) {
+			if _, releaseErr := conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(?)`,
+				m.lockName()); releaseErr != nil && *err == nil {
+				*err = releaseErr
+			}
+		}(conn, &err)
+
+		// The table is created only by the commands which change the schema,
+		// so the status could be checked by the user with read-only grants.
+		if _, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+m.quotedTable()+
+			` (version bigint not null primary key, dirty boolean not null)`); err != nil {
+			return err
+		}
+
+		return fn(conn, migrations)
+	})
+}
+
+func (m *Migrator) withConn(ctx context.Context, fn func(conn *sql.Conn, migrations []migration) error) (err error) {
+	migrations, err := loadMigrations(m.source)
+	if err != nil {
+		return err
+	}
+
+	config, err := mysql.ParseDSN(m.dsn)
+	if err != nil {
+		return err
+	}
+
+	// Migration files contain several statements.
+	config.MultiStatements = true
+
+	db, err := sql.Open(DriverName, config.FormatDSN())
+	if err != nil {
+		return err
+	}
+
+	defer func(db *sql.DB, err *error) {
+		if closeErr := db.Close(); closeErr != nil && *err == nil {
+			*err = closeErr
+		}
+	}(db, &err)
+
+	// Advisory lock is held by the session, so all queries must be executed on the same connection.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func(conn *sql.Conn, err *error) {
+		if closeErr := conn.Close(); closeErr != nil && *err == nil {
+			*err = closeErr
+		}
+	}(conn, &err)
+
+	return fn(conn, migrations)
+}
+
+func (m *Migrator) version(ctx context.Context, conn *sql.Conn) (int64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM `+m.quotedTable()+` LIMIT 1`).
+		Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) || isNoSuchTable(err) {
+		return NilVersion, false, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
+
+// isNoSuchTable checks that error means the migrations table does not exist,
+// because no migrations were applied yet.
+func isNoSuchTable(err error) bool {
+	// ER_NO_SUCH_TABLE is returned when the table does not exist.
+	const errNoSuchTable = 1146
+
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == errNoSuchTable
+}
+
+func (m *Migrator) setVersion(ctx context.Context, conn *sql.Conn, version int64, dirty bool) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func(tx *sql.Tx, err *error) {
+		if *err == nil {
+			return
+		}
+
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			*err = rollbackErr
+		}
+	}(tx, &err)
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM `+m.quotedTable()); err != nil {
+		return err
+	}
+
+	if version >= 0 || dirty {
+		if _, err = tx.ExecContext(ctx, `INSERT INTO `+m.quotedTable()+` (version, dirty) VALUES (?, ?)`,
+			version, dirty); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) exec(ctx context.Context, conn *sql.Conn, query string) error {
+	if query == "" {
+		return nil
+	}
+
+	_, err := conn.ExecContext(ctx, query)
+
+	return err
+}
+
+func (m *Migrator) quotedTable() string {
+	return "`" + m.table + "`"
+}
+
+// lockName returns the advisory lock name which is the same as golang-migrate uses.
+func (m *Migrator) lockName() string {
+	const advisoryLockIDSalt uint = 1486364155
+
+	config, err := mysql.ParseDSN(m.dsn)
+	if err != nil {
+		return m.table
+	}
+
+	sum := crc32.ChecksumIEEE([]byte(config.DBName + ":" + m.table))
+
+	return strconv.FormatUint(uint64(sum*uint32(advisoryLockIDSalt)), 10)
+}
+
+// nolint:gochecknoglobals
+var migrationFileRegexp = regexp.MustCompile(`^([0-9]+)_(.*)\.(up|down)\.sql$`)
+
+func loadMigrations(source fs.FS) ([]migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*migration, len(entries))
+
+	for _, entry := range entries {
+		match := migrationFileRegexp.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("load migrations: %w", err)
+		}
+
+		body, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("load migrations: %w", err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &migration{
+				version: version,
+				name:    match[2],
+				up:      "",
+				down:    "",
+			}
+			byVersion[version] = mig
+		}
+
+		if match[3] == "up" {
+			mig.up = string(body)
+		} else {
+			mig.down = string(body)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		migrations = append(migrations, *mig)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
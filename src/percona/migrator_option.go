@@ -0,0 +1,36 @@
+package percona
+
+import (
+	"time"
+)
+
+// MigratorOption represents an option for configure Migrator instance.
+type MigratorOption interface {
+	apply(migrator *Migrator)
+}
+
+type migratorOptionFunc func(migrator *Migrator)
+
+func (fn migratorOptionFunc) apply(migrator *Migrator) {
+	fn(migrator)
+}
+
+// DefaultMigrationsTable is the name of table which stores the schema version.
+const DefaultMigrationsTable = "schema_migrations"
+
+// WithMigrationsTable sets up the name of table which stores the schema version.
+func WithMigrationsTable(table string) MigratorOption {
+	return migratorOptionFunc(func(m *Migrator) {
+		m.table = table
+	})
+}
+
+// DefaultMigrationLockTimeout is the maximum time for waiting until migration lock will be acquired.
+const DefaultMigrationLockTimeout = time.Second * 10
+
+// WithMigrationLockTimeout sets up the maximum time for waiting until migration lock will be acquired.
+func WithMigrationLockTimeout(timeout time.Duration) MigratorOption {
+	return migratorOptionFunc(func(m *Migrator) {
+		m.lockTimeout = timeout
+	})
+}
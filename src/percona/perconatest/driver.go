@@ -0,0 +1,216 @@
+package perconatest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// DriverName is the name of the registered scriptable driver.
+const DriverName = "perconatest"
+
+// nolint:gochecknoinits
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
+var _ driver.Driver = (*Driver)(nil)
+
+// Driver opens connections which follow the Script found by DSN.
+type Driver struct{}
+
+// Open returns a new connection to the database.
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	script, ok := scripts.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("perconatest: script for %q is not found", dsn) // nolint:goerr113
+	}
+
+	return &conn{
+		script: script.(*Script), // nolint:forcetypeassert
+	}, nil
+}
+
+var (
+	_ driver.Conn               = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+)
+
+type conn struct {
+	script *Script
+}
+
+// Prepare returns a prepared statement, bound to this connection.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext returns a prepared statement, bound to this connection.
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if e, ok := c.script.nextPrepare(query); ok {
+		if err := e.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		if e.err != nil {
+			return nil, e.err
+		}
+	}
+
+	return &stmt{
+		script: c.script,
+		query:  query,
+	}, nil
+}
+
+// Close invalidates and potentially stops any current prepared statements and transactions.
+func (c *conn) Close() error {
+	return nil
+}
+
+// Begin starts and returns a new transaction.
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{}) // nolint:exhaustivestruct
+}
+
+// BeginTx starts and returns a new transaction.
+func (c *conn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	e, err := c.script.next(expectBegin, "BEGIN", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return &tx{
+		script: c.script,
+	}, nil
+}
+
+// Ping verifies a connection to the database is still alive.
+func (c *conn) Ping(context.Context) error {
+	return nil
+}
+
+var (
+	_ driver.Stmt             = (*stmt)(nil)
+	_ driver.StmtExecContext  = (*stmt)(nil)
+	_ driver.StmtQueryContext = (*stmt)(nil)
+)
+
+type stmt struct {
+	script *Script
+	query  string
+}
+
+// Close closes the statement.
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput returns the number of placeholder parameters. The number is not checked by the driver.
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+// Exec executes a query that doesn't return rows.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// ExecContext executes a query that doesn't return rows.
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	e, err := s.script.next(expectExec, s.query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return e.result, nil
+}
+
+// Query executes a query that may return rows.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// QueryContext executes a query that may return rows.
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	e, err := s.script.next(expectQuery, s.query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	scripted := e.rows
+	if scripted == nil {
+		scripted = NewRows()
+	}
+
+	return &rows{
+		script: scripted,
+		pos:    0,
+	}, nil
+}
+
+var _ driver.Tx = (*tx)(nil)
+
+type tx struct {
+	script *Script
+}
+
+// Commit commits the transaction.
+func (t *tx) Commit() error {
+	e, err := t.script.next(expectCommit, "COMMIT", nil)
+	if err != nil {
+		return err
+	}
+
+	return e.err
+}
+
+// Rollback aborts the transaction.
+func (t *tx) Rollback() error {
+	e, err := t.script.next(expectRollback, "ROLLBACK", nil)
+	if err != nil {
+		return err
+	}
+
+	return e.err
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{
+			Name:    "",
+			Ordinal: i + 1,
+			Value:   arg,
+		}
+	}
+
+	return named
+}
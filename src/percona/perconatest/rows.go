@@ -0,0 +1,90 @@
+package perconatest
+
+import (
+	"database/sql/driver"
+	"io"
+)
+
+// Rows is the scripted query result.
+type Rows struct {
+	columns []string
+	values  [][]driver.Value
+	errs    map[int]error
+}
+
+// NewRows returns a new Rows instance with the given columns.
+func NewRows(columns ...string) *Rows {
+	return &Rows{
+		columns: columns,
+		values:  nil,
+		errs:    make(map[int]error),
+	}
+}
+
+// AddRow adds the row with values in the order of columns.
+func (r *Rows) AddRow(values ...driver.Value) *Rows {
+	r.values = append(r.values, values)
+
+	return r
+}
+
+// RowError sets up the error which is returned instead of the row with the given index.
+func (r *Rows) RowError(row int, err error) *Rows {
+	r.errs[row] = err
+
+	return r
+}
+
+var _ driver.Rows = (*rows)(nil)
+
+// rows is the iterator over the scripted rows.
+type rows struct {
+	script *Rows
+	pos    int
+}
+
+// Columns returns the names of the columns.
+func (r *rows) Columns() []string {
+	return r.script.columns
+}
+
+// Close closes the rows iterator.
+func (r *rows) Close() error {
+	return nil
+}
+
+// Next is called to populate the next row of data into the provided slice.
+func (r *rows) Next(dest []driver.Value) error {
+	if err, ok := r.script.errs[r.pos]; ok {
+		r.pos++
+
+		return err
+	}
+
+	if r.pos >= len(r.script.values) {
+		return io.EOF
+	}
+
+	copy(dest, r.script.values[r.pos])
+	r.pos++
+
+	return nil
+}
+
+var _ driver.Result = result{} // nolint:exhaustivestruct
+
+// result is the scripted result of the executed statement.
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+// LastInsertId returns the database's auto-generated ID after an insert into a table.
+func (res result) LastInsertId() (int64, error) {
+	return res.lastInsertID, nil
+}
+
+// RowsAffected returns the number of rows affected by the query.
+func (res result) RowsAffected() (int64, error) {
+	return res.rowsAffected, nil
+}
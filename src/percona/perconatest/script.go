@@ -0,0 +1,465 @@
+// Package perconatest provides the scriptable database/sql driver, so code
+// which works with Percona could be tested without the database.
+package perconatest
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// nolint:gochecknoglobals
+var (
+	scripts   sync.Map
+	scriptSeq uint64
+)
+
+type expectationKind int
+
+const (
+	expectBegin expectationKind = iota
+	expectPrepare
+	expectExec
+	expectQuery
+	expectCommit
+	expectRollback
+)
+
+func (kind expectationKind) String() string {
+	switch kind {
+	case expectBegin:
+		return "BEGIN"
+	case expectPrepare:
+		return "PREPARE"
+	case expectExec:
+		return "EXEC"
+	case expectQuery:
+		return "QUERY"
+	case expectCommit:
+		return "COMMIT"
+	case expectRollback:
+		return "ROLLBACK"
+	}
+
+	return "UNKNOWN"
+}
+
+// Argument matches the query argument.
+type Argument interface {
+	// Match checks that the argument value is expected.
+	Match(value driver.Value) bool
+}
+
+type anyArgument struct{}
+
+func (anyArgument) Match(driver.Value) bool {
+	return true
+}
+
+// AnyArg returns Argument which matches any value.
+func AnyArg() Argument {
+	return anyArgument{}
+}
+
+// Expectation is the scripted call to the database.
+type Expectation struct {
+	kind    expectationKind
+	pattern *regexp.Regexp
+	args    []any
+
+	result driver.Result
+	rows   *Rows
+	err    error
+	delay  time.Duration
+}
+
+// WithArgs sets up the arguments the statement should be executed with. Values are compared after the
+// conversion to driver values, Argument values are used as matchers.
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	e.args = args
+
+	return e
+}
+
+// WillReturnResult sets up the result of the executed statement.
+func (e *Expectation) WillReturnResult(lastInsertID, rowsAffected int64) *Expectation {
+	e.result = result{
+		lastInsertID: lastInsertID,
+		rowsAffected: rowsAffected,
+	}
+
+	return e
+}
+
+// WillReturnRows sets up the rows returned by the query.
+func (e *Expectation) WillReturnRows(rows *Rows) *Expectation {
+	e.rows = rows
+
+	return e
+}
+
+// WillReturnError sets up the error returned by the call.
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+
+	return e
+}
+
+// WillDelayFor sets up the time the call takes. The call returns the context
+// error when the context is done earlier.
+func (e *Expectation) WillDelayFor(delay time.Duration) *Expectation {
+	e.delay = delay
+
+	return e
+}
+
+func (e *Expectation) String() string {
+	if e.pattern == nil {
+		return e.kind.String()
+	}
+
+	return fmt.Sprintf("%s %q", e.kind, e.pattern)
+}
+
+func (e *Expectation) wait(ctx context.Context) error {
+	if e.delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(e.delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (e *Expectation) matchArgs(args []driver.NamedValue) error {
+	if e.args == nil {
+		return nil
+	}
+
+	if len(args) != len(e.args) {
+		return fmt.Errorf("expected %d arguments, got %d", len(e.args), len(args)) // nolint:goerr113
+	}
+
+	for i, expected := range e.args {
+		if matcher, ok := expected.(Argument); ok {
+			if !matcher.Match(args[i].Value) {
+				return fmt.Errorf("argument %d value %v does not match", i, args[i].Value) // nolint:goerr113
+			}
+
+			continue
+		}
+
+		value, err := driver.DefaultParameterConverter.ConvertValue(expected)
+		if err != nil {
+			return fmt.Errorf("argument %d: %w", i, err)
+		}
+
+		if !reflect.DeepEqual(value, args[i].Value) {
+			return fmt.Errorf("argument %d: expected %v, got %v", i, value, args[i].Value) // nolint:goerr113
+		}
+	}
+
+	return nil
+}
+
+// QueryResponder returns the rows of the query which is executed with the given arguments.
+type QueryResponder func(args []driver.NamedValue) (*Rows, error)
+
+// ExecResponder returns the result of the statement which is executed with the given arguments.
+type ExecResponder func(args []driver.NamedValue) (driver.Result, error)
+
+// responder answers the statements which match the pattern.
+type responder struct {
+	kind    expectationKind
+	pattern *regexp.Regexp
+	query   QueryResponder
+	exec    ExecResponder
+}
+
+// Script is the ordered list of expected calls to the database. Calls
+// should be made in the same order they were scripted, so the order of
+// statements and transaction commits and rollbacks is asserted. Statements
+// are prepared without expectations unless the prepare is the next
+// expected call, because prepared statements could be cached.
+//
+// The calls which are made after all expected calls are answered by the
+// responders, so the code which depends on the state of the database could
+// be run against the fake one.
+type Script struct {
+	t   testing.TB
+	dsn string
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	pos          int
+	responders   []responder
+}
+
+// NewScript returns a new Script instance. Unmet expectations are reported when the test is finished.
+func NewScript(t testing.TB) *Script {
+	t.Helper()
+
+	script := &Script{
+		t:   t,
+		dsn: fmt.Sprintf("perconatest@tcp(script-%d)/perconatest", atomic.AddUint64(&scriptSeq, 1)),
+
+		mu:           sync.Mutex{},
+		expectations: nil,
+		pos:          0,
+		responders:   nil,
+	}
+
+	scripts.Store(script.dsn, script)
+
+	t.Cleanup(func() {
+		scripts.Delete(script.dsn)
+
+		if err := script.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return script
+}
+
+// DSN returns the data source name which should be opened with DriverName.
+func (s *Script) DSN() string {
+	return s.dsn
+}
+
+// ExpectBegin expects the transaction to be started.
+func (s *Script) ExpectBegin() *Expectation {
+	return s.expect(expectBegin, "")
+}
+
+// ExpectPrepare expects the statement which matches the pattern to be prepared.
+func (s *Script) ExpectPrepare(pattern string) *Expectation {
+	return s.expect(expectPrepare, pattern)
+}
+
+// ExpectExec expects the statement which matches the pattern to be executed.
+func (s *Script) ExpectExec(pattern string) *Expectation {
+	return s.expect(expectExec, pattern)
+}
+
+// ExpectQuery expects the query which matches the pattern to be executed.
+func (s *Script) ExpectQuery(pattern string) *Expectation {
+	return s.expect(expectQuery, pattern)
+}
+
+// ExpectCommit expects the transaction to be committed.
+func (s *Script) ExpectCommit() *Expectation {
+	return s.expect(expectCommit, "")
+}
+
+// ExpectRollback expects the transaction to be rolled back.
+func (s *Script) ExpectRollback() *Expectation {
+	return s.expect(expectRollback, "")
+}
+
+// RespondQuery answers the queries which match the pattern. The responders
+// are called one at a time, so they could share the state without locking.
+func (s *Script) RespondQuery(pattern string, fn QueryResponder) {
+	s.respond(responder{
+		kind:    expectQuery,
+		pattern: regexp.MustCompile(pattern),
+		query:   fn,
+		exec:    nil,
+	})
+}
+
+// RespondExec answers the statements which match the pattern. The responders
+// are called one at a time, so they could share the state without locking.
+func (s *Script) RespondExec(pattern string, fn ExecResponder) {
+	s.respond(responder{
+		kind:    expectExec,
+		pattern: regexp.MustCompile(pattern),
+		query:   nil,
+		exec:    fn,
+	})
+}
+
+// ExpectationsWereMet returns error when some scripted calls were not made.
+func (s *Script) ExpectationsWereMet() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pos == len(s.expectations) {
+		return nil
+	}
+
+	remaining := make([]string, 0, len(s.expectations)-s.pos)
+	for _, e := range s.expectations[s.pos:] {
+		remaining = append(remaining, e.String())
+	}
+
+	return fmt.Errorf("perconatest: expected calls were not made: %s", // nolint:goerr113
+		strings.Join(remaining, ", "))
+}
+
+func (s *Script) expect(kind expectationKind, pattern string) *Expectation {
+	e := &Expectation{
+		kind:    kind,
+		pattern: nil,
+		args:    nil,
+
+		result: result{
+			lastInsertID: 0,
+			rowsAffected: 0,
+		},
+		rows:  nil,
+		err:   nil,
+		delay: 0,
+	}
+
+	if pattern != "" {
+		e.pattern = regexp.MustCompile(pattern)
+	}
+
+	s.mu.Lock()
+	s.expectations = append(s.expectations, e)
+	s.mu.Unlock()
+
+	return e
+}
+
+// next returns the next expectation when it matches the call. The call
+// which does not match is reported as a test error.
+func (s *Script) next(kind expectationKind, query string, args []driver.NamedValue) (*Expectation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pos == len(s.expectations) {
+		if e, ok := s.answer(kind, query, args); ok {
+			return e, nil
+		}
+
+		return nil, s.fail("unexpected %s %q: all expected calls were made", kind, query)
+	}
+
+	e := s.expectations[s.pos]
+
+	if e.kind != kind || (e.pattern != nil && !e.pattern.MatchString(query)) {
+		return nil, s.fail("unexpected %s %q: expected %s", kind, query, e)
+	}
+
+	if err := e.matchArgs(args); err != nil {
+		return nil, s.fail("%s %q: %v", kind, query, err)
+	}
+
+	s.pos++
+
+	return e, nil
+}
+
+// nextPrepare returns the prepare expectation when it is the next expected call.
+func (s *Script) nextPrepare(query string) (*Expectation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pos == len(s.expectations) {
+		return nil, false
+	}
+
+	e := s.expectations[s.pos]
+	if e.kind != expectPrepare || (e.pattern != nil && !e.pattern.MatchString(query)) {
+		return nil, false
+	}
+
+	s.pos++
+
+	return e, true
+}
+
+func (s *Script) respond(r responder) {
+	s.mu.Lock()
+	s.responders = append(s.responders, r)
+	s.mu.Unlock()
+}
+
+// answer returns the expectation with the response of the first responder
+// which matches the call. The transactions are always started, committed and
+// rolled back when there are responders. It should be called under the lock.
+func (s *Script) answer(kind expectationKind, query string, args []driver.NamedValue) (*Expectation, bool) {
+	if len(s.responders) == 0 {
+		return nil, false
+	}
+
+	e := &Expectation{
+		kind:    kind,
+		pattern: nil,
+		args:    nil,
+
+		result: result{
+			lastInsertID: 0,
+			rowsAffected: 0,
+		},
+		rows:  nil,
+		err:   nil,
+		delay: 0,
+	}
+
+	switch kind {
+	case expectBegin, expectCommit, expectRollback:
+		return e, true
+	case expectPrepare:
+		return nil, false
+	case expectExec, expectQuery:
+	}
+
+	for _, r := range s.responders {
+		if r.kind != kind || !r.pattern.MatchString(query) {
+			continue
+		}
+
+		if kind == expectQuery {
+			e.rows, e.err = r.query(args)
+		} else {
+			e.result, e.err = r.exec(args)
+		}
+
+		return e, true
+	}
+
+	return nil, false
+}
+
+func (s *Script) fail(format string, args ...any) error {
+	err := fmt.Errorf("perconatest: "+format, args...) // nolint:goerr113
+
+	s.t.Error(err)
+
+	return err
+}
+
+// NewResult returns the result of the executed statement.
+func NewResult(lastInsertID, rowsAffected int64) driver.Result {
+	return result{
+		lastInsertID: lastInsertID,
+		rowsAffected: rowsAffected,
+	}
+}
+
+// MySQLError returns the error with MySQL error number, so the code which
+// checks server errors could be tested.
+func MySQLError(number uint16, message string) error {
+	return &mysql.MySQLError{
+		Number:  number,
+		Message: message,
+	}
+}
@@ -0,0 +1,179 @@
+package percona
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ReplicationLagChecker represents a service that can report the replication lag.
+type ReplicationLagChecker interface {
+	// ReplicationLag returns how far the replica is behind the primary.
+	ReplicationLag(ctx context.Context) (time.Duration, error)
+}
+
+// Replica is the read-only target for the Router.
+type Replica struct {
+	// PrepareTxBeginner is used to execute queries on the replica.
+	PrepareTxBeginner PrepareTxBeginner
+
+	// Checker is used to check the replica health.
+	Checker ReplicationLagChecker
+}
+
+type replica struct {
+	prepareTxBeginner PrepareTxBeginner
+	checker           ReplicationLagChecker
+
+	healthy int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+func (r *replica) setHealthy(healthy bool) {
+	var val int32
+	if healthy {
+		val = 1
+	}
+
+	atomic.StoreInt32(&r.healthy, val)
+}
+
+var _ PrepareTxBeginner = (*Router)(nil)
+
+// Router routes read-only statements to the healthy replicas in round-robin
+// manner, while writes and transactions go to the primary.
+type Router struct {
+	primary  PrepareTxBeginner
+	replicas []*replica
+	next     uint64
+
+	maxReplicationLag   time.Duration
+	checkInterval       time.Duration
+	checkTimeout        time.Duration
+	replicaCheckHandler func(idx int, lag time.Duration, err error)
+}
+
+// NewRouter returns a new Router instance. Replicas are considered healthy
+// until the first check.
+func NewRouter(primary PrepareTxBeginner, replicas []Replica, opts ...RouterOption) *Router {
+	router := &Router{
+		primary:  primary,
+		replicas: make([]*replica, len(replicas)),
+		next:     0,
+
+		maxReplicationLag:   DefaultMaxReplicationLag,
+		checkInterval:       DefaultReplicaCheckInterval,
+		checkTimeout:        DefaultReplicaCheckTimeout,
+		replicaCheckHandler: func(int, time.Duration, error) {},
+	}
+
+	for i, r := range replicas {
+		router.replicas[i] = &replica{
+			prepareTxBeginner: r.PrepareTxBeginner,
+			checker:           r.Checker,
+
+			healthy: 1,
+		}
+	}
+
+	for _, opt := range opts {
+		opt.apply(router)
+	}
+
+	return router
+}
+
+// PrepareContext creates a prepared statement for later queries or executions.
+//
+// Read-only statements are prepared on a healthy replica unless the primary
+// reads are forced by the context.
+func (r *Router) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	if !isReadOnlyQuery(query) {
+		markWrite(ctx)
+
+		return r.primary.PrepareContext(ctx, query)
+	}
+
+	if isPrimaryForced(ctx) {
+		return r.primary.PrepareContext(ctx, query)
+	}
+
+	if target := r.pickReplica(); target != nil {
+		return target.PrepareContext(ctx, query)
+	}
+
+	return r.primary.PrepareContext(ctx, query)
+}
+
+// BeginTx starts a transaction on the primary.
+func (r *Router) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	if opts == nil || !opts.ReadOnly {
+		markWrite(ctx)
+	}
+
+	return r.primary.BeginTx(ctx, opts)
+}
+
+func (r *Router) pickReplica() PrepareTxBeginner {
+	count := uint64(len(r.replicas))
+
+	for i := uint64(0); i < count; i++ {
+		target := r.replicas[atomic.AddUint64(&r.next, 1)%count]
+		if target.isHealthy() {
+			return target.prepareTxBeginner
+		}
+	}
+
+	return nil
+}
+
+// CheckReplicas checks the replication lag of every replica and marks
+// replicas which are lagging or unavailable as unhealthy.
+func (r *Router) CheckReplicas(ctx context.Context) {
+	for i, target := range r.replicas {
+		checkCtx, cancel := context.WithDeadline(ctx, time.Now().Add(r.checkTimeout))
+		lag, err := target.checker.ReplicationLag(checkCtx)
+
+		cancel()
+
+		target.setHealthy(err == nil && lag <= r.maxReplicationLag)
+		r.replicaCheckHandler(i, lag, err)
+	}
+}
+
+// Run checks replicas periodically until the context is done.
+func (r *Router) Run(ctx context.Context) error {
+	if len(r.replicas) == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(r.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		r.CheckReplicas(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func isReadOnlyQuery(query string) bool {
+	query = strings.TrimLeft(query, " \t\r\n(")
+
+	for _, prefix := range []string{"SELECT", "SHOW", "EXPLAIN"} {
+		if len(query) >= len(prefix) && strings.EqualFold(query[:len(prefix)], prefix) {
+			return !strings.Contains(strings.ToUpper(query), "FOR UPDATE")
+		}
+	}
+
+	return false
+}
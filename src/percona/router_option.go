@@ -0,0 +1,53 @@
+package percona
+
+import (
+	"time"
+)
+
+// RouterOption represents an option for configure Router instance.
+type RouterOption interface {
+	apply(router *Router)
+}
+
+type routerOptionFunc func(router *Router)
+
+func (fn routerOptionFunc) apply(router *Router) {
+	fn(router)
+}
+
+// DefaultMaxReplicationLag is the maximum replication lag when replica is still used for reads.
+const DefaultMaxReplicationLag = time.Second
+
+// WithMaxReplicationLag sets up the maximum replication lag when replica is still used for reads.
+func WithMaxReplicationLag(lag time.Duration) RouterOption {
+	return routerOptionFunc(func(r *Router) {
+		r.maxReplicationLag = lag
+	})
+}
+
+// DefaultReplicaCheckInterval is the interval between replica health checks.
+const DefaultReplicaCheckInterval = time.Second * 5
+
+// WithReplicaCheckInterval sets up the interval between replica health checks.
+func WithReplicaCheckInterval(interval time.Duration) RouterOption {
+	return routerOptionFunc(func(r *Router) {
+		r.checkInterval = interval
+	})
+}
+
+// DefaultReplicaCheckTimeout is the maximum time for waiting until replica health check will be finished.
+const DefaultReplicaCheckTimeout = time.Millisecond * 500
+
+// WithReplicaCheckTimeout sets up the maximum time for waiting until replica health check will be finished.
+func WithReplicaCheckTimeout(timeout time.Duration) RouterOption {
+	return routerOptionFunc(func(r *Router) {
+		r.checkTimeout = timeout
+	})
+}
+
+// WithReplicaCheckHandler sets up the function which is called after every replica health check.
+func WithReplicaCheckHandler(fn func(idx int, lag time.Duration, err error)) RouterOption {
+	return routerOptionFunc(func(r *Router) {
+		r.replicaCheckHandler = fn
+	})
+}
@@ -0,0 +1,238 @@
+package percona_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+)
+
+// routingTarget records the statements and transactions which were routed to it.
+type routingTarget struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (target *routingTarget) PrepareContext(context.Context, string) (percona.Stmt, error) {
+	target.mu.Lock()
+	target.calls++
+	target.mu.Unlock()
+
+	return nil, nil // nolint:nilnil
+}
+
+func (target *routingTarget) BeginTx(context.Context, *sql.TxOptions) (percona.Tx, error) {
+	target.mu.Lock()
+	target.calls++
+	target.mu.Unlock()
+
+	return nil, nil // nolint:nilnil
+}
+
+// takeCalls returns the number of calls since the previous one.
+func (target *routingTarget) takeCalls() int {
+	target.mu.Lock()
+	defer target.mu.Unlock()
+
+	calls := target.calls
+	target.calls = 0
+
+	return calls
+}
+
+// lagChecker reports the configured replication lag.
+type lagChecker struct {
+	lag time.Duration
+	err error
+}
+
+func (checker lagChecker) ReplicationLag(context.Context) (time.Duration, error) {
+	return checker.lag, checker.err
+}
+
+func newRouter(
+	checkers ...percona.ReplicationLagChecker,
+) (
+	*percona.Router,
+	*routingTarget,
+	[]*routingTarget,
+) {
+	var (
+		primary  = &routingTarget{mu: sync.Mutex{}, calls: 0}
+		targets  = make([]*routingTarget, len(checkers))
+		replicas = make([]percona.Replica, len(checkers))
+	)
+
+	for i, checker := range checkers {
+		targets[i] = &routingTarget{mu: sync.Mutex{}, calls: 0}
+		replicas[i] = percona.Replica{
+			PrepareTxBeginner: targets[i],
+			Checker:           checker,
+		}
+	}
+
+	return percona.NewRouter(primary, replicas, percona.WithMaxReplicationLag(time.Second)), primary, targets
+}
+
+func TestRouter_PrepareContext(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name      string
+		query     string
+		ctx       func(ctx context.Context) context.Context
+		onReplica bool
+	}{
+		{
+			name:      "Select",
+			query:     "SELECT * FROM user_accounts",
+			ctx:       func(ctx context.Context) context.Context { return ctx },
+			onReplica: true,
+		},
+		{
+			name:      "LowerCaseSubquery",
+			query:     "\n\t(select 1) UNION (select 2)",
+			ctx:       func(ctx context.Context) context.Context { return ctx },
+			onReplica: true,
+		},
+		{
+			name:      "Show",
+			query:     "SHOW TABLES",
+			ctx:       func(ctx context.Context) context.Context { return ctx },
+			onReplica: true,
+		},
+		{
+			name:      "SelectForUpdate",
+			query:     "SELECT * FROM user_accounts WHERE id = ? for update",
+			ctx:       func(ctx context.Context) context.Context { return ctx },
+			onReplica: false,
+		},
+		{
+			name:      "Insert",
+			query:     "INSERT INTO users VALUES (?)",
+			ctx:       func(ctx context.Context) context.Context { return ctx },
+			onReplica: false,
+		},
+		{
+			name:      "CommonTableExpression",
+			query:     "WITH ua AS (SELECT 1) SELECT * FROM ua",
+			ctx:       func(ctx context.Context) context.Context { return ctx },
+			onReplica: false,
+		},
+		{
+			name:      "PrimaryForced",
+			query:     "SELECT * FROM user_accounts",
+			ctx:       percona.WithPrimary,
+			onReplica: false,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			router, primary, replicas := newRouter(lagChecker{lag: 0, err: nil})
+
+			if _, err := router.PrepareContext(tc.ctx(context.Background()), tc.query); err != nil {
+				t.Fatalf("prepare: %v", err)
+			}
+
+			expected := [2]int{0, 1}
+			if tc.onReplica {
+				expected = [2]int{1, 0}
+			}
+
+			if calls := [2]int{replicas[0].takeCalls(), primary.takeCalls()}; calls != expected {
+				t.Errorf("unexpected replica and primary calls: %v, expected %v", calls, expected)
+			}
+		})
+	}
+}
+
+func TestRouter_ReadYourWrites(t *testing.T) {
+	t.Parallel()
+
+	var (
+		router, primary, replicas = newRouter(lagChecker{lag: 0, err: nil})
+		ctx                       = percona.WithReadYourWrites(context.Background())
+	)
+
+	if _, err := router.PrepareContext(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	if replicas[0].takeCalls() != 1 {
+		t.Fatal("read before the write is not routed to the replica")
+	}
+
+	if _, err := router.BeginTx(ctx, nil); err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+
+	if _, err := router.PrepareContext(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	if calls := primary.takeCalls(); calls != 2 || replicas[0].takeCalls() != 0 {
+		t.Errorf("read after the write is not routed to the primary: %d calls", calls)
+	}
+
+	// The other requests are not affected by the write.
+	if _, err := router.PrepareContext(percona.WithReadYourWrites(context.Background()), "SELECT 1"); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	if replicas[0].takeCalls() != 1 {
+		t.Error("read of another request is not routed to the replica")
+	}
+}
+
+func TestRouter_CheckReplicas(t *testing.T) {
+	t.Parallel()
+
+	router, primary, replicas := newRouter(
+		lagChecker{lag: time.Millisecond, err: nil},
+		lagChecker{lag: time.Minute, err: nil},
+		lagChecker{lag: 0, err: percona.ErrReplicationStopped},
+		lagChecker{lag: time.Millisecond * 10, err: nil},
+	)
+
+	router.CheckReplicas(context.Background())
+
+	for i := 0; i < 4; i++ {
+		if _, err := router.PrepareContext(context.Background(), "SELECT 1"); err != nil {
+			t.Fatalf("prepare: %v", err)
+		}
+	}
+
+	// The reads are spread over the healthy replicas only.
+	for i, expected := range []int{2, 0, 0, 2} {
+		if calls := replicas[i].takeCalls(); calls != expected {
+			t.Errorf("replica %d: expected %d calls, got %d", i, expected, calls)
+		}
+	}
+
+	if calls := primary.takeCalls(); calls != 0 {
+		t.Errorf("unexpected primary calls: %d", calls)
+	}
+}
+
+func TestRouter_NoHealthyReplicas(t *testing.T) {
+	t.Parallel()
+
+	router, primary, replicas := newRouter(lagChecker{lag: 0, err: errors.New("connection refused")})
+
+	router.CheckReplicas(context.Background())
+
+	if _, err := router.PrepareContext(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	if primary.takeCalls() != 1 || replicas[0].takeCalls() != 0 {
+		t.Error("read is not routed to the primary")
+	}
+}
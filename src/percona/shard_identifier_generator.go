@@ -0,0 +1,55 @@
+package percona
+
+import (
+	"context"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+)
+
+// maxIdentifierLength is the size of identifier columns.
+const maxIdentifierLength = 64
+
+var _ otelexample.IdentifierGenerator = (*ShardIdentifierGenerator)(nil)
+
+// ShardIdentifierGenerator generates identifiers which start with the shard
+// name, so the record could be found without the shard map lookup and
+// stays on its shard when the shard map is changed.
+type ShardIdentifierGenerator struct {
+	wrapped otelexample.IdentifierGenerator
+	router  *ShardRouter
+}
+
+// NewShardIdentifierGenerator returns a new ShardIdentifierGenerator instance.
+func NewShardIdentifierGenerator(
+	generator otelexample.IdentifierGenerator,
+	router *ShardRouter,
+) *ShardIdentifierGenerator {
+	return &ShardIdentifierGenerator{
+		wrapped: generator,
+		router:  router,
+	}
+}
+
+// GenerateIdentifier returns a new unique identifier. The shard is taken
+// from the context when it is set, otherwise it is located by the generated
+// value.
+func (svc *ShardIdentifierGenerator) GenerateIdentifier(ctx context.Context) otelexample.ID {
+	id := svc.wrapped.GenerateIdentifier(ctx).String()
+
+	shard, ok := shardFromContext(ctx)
+	if !ok {
+		key, ok := shardKeyFromContext(ctx)
+		if !ok {
+			key = id
+		}
+
+		shard = svc.router.Locate(key)
+	}
+
+	prefix := shard + ShardIDSeparator
+	if len(prefix)+len(id) > maxIdentifierLength && len(prefix) < maxIdentifierLength {
+		id = id[:maxIdentifierLength-len(prefix)]
+	}
+
+	return otelexample.ID(prefix + id)
+}
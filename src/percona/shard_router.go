@@ -0,0 +1,162 @@
+package percona
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ShardIDSeparator separates the shard name from the rest of the identifier.
+const ShardIDSeparator = "."
+
+var (
+	// ErrShardNotSpecified is returned when neither the shard nor the shard key is carried by the context.
+	ErrShardNotSpecified = errors.New("shard is not specified")
+
+	// ErrUnknownShard is returned when the shard is missing in the shard map.
+	ErrUnknownShard = errors.New("unknown shard")
+)
+
+// Shard is the target for the ShardRouter.
+type Shard struct {
+	// Name is the unique shard name. It is encoded into identifiers, so it should not be changed.
+	Name string
+
+	// PrepareTxBeginner is used to execute queries on the shard.
+	PrepareTxBeginner PrepareTxBeginner
+}
+
+type ringPoint struct {
+	hash  uint64
+	shard string
+}
+
+var _ PrepareTxBeginner = (*ShardRouter)(nil)
+
+// ShardRouter routes statements and transactions to the shard which is
+// chosen by the shard key from the context with consistent hashing, so only
+// a small part of keys is moved when shards are added or removed.
+type ShardRouter struct {
+	shards map[string]PrepareTxBeginner
+	names  []string
+	ring   []ringPoint
+
+	virtualNodes int
+}
+
+// NewShardRouter returns a new ShardRouter instance.
+func NewShardRouter(shards []Shard, opts ...ShardRouterOption) *ShardRouter {
+	router := &ShardRouter{
+		shards: make(map[string]PrepareTxBeginner, len(shards)),
+		names:  make([]string, 0, len(shards)),
+		ring:   nil,
+
+		virtualNodes: DefaultShardVirtualNodes,
+	}
+
+	for _, opt := range opts {
+		opt.apply(router)
+	}
+
+	router.ring = make([]ringPoint, 0, len(shards)*router.virtualNodes)
+
+	for _, shard := range shards {
+		router.shards[shard.Name] = shard.PrepareTxBeginner
+		router.names = append(router.names, shard.Name)
+
+		for i := 0; i < router.virtualNodes; i++ {
+			router.ring = append(router.ring, ringPoint{
+				hash:  hashShardKey(shard.Name + "#" + strconv.Itoa(i)),
+				shard: shard.Name,
+			})
+		}
+	}
+
+	sort.Slice(router.ring, func(i, j int) bool {
+		return router.ring[i].hash < router.ring[j].hash
+	})
+
+	return router
+}
+
+// Shards returns the names of all shards.
+func (r *ShardRouter) Shards() []string {
+	return append([]string(nil), r.names...)
+}
+
+// Locate returns the shard name for the key. The key which starts with the
+// known shard name is located on that shard, other keys are placed on the
+// ring.
+func (r *ShardRouter) Locate(key string) string {
+	if name, _, ok := strings.Cut(key, ShardIDSeparator); ok {
+		if _, known := r.shards[name]; known {
+			return name
+		}
+	}
+
+	if len(r.ring) == 0 {
+		return ""
+	}
+
+	hash := hashShardKey(key)
+
+	idx := sort.Search(len(r.ring), func(i int) bool {
+		return r.ring[i].hash >= hash
+	})
+	if idx == len(r.ring) {
+		idx = 0
+	}
+
+	return r.ring[idx].shard
+}
+
+// PrepareContext creates a prepared statement for later queries or executions on the shard.
+func (r *ShardRouter) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	target, err := r.target(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return target.PrepareContext(ctx, query)
+}
+
+// BeginTx starts a transaction on the shard.
+func (r *ShardRouter) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	target, err := r.target(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return target.BeginTx(ctx, opts)
+}
+
+func (r *ShardRouter) target(ctx context.Context) (PrepareTxBeginner, error) {
+	name, ok := shardFromContext(ctx)
+	if !ok {
+		key, ok := shardKeyFromContext(ctx)
+		if !ok {
+			return nil, fmt.Errorf("route to shard: %w", ErrShardNotSpecified)
+		}
+
+		name = r.Locate(key)
+	}
+
+	target, ok := r.shards[name]
+	if !ok {
+		return nil, fmt.Errorf("route to shard %q: %w", name, ErrUnknownShard)
+	}
+
+	return target, nil
+}
+
+func hashShardKey(key string) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(key))
+
+	return hash.Sum64()
+}
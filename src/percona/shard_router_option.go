@@ -0,0 +1,23 @@
+package percona
+
+// ShardRouterOption represents an option for configure ShardRouter instance.
+type ShardRouterOption interface {
+	apply(router *ShardRouter)
+}
+
+type shardRouterOptionFunc func(router *ShardRouter)
+
+func (fn shardRouterOptionFunc) apply(router *ShardRouter) {
+	fn(router)
+}
+
+// DefaultShardVirtualNodes is the number of points every shard takes on the hash ring.
+const DefaultShardVirtualNodes = 128
+
+// WithShardVirtualNodes sets up the number of points every shard takes on the hash ring. More points give the
+// more even distribution of keys.
+func WithShardVirtualNodes(nodes int) ShardRouterOption {
+	return shardRouterOptionFunc(func(r *ShardRouter) {
+		r.virtualNodes = nodes
+	})
+}
@@ -0,0 +1,314 @@
+package percona
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// SlowQuery describes the query which execution took longer than the threshold.
+type SlowQuery struct {
+	// Fingerprint is the fingerprint of the query.
+	Fingerprint QueryFingerprint
+
+	// ArgsCount is the number of the query arguments. The argument values are
+	// not kept, because they could contain sensitive data.
+	ArgsCount int
+
+	// RowsAffected is the number of rows affected by the statement, or -1 when it is unknown.
+	RowsAffected int64
+
+	// Elapsed is the query execution time.
+	Elapsed time.Duration
+
+	// Err is the error which the query has failed with, e.g. when its deadline
+	// has been exceeded. It is nil when the query has succeeded.
+	Err error
+
+	// Plan is the query execution plan in JSON format. It is empty when the plan was not captured.
+	Plan string
+
+	// PlanErr is the error which has occurred during the plan capturing.
+	PlanErr error
+}
+
+// SlowQueryHandler is called when the query execution took longer than the threshold.
+type SlowQueryHandler func(ctx context.Context, query SlowQuery)
+
+var _ PrepareTxBeginner = (*SlowQueryLog)(nil)
+
+// SlowQueryLog reports queries which execution took longer than the threshold.
+// It could capture the execution plan of slow SELECT queries. The plan is
+// captured asynchronously, so the reporting of such queries is delayed.
+type SlowQueryLog struct {
+	wrapped PrepareTxBeginner
+
+	threshold time.Duration
+	handlers  []SlowQueryHandler
+
+	explainer       Preparer
+	explainInterval time.Duration
+	explainTimeout  time.Duration
+
+	mu            sync.Mutex
+	lastExplainAt time.Time
+	wg            sync.WaitGroup
+}
+
+// NewSlowQueryLog returns a new SlowQueryLog instance.
+func NewSlowQueryLog(svc PrepareTxBeginner, opts ...SlowQueryLogOption) *SlowQueryLog {
+	log := &SlowQueryLog{
+		wrapped: svc,
+
+		threshold: DefaultSlowQueryThreshold,
+		handlers:  nil,
+
+		explainer:       nil,
+		explainInterval: DefaultSlowQueryExplainInterval,
+		explainTimeout:  DefaultSlowQueryExplainTimeout,
+
+		mu:            sync.Mutex{},
+		lastExplainAt: time.Time{},
+		wg:            sync.WaitGroup{},
+	}
+
+	for _, opt := range opts {
+		opt.apply(log)
+	}
+
+	return log
+}
+
+// PrepareContext creates a prepared statement for later queries or executions.
+func (log *SlowQueryLog) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	perconaStmt, err := log.wrapped.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &slowQueryStmt{
+		wrapped: perconaStmt,
+		log:     log,
+
+		query:       query,
+		fingerprint: FingerprintContext(ctx, query),
+	}, nil
+}
+
+// BeginTx starts a transaction.
+func (log *SlowQueryLog) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	perconaTx, err := log.wrapped.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &slowQueryTx{
+		wrapped: perconaTx,
+		log:     log,
+	}, nil
+}
+
+// Wait waits for the execution plans which are being captured.
+func (log *SlowQueryLog) Wait() {
+	log.wg.Wait()
+}
+
+func (log *SlowQueryLog) observe(
+	ctx context.Context,
+	stmt *slowQueryStmt,
+	args []any,
+	rows int64,
+	elapsed time.Duration,
+	err error,
+) {
+	if elapsed < log.threshold {
+		return
+	}
+
+	query := SlowQuery{
+		Fingerprint:  stmt.fingerprint,
+		ArgsCount:    len(args),
+		RowsAffected: rows,
+		Elapsed:      elapsed,
+		Err:          err,
+		Plan:         "",
+		PlanErr:      nil,
+	}
+
+	if stmt.fingerprint.Operation != "SELECT" || !log.allowExplain() {
+		log.handle(ctx, query)
+
+		return
+	}
+
+	// The caller context could be canceled right after the query, so the plan is
+	// captured within the context which keeps only the values of the caller context.
+	ctx = detachedContext{Context: ctx}
+	args = append(make([]any, 0, len(args)), args...)
+
+	log.wg.Add(1)
+
+	go func() {
+		defer log.wg.Done()
+
+		query.Plan, query.PlanErr = log.explain(ctx, stmt.query, args)
+		log.handle(ctx, query)
+	}()
+}
+
+func (log *SlowQueryLog) handle(ctx context.Context, query SlowQuery) {
+	for _, handler := range log.handlers {
+		handler(ctx, query)
+	}
+}
+
+// allowExplain returns true when the execution plan could be captured according to the rate limit.
+func (log *SlowQueryLog) allowExplain() bool {
+	if log.explainer == nil {
+		return false
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(log.lastExplainAt) < log.explainInterval {
+		return false
+	}
+
+	log.lastExplainAt = now
+
+	return true
+}
+
+func (log *SlowQueryLog) explain(ctx context.Context, query string, args []any) (plan string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, log.explainTimeout)
+	defer cancel()
+
+	stmt, err := log.explainer.PrepareContext(WithQueryName(ctx, "explain"), "EXPLAIN FORMAT=JSON "+query)
+	if err != nil {
+		return "", err
+	}
+
+	defer func(ctx context.Context, stmt Stmt, err *error) {
+		if closeErr := stmt.Close(ctx); closeErr != nil && *err == nil {
+			*err = closeErr
+		}
+	}(ctx, stmt, &err)
+
+	if err = stmt.QueryRowContext(ctx, args...).Scan(&plan); err != nil {
+		return "", err
+	}
+
+	return plan, nil
+}
+
+// detachedContext keeps the values of the parent context, but it is never canceled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+var _ Stmt = (*slowQueryStmt)(nil)
+
+// slowQueryStmt is a prepared statement which reports its slow executions.
+type slowQueryStmt struct {
+	wrapped Stmt
+	log     *SlowQueryLog
+
+	query       string
+	fingerprint QueryFingerprint
+}
+
+// ExecContext executes a prepared statement with the given arguments and
+// returns a Result summarizing the effect of the statement.
+func (stmt *slowQueryStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	start := time.Now()
+
+	result, err := stmt.wrapped.ExecContext(ctx, args...)
+	elapsed := time.Since(start)
+
+	rows := int64(-1)
+
+	if err == nil {
+		if affected, rowsErr := result.RowsAffected(); rowsErr == nil {
+			rows = affected
+		}
+	}
+
+	stmt.log.observe(ctx, stmt, args, rows, elapsed, err)
+
+	return result, err
+}
+
+// QueryRowContext executes a prepared query statement with the given arguments.
+func (stmt *slowQueryStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	start := time.Now()
+
+	row := stmt.wrapped.QueryRowContext(ctx, args...)
+	stmt.log.observe(ctx, stmt, args, -1, time.Since(start), row.Err())
+
+	return row
+}
+
+// QueryContext executes a prepared query statement with the given arguments
+// and returns the query results as a *Rows.
+func (stmt *slowQueryStmt) QueryContext(ctx context.Context, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+
+	rows, err := stmt.wrapped.QueryContext(ctx, args...)
+	stmt.log.observe(ctx, stmt, args, -1, time.Since(start), err)
+
+	return rows, err
+}
+
+// Close closes the statement.
+func (stmt *slowQueryStmt) Close(ctx context.Context) error {
+	return stmt.wrapped.Close(ctx)
+}
+
+var _ Tx = (*slowQueryTx)(nil)
+
+// slowQueryTx is an in-progress database transaction which statements report their slow executions.
+type slowQueryTx struct {
+	wrapped Tx
+	log     *SlowQueryLog
+}
+
+// PrepareContext creates a prepared statement for later queries or executions.
+func (tx *slowQueryTx) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	perconaStmt, err := tx.wrapped.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &slowQueryStmt{
+		wrapped: perconaStmt,
+		log:     tx.log,
+
+		query:       query,
+		fingerprint: FingerprintContext(ctx, query),
+	}, nil
+}
+
+// Commit commits the transaction.
+func (tx *slowQueryTx) Commit(ctx context.Context) error {
+	return tx.wrapped.Commit(ctx)
+}
+
+// Rollback aborts the transaction.
+func (tx *slowQueryTx) Rollback(ctx context.Context) error {
+	return tx.wrapped.Rollback(ctx)
+}
@@ -0,0 +1,62 @@
+package percona
+
+import (
+	"time"
+)
+
+// SlowQueryLogOption represents an option for configure SlowQueryLog instance.
+type SlowQueryLogOption interface {
+	apply(log *SlowQueryLog)
+}
+
+type slowQueryLogOptionFunc func(log *SlowQueryLog)
+
+func (fn slowQueryLogOptionFunc) apply(log *SlowQueryLog) {
+	fn(log)
+}
+
+// DefaultSlowQueryThreshold is the query execution time after which the query is considered slow.
+const DefaultSlowQueryThreshold = time.Millisecond * 200
+
+// WithSlowQueryThreshold sets up the query execution time after which the query is considered slow.
+func WithSlowQueryThreshold(threshold time.Duration) SlowQueryLogOption {
+	return slowQueryLogOptionFunc(func(log *SlowQueryLog) {
+		log.threshold = threshold
+	})
+}
+
+// WithSlowQueryHandler adds the handler which is called when the slow query has been detected.
+func WithSlowQueryHandler(handler SlowQueryHandler) SlowQueryLogOption {
+	return slowQueryLogOptionFunc(func(log *SlowQueryLog) {
+		log.handlers = append(log.handlers, handler)
+	})
+}
+
+// WithSlowQueryExplain enables capturing of the execution plans of slow SELECT
+// queries. The plans are captured by the explainer, which should not be
+// decorated by the SlowQueryLog itself.
+func WithSlowQueryExplain(explainer Preparer) SlowQueryLogOption {
+	return slowQueryLogOptionFunc(func(log *SlowQueryLog) {
+		log.explainer = explainer
+	})
+}
+
+// DefaultSlowQueryExplainInterval is the minimum period of time between the execution plan captures.
+const DefaultSlowQueryExplainInterval = time.Second * 10
+
+// WithSlowQueryExplainInterval sets up the minimum period of time between the execution plan captures.
+func WithSlowQueryExplainInterval(interval time.Duration) SlowQueryLogOption {
+	return slowQueryLogOptionFunc(func(log *SlowQueryLog) {
+		log.explainInterval = interval
+	})
+}
+
+// DefaultSlowQueryExplainTimeout is the maximum period of time for capturing of the execution plan.
+const DefaultSlowQueryExplainTimeout = time.Second * 5
+
+// WithSlowQueryExplainTimeout sets up the maximum period of time for capturing of the execution plan.
+func WithSlowQueryExplainTimeout(timeout time.Duration) SlowQueryLogOption {
+	return slowQueryLogOptionFunc(func(log *SlowQueryLog) {
+		log.explainTimeout = timeout
+	})
+}
@@ -0,0 +1,181 @@
+package percona_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona/perconatest"
+)
+
+// slowQueryRecorder keeps the reported slow queries.
+type slowQueryRecorder struct {
+	mu      sync.Mutex
+	queries []percona.SlowQuery
+}
+
+func (r *slowQueryRecorder) HandleSlowQuery(_ context.Context, query percona.SlowQuery) {
+	r.mu.Lock()
+	r.queries = append(r.queries, query)
+	r.mu.Unlock()
+}
+
+func (r *slowQueryRecorder) reported(t *testing.T) percona.SlowQuery {
+	t.Helper()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.queries) != 1 {
+		t.Fatalf("expected 1 slow query, got %d", len(r.queries))
+	}
+
+	return r.queries[0]
+}
+
+func TestSlowQueryLog_Failed(t *testing.T) {
+	t.Parallel()
+
+	errExec := perconatest.MySQLError(errDuplicateEntry, "Duplicate entry")
+
+	for _, tc := range []struct {
+		name   string
+		script func(script *perconatest.Script)
+		run    func(ctx context.Context, stmt percona.Stmt) error
+		err    error
+	}{
+		{
+			name: "ExecError",
+			script: func(script *perconatest.Script) {
+				script.ExpectExec(`^INSERT INTO users `).WillDelayFor(time.Millisecond * 20).WillReturnError(errExec)
+			},
+			run: func(ctx context.Context, stmt percona.Stmt) error {
+				_, err := stmt.ExecContext(ctx, "tenant")
+
+				return err
+			},
+			err: errExec,
+		},
+		{
+			name: "QueryRowDeadlineExceeded",
+			script: func(script *perconatest.Script) {
+				script.ExpectQuery(`^INSERT INTO users `).WillDelayFor(time.Second)
+			},
+			run: func(ctx context.Context, stmt percona.Stmt) error {
+				var id string
+
+				return stmt.QueryRowContext(ctx, "tenant").Scan(&id)
+			},
+			err: context.DeadlineExceeded,
+		},
+		{
+			name: "QueryDeadlineExceeded",
+			script: func(script *perconatest.Script) {
+				script.ExpectQuery(`^INSERT INTO users `).WillDelayFor(time.Second)
+			},
+			run: func(ctx context.Context, stmt percona.Stmt) error {
+				rows, err := stmt.QueryContext(ctx, "tenant")
+				if err == nil {
+					err = rows.Close()
+				}
+
+				return err
+			},
+			err: context.DeadlineExceeded,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			script := perconatest.NewScript(t)
+			tc.script(script)
+
+			var (
+				recorder = &slowQueryRecorder{mu: sync.Mutex{}, queries: nil}
+				log      = percona.NewSlowQueryLog(connectClient(t, script),
+					percona.WithSlowQueryThreshold(time.Millisecond*10),
+					percona.WithSlowQueryHandler(recorder.HandleSlowQuery))
+			)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+			defer cancel()
+
+			stmt, err := log.PrepareContext(ctx, `INSERT INTO users (tenant_id) VALUES (?)`)
+			if err != nil {
+				t.Fatalf("prepare: %v", err)
+			}
+
+			if err := tc.run(ctx, stmt); !errors.Is(err, tc.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := stmt.Close(ctx); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			query := recorder.reported(t)
+
+			if !errors.Is(query.Err, tc.err) {
+				t.Errorf("unexpected error of slow query: %v", query.Err)
+			}
+
+			if query.Fingerprint.Operation != "INSERT" || query.ArgsCount != 1 || query.RowsAffected != -1 {
+				t.Errorf("unexpected slow query: %+v", query)
+			}
+		})
+	}
+}
+
+func TestSlowQueryLog_ExplainUncached(t *testing.T) {
+	t.Parallel()
+
+	const plan = `{"query_block":{"select_id":1}}`
+
+	script := perconatest.NewScript(t)
+	script.ExpectPrepare(`^SELECT username FROM user_accounts `)
+	script.ExpectQuery(`^SELECT username FROM user_accounts `).WithArgs("tenant").
+		WillDelayFor(time.Millisecond * 20).WillReturnRows(perconatest.NewRows("username").AddRow("alice"))
+	script.ExpectPrepare(`^EXPLAIN FORMAT=JSON SELECT username FROM user_accounts `)
+	script.ExpectQuery(`^EXPLAIN FORMAT=JSON SELECT username FROM user_accounts `).WithArgs("tenant").
+		WillReturnRows(perconatest.NewRows("EXPLAIN").AddRow(plan))
+
+	var (
+		ctx      = context.Background()
+		client   = connectClient(t, script, percona.WithStmtCacheSize(1))
+		recorder = &slowQueryRecorder{mu: sync.Mutex{}, queries: nil}
+		log      = percona.NewSlowQueryLog(client,
+			percona.WithSlowQueryThreshold(time.Millisecond*10),
+			percona.WithSlowQueryHandler(recorder.HandleSlowQuery),
+			percona.WithSlowQueryExplain(client.Uncached()))
+	)
+
+	stmt, err := log.PrepareContext(ctx, `SELECT username FROM user_accounts WHERE tenant_id = ?`)
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	var username string
+	if err := stmt.QueryRowContext(ctx, "tenant").Scan(&username); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	if err := stmt.Close(ctx); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	log.Wait()
+
+	if query := recorder.reported(t); query.Plan != plan || query.PlanErr != nil || query.Err != nil {
+		t.Errorf("unexpected slow query: %+v", query)
+	}
+
+	// The EXPLAIN statement does not evict the application statement from the cache.
+	if stats := client.StmtCacheStats(); stats.Size != 1 || stats.Misses != 1 || stats.Evictions != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
@@ -3,8 +3,21 @@ package percona
 import (
 	"context"
 	"database/sql"
+	"sync"
 )
 
+// Row is the result of the query which selects a single row.
+type Row interface {
+	// Scan copies the columns from the matched row into the values pointed at
+	// by dest. If the query selects no rows, Scan returns sql.ErrNoRows.
+	Scan(dest ...any) error
+
+	// Err returns the error which was encountered while running the query.
+	Err() error
+}
+
+var _ Row = (*sql.Row)(nil)
+
 // Stmt is a prepared statement.
 type Stmt interface {
 	// ExecContext executes a prepared statement with the given arguments and
@@ -12,7 +25,7 @@ type Stmt interface {
 	ExecContext(ctx context.Context, args ...any) (sql.Result, error)
 
 	// QueryRowContext executes a prepared query statement with the given arguments.
-	QueryRowContext(ctx context.Context, args ...any) *sql.Row
+	QueryRowContext(ctx context.Context, args ...any) Row
 
 	// QueryContext executes a prepared query statement with the given arguments
 	// and returns the query results as a *Rows.
@@ -36,7 +49,7 @@ func (stmt *stmt) ExecContext(ctx context.Context, args ...any) (sql.Result, err
 }
 
 // QueryRowContext executes a prepared query statement with the given arguments.
-func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
+func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) Row {
 	return stmt.sqlStmt.QueryRowContext(ctx, args...)
 }
 
@@ -50,3 +63,67 @@ func (stmt *stmt) QueryContext(ctx context.Context, args ...any) (*sql.Rows, err
 func (stmt *stmt) Close(_ context.Context) error {
 	return stmt.sqlStmt.Close()
 }
+
+var _ Stmt = (*cachedStmt)(nil)
+
+// cachedStmt is a prepared statement which is owned by the statement cache.
+type cachedStmt struct {
+	cache *stmtCache
+	entry *stmtCacheEntry
+
+	// sqlStmt is the statement which is used for execution. It is the cached
+	// statement itself or its transaction-specific copy.
+	sqlStmt *sql.Stmt
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// ExecContext executes a prepared statement with the given arguments and
+// returns a Result summarizing the effect of the statement.
+func (stmt *cachedStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	result, err := stmt.sqlStmt.ExecContext(ctx, args...)
+	stmt.checkError(err)
+
+	return result, err
+}
+
+// QueryRowContext executes a prepared query statement with the given arguments.
+func (stmt *cachedStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	row := stmt.sqlStmt.QueryRowContext(ctx, args...)
+	stmt.checkError(row.Err())
+
+	return row
+}
+
+// QueryContext executes a prepared query statement with the given arguments
+// and returns the query results as a *Rows.
+func (stmt *cachedStmt) QueryContext(ctx context.Context, args ...any) (*sql.Rows, error) {
+	rows, err := stmt.sqlStmt.QueryContext(ctx, args...)
+	stmt.checkError(err)
+
+	return rows, err
+}
+
+// Close returns the statement to the cache. The cached statement is closed
+// only when it was evicted and nobody else uses it, so it is safe to call
+// Close multiple times.
+func (stmt *cachedStmt) Close(_ context.Context) error {
+	stmt.closeOnce.Do(func() {
+		if stmt.sqlStmt != stmt.entry.sqlStmt {
+			stmt.closeErr = stmt.sqlStmt.Close()
+		}
+
+		if err := stmt.cache.release(stmt.entry); err != nil && stmt.closeErr == nil {
+			stmt.closeErr = err
+		}
+	})
+
+	return stmt.closeErr
+}
+
+func (stmt *cachedStmt) checkError(err error) {
+	if isConnError(err) {
+		stmt.cache.invalidate(stmt.entry)
+	}
+}
@@ -0,0 +1,254 @@
+package percona
+
+import (
+	"container/list"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// StmtCacheStats contains the prepared statement cache statistics.
+type StmtCacheStats struct {
+	// Size is the number of statements are currently cached.
+	Size int
+
+	// Capacity is the maximum number of statements could be cached.
+	Capacity int
+
+	// Hits is the number of lookups which found a prepared statement.
+	Hits uint64
+
+	// Misses is the number of lookups which have to prepare a new statement.
+	Misses uint64
+
+	// Evictions is the number of statements that were removed from the cache
+	// because of capacity limit or invalidation.
+	Evictions uint64
+}
+
+// stmtCacheEntry is the cached prepared statement.
+type stmtCacheEntry struct {
+	query   string
+	sqlStmt *sql.Stmt
+
+	// refs is the number of callers are currently using the statement.
+	refs int
+
+	// evicted is the flag that the statement is not owned by the cache anymore
+	// and should be closed by the last caller.
+	evicted bool
+}
+
+// stmtCache is the LRU cache of prepared statements keyed by SQL text.
+type stmtCache struct {
+	mu sync.Mutex
+
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func newStmtCache(capacity int) *stmtCache {
+	return &stmtCache{
+		mu: sync.Mutex{},
+
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+
+		hits:      0,
+		misses:    0,
+		evictions: 0,
+	}
+}
+
+// lookup returns the cached statement for the query and increments its reference counter. The miss is counted
+// when the statement is missing.
+func (cache *stmtCache) lookup(query string) (*stmtCacheEntry, bool) {
+	cache.mu.Lock()
+
+	if elem, ok := cache.entries[query]; ok {
+		cache.order.MoveToFront(elem)
+
+		entry := elem.Value.(*stmtCacheEntry) // nolint:forcetypeassert
+		entry.refs++
+
+		cache.mu.Unlock()
+
+		atomic.AddUint64(&cache.hits, 1)
+
+		return entry, true
+	}
+
+	cache.mu.Unlock()
+
+	atomic.AddUint64(&cache.misses, 1)
+
+	return nil, false
+}
+
+// acquire returns the cached statement for the query and increments its reference counter. The prepare function
+// is called when the statement is missing.
+func (cache *stmtCache) acquire(query string, prepare func() (*sql.Stmt, error)) (*stmtCacheEntry, error) {
+	if entry, ok := cache.lookup(query); ok {
+		return entry, nil
+	}
+
+	sqlStmt, err := prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	return cache.insert(query, sqlStmt), nil
+}
+
+// contains checks that the statement for the query is cached. It does not
+// count the hit or the miss.
+func (cache *stmtCache) contains(query string) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	_, ok := cache.entries[query]
+
+	return ok
+}
+
+// insert caches the prepared statement and returns it with the incremented reference counter.
+func (cache *stmtCache) insert(query string, sqlStmt *sql.Stmt) *stmtCacheEntry {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	// The statement could be prepared concurrently by another caller, so the
+	// one which was cached first wins.
+	if elem, ok := cache.entries[query]; ok {
+		_ = sqlStmt.Close()
+
+		cache.order.MoveToFront(elem)
+
+		entry := elem.Value.(*stmtCacheEntry) // nolint:forcetypeassert
+		entry.refs++
+
+		return entry
+	}
+
+	entry := &stmtCacheEntry{
+		query:   query,
+		sqlStmt: sqlStmt,
+
+		refs:    1,
+		evicted: false,
+	}
+
+	cache.entries[query] = cache.order.PushFront(entry)
+
+	for cache.order.Len() > cache.capacity {
+		cache.evict(cache.order.Back())
+	}
+
+	return entry
+}
+
+// release decrements the statement reference counter and closes it when the
+// statement has been already evicted and nobody uses it.
+func (cache *stmtCache) release(entry *stmtCacheEntry) error {
+	cache.mu.Lock()
+
+	entry.refs--
+
+	closeStmt := entry.evicted && entry.refs == 0
+
+	cache.mu.Unlock()
+
+	if !closeStmt {
+		return nil
+	}
+
+	return entry.sqlStmt.Close()
+}
+
+// invalidate removes the statement from the cache, so the next caller will prepare a new one.
+func (cache *stmtCache) invalidate(entry *stmtCacheEntry) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if entry.evicted {
+		return
+	}
+
+	if elem, ok := cache.entries[entry.query]; ok {
+		cache.evict(elem)
+	}
+}
+
+// purge removes all statements from the cache.
+func (cache *stmtCache) purge() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for elem := cache.order.Back(); elem != nil; elem = cache.order.Back() {
+		cache.evict(elem)
+	}
+}
+
+// evict removes element from the cache. It should be called under the lock.
+func (cache *stmtCache) evict(elem *list.Element) {
+	entry := cache.order.Remove(elem).(*stmtCacheEntry) // nolint:forcetypeassert
+	delete(cache.entries, entry.query)
+
+	entry.evicted = true
+
+	atomic.AddUint64(&cache.evictions, 1)
+
+	if entry.refs == 0 {
+		_ = entry.sqlStmt.Close()
+	}
+}
+
+func (cache *stmtCache) stats() StmtCacheStats {
+	cache.mu.Lock()
+	size := cache.order.Len()
+	cache.mu.Unlock()
+
+	return StmtCacheStats{
+		Size:     size,
+		Capacity: cache.capacity,
+
+		Hits:      atomic.LoadUint64(&cache.hits),
+		Misses:    atomic.LoadUint64(&cache.misses),
+		Evictions: atomic.LoadUint64(&cache.evictions),
+	}
+}
+
+// isConnError checks that error is caused by the broken connection or by the
+// statement which is unknown for the server, so the cached statement is not
+// valid anymore.
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	// ER_UNKNOWN_STMT_HANDLER is returned when the statement was deallocated on the server side.
+	const errUnknownStmtHandler = 1243
+
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == errUnknownStmtHandler
+}
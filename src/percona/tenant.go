@@ -0,0 +1,33 @@
+package percona
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+)
+
+// tenantFromContext returns the tenant which scopes all queries. The request without tenant is rejected,
+// so the data of one tenant could not be read or changed by another one.
+func tenantFromContext(ctx context.Context) (otelexample.TenantID, error) {
+	if id, ok := otelexample.TenantIDFromContext(ctx); ok {
+		return id, nil
+	}
+
+	return otelexample.EmptyTenantID, &otelexample.Error{
+		Code:    otelexample.ErrorCodeInvalid,
+		Message: "tenant is not specified",
+		Err:     nil,
+	}
+}
+
+// isDuplicateEntry checks that error is caused by the unique constraint violation.
+func isDuplicateEntry(err error) bool {
+	// ER_DUP_ENTRY is returned when the row with the same unique key already exists.
+	const errDupEntry = 1062
+
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == errDupEntry
+}
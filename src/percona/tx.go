@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 )
 
 // Tx is an in-progress database transaction.
@@ -12,10 +13,10 @@ type Tx interface {
 	PrepareContext(ctx context.Context, query string) (Stmt, error)
 
 	// Commit commits the transaction.
-	Commit() error
+	Commit(ctx context.Context) error
 
 	// Rollback aborts the transaction.
-	Rollback() error
+	Rollback(ctx context.Context) error
 }
 
 var _ Tx = (*tx)(nil)
@@ -23,10 +24,48 @@ var _ Tx = (*tx)(nil)
 // tx is an in-progress database transaction.
 type tx struct {
 	sqlTx *sql.Tx
+
+	db        *sql.DB
+	stmtCache *stmtCache
+
+	// uncached are the queries which were missing in the cache, they are
+	// cached after the transaction is committed.
+	uncached []string
 }
 
 // PrepareContext creates a prepared statement for use within a transaction.
+//
+// When the statement cache is enabled the cached statement is re-bound to the
+// transaction. The missing statement is prepared on the transaction connection
+// and it is cached only after the commit, because the preparation on the pool
+// would wait for another connection while the transaction holds one.
 func (tx *tx) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	if tx.stmtCache == nil {
+		return tx.prepare(ctx, query)
+	}
+
+	entry, ok := tx.stmtCache.lookup(query)
+	if !ok {
+		tx.uncached = append(tx.uncached, query)
+
+		return tx.prepare(ctx, query)
+	}
+
+	return &cachedStmt{
+		cache: tx.stmtCache,
+		entry: entry,
+
+		sqlStmt: tx.sqlTx.StmtContext(ctx, entry.sqlStmt),
+
+		closeOnce: sync.Once{},
+		closeErr:  nil,
+	}, nil
+}
+
+// prepare creates a prepared statement on the transaction connection, it is closed together with the transaction.
+func (tx *tx) prepare(ctx context.Context, query string) (Stmt, error) {
+	markRoundTrip(ctx)
+
 	sqlStmt, err := tx.sqlTx.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("prepare: %w", err)
@@ -37,12 +76,42 @@ func (tx *tx) PrepareContext(ctx context.Context, query string) (Stmt, error) {
 	}, nil
 }
 
-// Commit commits the transaction.
-func (tx *tx) Commit() error {
-	return tx.sqlTx.Commit()
+// Commit commits the transaction. The database/sql transaction is bound to the
+// context which began it, so the context is used only for caching of the
+// statements which were missing in the cache.
+func (tx *tx) Commit(ctx context.Context) error {
+	if err := tx.sqlTx.Commit(); err != nil {
+		return err // nolint:wrapcheck
+	}
+
+	tx.cacheStmts(ctx)
+
+	return nil
+}
+
+// cacheStmts prepares on the pool and caches the statements which were
+// missing in the cache. The transaction connection is already released, so
+// the preparation does not wait for it. The statements are cached in the best
+// effort, so the errors are ignored and the next transaction prepares them on
+// its own connection.
+func (tx *tx) cacheStmts(ctx context.Context) {
+	for _, query := range tx.uncached {
+		if tx.stmtCache.contains(query) {
+			continue
+		}
+
+		sqlStmt, err := tx.db.PrepareContext(ctx, query)
+		if err != nil {
+			return
+		}
+
+		_ = tx.stmtCache.release(tx.stmtCache.insert(query, sqlStmt))
+	}
+
+	tx.uncached = nil
 }
 
 // Rollback aborts the transaction.
-func (tx *tx) Rollback() error {
+func (tx *tx) Rollback(_ context.Context) error {
 	return tx.sqlTx.Rollback()
 }
@@ -17,6 +17,13 @@ type UserAccountService struct {
 
 	identifierGenerator otelexample.IdentifierGenerator
 	timer               otelexample.Timer
+
+	shards []string
+
+	createTimeout           time.Duration
+	listTimeout             time.Duration
+	getTimeout              time.Duration
+	deadlineExceededHandler DeadlineExceededHandler
 }
 
 // NewUserAccountService returns a new instance of UserAccountService.
@@ -24,68 +31,115 @@ func NewUserAccountService(
 	prepareTxBeginner PrepareTxBeginner,
 	identifierGenerator otelexample.IdentifierGenerator,
 	timer otelexample.Timer,
+	opts ...UserAccountServiceOption,
 ) *UserAccountService {
-	return &UserAccountService{
+	svc := &UserAccountService{
 		prepareTxBeginner: prepareTxBeginner,
 
 		identifierGenerator: identifierGenerator,
 		timer:               timer,
+
+		shards: nil,
+
+		createTimeout:           DefaultCreateTimeout,
+		listTimeout:             DefaultListTimeout,
+		getTimeout:              DefaultGetTimeout,
+		deadlineExceededHandler: nopDeadlineExceededHandler,
 	}
+
+	for _, opt := range opts {
+		opt.apply(svc)
+	}
+
+	return svc
 }
 
 // CreateUserAccount creates a new user account.
 func (svc *UserAccountService) CreateUserAccount(ctx context.Context, ua *otelexample.UserAccount) error {
-	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second))
+	ctx, cancel := withOperationTimeout(ctx, svc.createTimeout)
 	defer cancel()
 
+	defer checkDeadline(ctx, OperationCreate, svc.deadlineExceededHandler)
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("create user account: %w", err)
+	}
+
+	// The identifier is generated before the transaction is started, because
+	// it chooses the shard for the user account.
+	uaID := svc.identifierGenerator.GenerateIdentifier(ctx)
+	ctx = WithShardKey(ctx, uaID.String())
+
+	if err = svc.checkUsernameAcrossShards(ctx, tenantID, ua.Username); err != nil {
+		return fmt.Errorf("create user account: %w", err)
+	}
+
 	tx, err := svc.prepareTxBeginner.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("create user account: %w", err)
 	}
 
-	if err = svc.createUserAccount(ctx, tx, ua); err == nil {
+	if err = svc.createUserAccount(ctx, tx, tenantID, uaID, ua); err == nil {
 		return nil
 	}
 
-	if rollbackErr := tx.Rollback(); rollbackErr != nil {
+	if isDuplicateEntry(err) {
+		err = usernameConflictError(ua.Username, err)
+	}
+
+	// The transaction is done when the commit fails, so the commit error is not replaced by the rollback one.
+	if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
 		return fmt.Errorf("create user account: %w", rollbackErr)
 	}
 
 	return fmt.Errorf("create user account: %w", err)
 }
 
-func (svc *UserAccountService) createUserAccount(ctx context.Context, tx Tx, ua *otelexample.UserAccount) error {
-	exist, err := svc.checkUserAccountExistent(ctx, tx, ua.Username)
+func (svc *UserAccountService) createUserAccount(
+	ctx context.Context,
+	tx Tx,
+	tenantID otelexample.TenantID,
+	uaID otelexample.ID,
+	ua *otelexample.UserAccount,
+) error {
+	exist, err := svc.checkUserAccountExistent(ctx, tx, tenantID, ua.Username)
 	if err != nil {
 		return err
 	}
 
 	if exist {
-		return &otelexample.Error{
-			Code:    otelexample.ErrorCodeConflict,
-			Message: fmt.Sprintf(`user account with username "%s" already exist`, ua.Username),
-			Err:     nil,
-		}
+		return usernameConflictError(ua.Username, nil)
 	}
 
-	if err := svc.createUserRow(ctx, tx, ua.User); err != nil {
+	if err := svc.createUserRow(ctx, tx, tenantID, ua.User); err != nil {
 		return err
 	}
 
-	if err := svc.createUserAccountRow(ctx, tx, ua); err != nil {
+	if err := svc.createUserAccountRow(ctx, tx, tenantID, uaID, ua); err != nil {
 		return err
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (svc *UserAccountService) checkUserAccountExistent(ctx context.Context, tx Tx, username string) (bool, error) {
-	stmt, err := tx.PrepareContext(ctx, `SELECT EXISTS(SELECT 1 FROM user_accounts ua WHERE ua.username = ?) `+
-		`AS is_exists`)
+func (svc *UserAccountService) checkUserAccountExistent(
+	ctx context.Context,
+	preparer Preparer,
+	tenantID otelexample.TenantID,
+	username string,
+) (
+	bool,
+	error,
+) {
+	ctx = WithQueryName(ctx, "check_user_account_exists")
+
+	stmt, err := preparer.PrepareContext(ctx, `SELECT EXISTS(SELECT 1 FROM user_accounts ua WHERE ua.tenant_id = ? `+
+		`AND ua.username = ?) AS is_exists`)
 	if err != nil {
 		return false, err
 	}
@@ -97,21 +151,28 @@ func (svc *UserAccountService) checkUserAccountExistent(ctx context.Context, tx
 	}(ctx, stmt, &err)
 
 	var exists bool
-	if err := stmt.QueryRowContext(ctx, username).Scan(&exists); err != nil {
+	if err := stmt.QueryRowContext(ctx, tenantID.String(), username).Scan(&exists); err != nil {
 		return false, err
 	}
 
 	return exists, nil
 }
 
-func (svc *UserAccountService) createUserRow(ctx context.Context, tx Tx, user *otelexample.User) error {
+func (svc *UserAccountService) createUserRow(
+	ctx context.Context,
+	tx Tx,
+	tenantID otelexample.TenantID,
+	user *otelexample.User,
+) error {
 	var (
 		createdAt = svc.timer.Time(ctx)
 		userID    = svc.identifierGenerator.GenerateIdentifier(ctx)
 	)
 
-	stmt, err := tx.PrepareContext(ctx, `INSERT INTO users (user_id, first_name, last_name, created_at) VALUES `+
-		`(?,?,?,?)`)
+	ctx = WithQueryName(ctx, "create_user")
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO users (tenant_id, user_id, first_name, last_name, created_at) `+
+		`VALUES (?,?,?,?,?)`)
 	if err != nil {
 		return err
 	}
@@ -122,7 +183,8 @@ func (svc *UserAccountService) createUserRow(ctx context.Context, tx Tx, user *o
 		}
 	}(ctx, stmt, &err)
 
-	_, err = stmt.ExecContext(ctx, userID.String(), user.FirstName, user.LastName, createdAt.UnixMilli())
+	_, err = stmt.ExecContext(ctx, tenantID.String(), userID.String(), user.FirstName, user.LastName,
+		createdAt.UnixMilli())
 	if err != nil {
 		return err
 	}
@@ -132,14 +194,19 @@ func (svc *UserAccountService) createUserRow(ctx context.Context, tx Tx, user *o
 	return nil
 }
 
-func (svc *UserAccountService) createUserAccountRow(ctx context.Context, tx Tx, ua *otelexample.UserAccount) error {
-	var (
-		createdAt = svc.timer.Time(ctx)
-		uaID      = svc.identifierGenerator.GenerateIdentifier(ctx)
-	)
+func (svc *UserAccountService) createUserAccountRow(
+	ctx context.Context,
+	tx Tx,
+	tenantID otelexample.TenantID,
+	uaID otelexample.ID,
+	ua *otelexample.UserAccount,
+) error {
+	createdAt := svc.timer.Time(ctx)
+
+	ctx = WithQueryName(ctx, "create_user_account")
 
-	stmt, err := tx.PrepareContext(ctx, `INSERT INTO user_accounts (user_account_id,username,user_id,`+
-		`created_at) VALUES (?,?,?,?)`)
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO user_accounts (tenant_id,user_account_id,username,`+
+		`user_id,created_at) VALUES (?,?,?,?,?)`)
 	if err != nil {
 		return err
 	}
@@ -150,7 +217,8 @@ func (svc *UserAccountService) createUserAccountRow(ctx context.Context, tx Tx,
 		}
 	}(ctx, stmt, &err)
 
-	_, err = stmt.ExecContext(ctx, uaID.String(), ua.Username, ua.User.ID.String(), createdAt.UnixMilli())
+	_, err = stmt.ExecContext(ctx, tenantID.String(), uaID.String(), ua.Username, ua.User.ID.String(),
+		createdAt.UnixMilli())
 	if err != nil {
 		return err
 	}
@@ -160,7 +228,12 @@ func (svc *UserAccountService) createUserAccountRow(ctx context.Context, tx Tx,
 	return nil
 }
 
-// FindUserAccounts returns a list of user accounts.
+// userAccountsOrder is the order of the listed user accounts. The row identifier is assigned by each shard, so
+// the accounts are ordered by the values which could be compared across shards.
+const userAccountsOrder = `ua.created_at, ua.user_account_id`
+
+// FindUserAccounts returns a list of user accounts ordered by the creation time
+// and then by the identifier, so the pages are the same with and without shards.
 func (svc *UserAccountService) FindUserAccounts(
 	ctx context.Context,
 	opts otelexample.FindOptions,
@@ -168,9 +241,11 @@ func (svc *UserAccountService) FindUserAccounts(
 	*otelexample.FindUserAccountsResult,
 	error,
 ) {
-	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second))
+	ctx, cancel := withOperationTimeout(ctx, svc.listTimeout)
 	defer cancel()
 
+	defer checkDeadline(ctx, OperationList, svc.deadlineExceededHandler)
+
 	var (
 		result = new(otelexample.FindUserAccountsResult)
 
@@ -179,11 +254,24 @@ func (svc *UserAccountService) FindUserAccounts(
 
 	result.Options = opts
 
-	if result.Total, err = svc.findUserAccountsCountTotal(ctx); err != nil {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("find user accounts: %w", err)
+	}
+
+	if len(svc.shards) > 0 {
+		if err = svc.findUserAccountsAcrossShards(ctx, tenantID, result); err != nil {
+			return nil, fmt.Errorf("find user accounts: %w", err)
+		}
+
+		return result, nil
+	}
+
+	if result.Total, err = svc.findUserAccountsCountTotal(ctx, tenantID); err != nil {
 		return nil, fmt.Errorf("find user accounts: %w", err)
 	}
 
-	if result.Data, err = svc.findUserAccounts(ctx, opts); err != nil {
+	if result.Data, err = svc.findUserAccounts(ctx, tenantID, opts); err != nil {
 		return nil, fmt.Errorf("find user accounts: %w", err)
 	}
 
@@ -191,15 +279,23 @@ func (svc *UserAccountService) FindUserAccounts(
 		return result, nil
 	}
 
-	if result.HasNext, err = svc.findUserAccountsHasNext(ctx, opts.Offset()+opts.Limit()); err != nil {
+	if result.HasNext, err = svc.findUserAccountsHasNext(ctx, tenantID, opts.Offset()+opts.Limit()); err != nil {
 		return nil, fmt.Errorf("find user accounts: %w", err)
 	}
 
 	return result, nil
 }
 
-func (svc *UserAccountService) findUserAccountsCountTotal(ctx context.Context) (uint64, error) {
-	stmt, err := svc.prepareTxBeginner.PrepareContext(ctx, `SELECT count(1) FROM user_accounts`)
+func (svc *UserAccountService) findUserAccountsCountTotal(
+	ctx context.Context,
+	tenantID otelexample.TenantID,
+) (
+	uint64,
+	error,
+) {
+	ctx = WithQueryName(ctx, "count_user_accounts")
+
+	stmt, err := svc.prepareTxBeginner.PrepareContext(ctx, `SELECT count(1) FROM user_accounts WHERE tenant_id = ?`)
 	if err != nil {
 		return 0, err
 	}
@@ -212,7 +308,7 @@ func (svc *UserAccountService) findUserAccountsCountTotal(ctx context.Context) (
 
 	var total uint64
 
-	if err := stmt.QueryRowContext(ctx).Scan(&total); err != nil {
+	if err := stmt.QueryRowContext(ctx, tenantID.String()).Scan(&total); err != nil {
 		return 0, err
 	}
 
@@ -221,15 +317,19 @@ func (svc *UserAccountService) findUserAccountsCountTotal(ctx context.Context) (
 
 func (svc *UserAccountService) findUserAccounts(
 	ctx context.Context,
+	tenantID otelexample.TenantID,
 	opts otelexample.FindOptions,
 ) (
 	[]*otelexample.UserAccount,
 	error,
 ) {
+	ctx = WithQueryName(ctx, "find_user_accounts")
+
 	stmt, err := svc.prepareTxBeginner.PrepareContext(ctx, `SELECT * FROM (SELECT ROW_NUMBER() OVER `+
-		`(ORDER BY ua.row_id) as row_num, ua.user_account_id, ua.username, ua.created_at AS ua_created_at, `+
-		`u.user_id, u.first_name, u.last_name, u.created_at AS u_created_at FROM user_accounts ua JOIN users u ON `+
-		`ua.user_id = u.user_id) AS subquery WHERE row_num > ? LIMIT ?`)
+		`(ORDER BY `+userAccountsOrder+`) as row_num, ua.user_account_id, ua.username, `+
+		`ua.created_at AS ua_created_at, u.user_id, u.first_name, u.last_name, u.created_at AS u_created_at `+
+		`FROM user_accounts ua JOIN users u ON ua.tenant_id = u.tenant_id AND ua.user_id = u.user_id `+
+		`WHERE ua.tenant_id = ?) AS subquery WHERE row_num > ? LIMIT ?`)
 	if err != nil {
 		return nil, err
 	}
@@ -240,7 +340,7 @@ func (svc *UserAccountService) findUserAccounts(
 		}
 	}(ctx, stmt, &err)
 
-	rows, err := stmt.QueryContext(ctx, opts.Offset(), opts.Limit())
+	rows, err := stmt.QueryContext(ctx, tenantID.String(), opts.Offset(), opts.Limit())
 	if err != nil {
 		return nil, err
 	}
@@ -281,10 +381,19 @@ func (svc *UserAccountService) findUserAccounts(
 	return uaa, nil
 }
 
-func (svc *UserAccountService) findUserAccountsHasNext(ctx context.Context, offset uint64) (bool, error) {
+func (svc *UserAccountService) findUserAccountsHasNext(
+	ctx context.Context,
+	tenantID otelexample.TenantID,
+	offset uint64,
+) (
+	bool,
+	error,
+) {
+	ctx = WithQueryName(ctx, "find_user_accounts_has_next")
+
 	stmt, err := svc.prepareTxBeginner.PrepareContext(ctx, `SELECT EXISTS(SELECT 1 FROM (SELECT ROW_NUMBER() `+
-		`OVER (ORDER BY ua.row_id) as row_num FROM user_accounts ua) AS subquery WHERE row_num > ? LIMIT 1) `+
-		`AS has_next`)
+		`OVER (ORDER BY `+userAccountsOrder+`) as row_num FROM user_accounts ua WHERE ua.tenant_id = ?) AS subquery `+
+		`WHERE row_num > ? LIMIT 1) AS has_next`)
 	if err != nil {
 		return false, err
 	}
@@ -296,7 +405,7 @@ func (svc *UserAccountService) findUserAccountsHasNext(ctx context.Context, offs
 	}(ctx, stmt, &err)
 
 	var hasNext bool
-	if err = stmt.QueryRowContext(ctx, offset).Scan(&hasNext); err != nil {
+	if err = stmt.QueryRowContext(ctx, tenantID.String(), offset).Scan(&hasNext); err != nil {
 		return false, err
 	}
 
@@ -311,12 +420,24 @@ func (svc *UserAccountService) FindUserAccountByID(
 	*otelexample.UserAccount,
 	error,
 ) {
-	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second))
+	ctx, cancel := withOperationTimeout(ctx, svc.getTimeout)
 	defer cancel()
 
+	defer checkDeadline(ctx, OperationGet, svc.deadlineExceededHandler)
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("find user account by id: %w", err)
+	}
+
+	ctx = WithShardKey(ctx, id.String())
+
+	ctx = WithQueryName(ctx, "find_user_account_by_id")
+
 	stmt, err := svc.prepareTxBeginner.PrepareContext(ctx, `SELECT ua.username, ua.created_at AS ua_created_at, `+
 		`u.user_id, u.first_name, u.last_name, u.created_at AS u_created_at FROM user_accounts as ua JOIN users as u `+
-		`ON ua.user_id = u.user_id WHERE ua.user_account_id = ?`)
+		`ON ua.tenant_id = u.tenant_id AND ua.user_id = u.user_id WHERE ua.tenant_id = ? AND `+
+		`ua.user_account_id = ?`)
 	if err != nil {
 		return nil, fmt.Errorf("find user account by id: %w", err)
 	}
@@ -336,8 +457,8 @@ func (svc *UserAccountService) FindUserAccountByID(
 
 	ua.ID, ua.User = id, new(otelexample.User)
 
-	err = stmt.QueryRowContext(ctx, id.String()).Scan(&ua.Username, &userAccountCreatedAt, &ua.User.ID,
-		&ua.User.FirstName, &ua.User.LastName, &userCreatedAt)
+	err = stmt.QueryRowContext(ctx, tenantID.String(), id.String()).Scan(&ua.Username, &userAccountCreatedAt,
+		&ua.User.ID, &ua.User.FirstName, &ua.User.LastName, &userCreatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("find user account by id: %w", &otelexample.Error{
 			Code:    otelexample.ErrorCodeNotFound,
@@ -354,3 +475,11 @@ func (svc *UserAccountService) FindUserAccountByID(
 
 	return ua, nil
 }
+
+func usernameConflictError(username string, err error) error {
+	return &otelexample.Error{
+		Code:    otelexample.ErrorCodeConflict,
+		Message: fmt.Sprintf(`user account with username "%s" already exist`, username),
+		Err:     err,
+	}
+}
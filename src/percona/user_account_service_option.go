@@ -0,0 +1,62 @@
+package percona
+
+import (
+	"time"
+)
+
+// UserAccountServiceOption represents an option for configure UserAccountService instance.
+type UserAccountServiceOption interface {
+	apply(svc *UserAccountService)
+}
+
+type userAccountServiceOptionFunc func(svc *UserAccountService)
+
+func (fn userAccountServiceOptionFunc) apply(svc *UserAccountService) {
+	fn(svc)
+}
+
+// DefaultCreateTimeout is the maximum time for waiting until create operation will be finished.
+const DefaultCreateTimeout = time.Second
+
+// WithCreateTimeout sets up the maximum time for waiting until create operation will be finished.
+func WithCreateTimeout(timeout time.Duration) UserAccountServiceOption {
+	return userAccountServiceOptionFunc(func(svc *UserAccountService) {
+		svc.createTimeout = timeout
+	})
+}
+
+// DefaultListTimeout is the maximum time for waiting until list operation will be finished.
+const DefaultListTimeout = time.Second
+
+// WithListTimeout sets up the maximum time for waiting until list operation will be finished.
+func WithListTimeout(timeout time.Duration) UserAccountServiceOption {
+	return userAccountServiceOptionFunc(func(svc *UserAccountService) {
+		svc.listTimeout = timeout
+	})
+}
+
+// DefaultGetTimeout is the maximum time for waiting until get operation will be finished.
+const DefaultGetTimeout = time.Second
+
+// WithGetTimeout sets up the maximum time for waiting until get operation will be finished.
+func WithGetTimeout(timeout time.Duration) UserAccountServiceOption {
+	return userAccountServiceOptionFunc(func(svc *UserAccountService) {
+		svc.getTimeout = timeout
+	})
+}
+
+// WithOperationDeadlineExceededHandler sets up the function which is called when create, list or get operation has
+// hit its deadline.
+func WithOperationDeadlineExceededHandler(handler DeadlineExceededHandler) UserAccountServiceOption {
+	return userAccountServiceOptionFunc(func(svc *UserAccountService) {
+		svc.deadlineExceededHandler = handler
+	})
+}
+
+// WithShards sets up the names of shards the user accounts are spread across. It should be used together with
+// ShardRouter, so the lists are gathered from all shards.
+func WithShards(shards []string) UserAccountServiceOption {
+	return userAccountServiceOptionFunc(func(svc *UserAccountService) {
+		svc.shards = shards
+	})
+}
@@ -0,0 +1,209 @@
+package percona
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"golang.org/x/sync/errgroup"
+)
+
+// checkUsernameAcrossShards checks that the username is not taken on any
+// shard. The check is made on the primary, because the lagging replica could
+// miss the account which has just been created. The unique key covers a single
+// shard only, so two accounts with the same username could be still created
+// concurrently on different shards.
+func (svc *UserAccountService) checkUsernameAcrossShards(
+	ctx context.Context,
+	tenantID otelexample.TenantID,
+	username string,
+) error {
+	if len(svc.shards) == 0 {
+		return nil
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	for _, shard := range svc.shards {
+		shard := shard
+
+		group.Go(func() error {
+			exist, err := svc.checkUserAccountExistent(WithPrimary(WithShard(groupCtx, shard)), svc.prepareTxBeginner,
+				tenantID, username)
+			if err != nil {
+				return fmt.Errorf("shard %s: %w", shard, err)
+			}
+
+			if exist {
+				return usernameConflictError(username, nil)
+			}
+
+			return nil
+		})
+	}
+
+	return group.Wait() // nolint:wrapcheck
+}
+
+// MaxShardedWindow is the maximum offset+limit of the page which is gathered from the shards. Every shard
+// returns the whole window, so the deeper pages are rejected instead of loading offset rows from each shard.
+const MaxShardedWindow = 10000
+
+// errShardedWindowExceeded is returned when the page is too deep to be gathered from the shards.
+// nolint:gochecknoglobals
+var errShardedWindowExceeded = &otelexample.Error{
+	Code:    otelexample.ErrorCodeInvalid,
+	Message: fmt.Sprintf("offset and limit should not exceed %d in total", MaxShardedWindow),
+	Err:     nil,
+}
+
+// findUserAccountsAcrossShards gathers the page from all shards. Every shard
+// returns its first offset+limit accounts ordered by creation time, so the
+// page could be cut from the merged list.
+func (svc *UserAccountService) findUserAccountsAcrossShards(
+	ctx context.Context,
+	tenantID otelexample.TenantID,
+	result *otelexample.FindUserAccountsResult,
+) error {
+	// The offset is supplied by the client, so it is checked before the sum
+	// could overflow.
+	if result.Options.Offset() > MaxShardedWindow-result.Options.Limit() {
+		return errShardedWindowExceeded
+	}
+
+	var (
+		window = result.Options.Offset() + result.Options.Limit()
+		totals = make([]uint64, len(svc.shards))
+		pages  = make([][]*otelexample.UserAccount, len(svc.shards))
+	)
+
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	for i, shard := range svc.shards {
+		i, shard := i, shard
+
+		group.Go(func() error {
+			shardCtx := WithShard(groupCtx, shard)
+
+			var err error
+
+			if totals[i], err = svc.findUserAccountsCountTotal(shardCtx, tenantID); err != nil {
+				return fmt.Errorf("shard %s: %w", shard, err)
+			}
+
+			// One more account is requested to find out that the next page exists.
+			if pages[i], err = svc.findOrderedUserAccounts(shardCtx, tenantID, window+1); err != nil {
+				return fmt.Errorf("shard %s: %w", shard, err)
+			}
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return err // nolint:wrapcheck
+	}
+
+	var size int
+
+	for i := range svc.shards {
+		result.Total += totals[i]
+		size += len(pages[i])
+	}
+
+	merged := make([]*otelexample.UserAccount, 0, size)
+
+	for i := range svc.shards {
+		merged = append(merged, pages[i]...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if !merged[i].CreatedAt.Equal(merged[j].CreatedAt) {
+			return merged[i].CreatedAt.Before(merged[j].CreatedAt)
+		}
+
+		return merged[i].ID < merged[j].ID
+	})
+
+	count := uint64(len(merged))
+
+	result.HasNext = count > window
+	result.Data = merged[minUint64(result.Options.Offset(), count):minUint64(window, count)]
+
+	return nil
+}
+
+func (svc *UserAccountService) findOrderedUserAccounts(
+	ctx context.Context,
+	tenantID otelexample.TenantID,
+	limit uint64,
+) (
+	[]*otelexample.UserAccount,
+	error,
+) {
+	ctx = WithQueryName(ctx, "find_ordered_user_accounts")
+
+	stmt, err := svc.prepareTxBeginner.PrepareContext(ctx, `SELECT ua.user_account_id, ua.username, `+
+		`ua.created_at AS ua_created_at, u.user_id, u.first_name, u.last_name, u.created_at AS u_created_at FROM `+
+		`user_accounts ua JOIN users u ON ua.tenant_id = u.tenant_id AND ua.user_id = u.user_id WHERE `+
+		`ua.tenant_id = ? ORDER BY `+userAccountsOrder+` LIMIT ?`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(ctx context.Context, stmt Stmt, err *error) {
+		if closeErr := stmt.Close(ctx); closeErr != nil {
+			*err = closeErr
+		}
+	}(ctx, stmt, &err)
+
+	rows, err := stmt.QueryContext(ctx, tenantID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(closer io.Closer, err *error) {
+		if closeErr := rows.Close(); closeErr != nil {
+			*err = closeErr
+		}
+	}(rows, &err)
+
+	var uaa []*otelexample.UserAccount
+
+	for rows.Next() {
+		var (
+			createdAt     int64
+			userCreatedAt int64
+		)
+
+		ua := new(otelexample.UserAccount)
+		ua.User = new(otelexample.User)
+
+		err = rows.Scan(&ua.ID, &ua.Username, &createdAt, &ua.User.ID, &ua.User.FirstName, &ua.User.LastName,
+			&userCreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		ua.CreatedAt, ua.User.CreatedAt = time.UnixMilli(createdAt), time.UnixMilli(userCreatedAt)
+
+		uaa = append(uaa, ua)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return uaa, nil
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
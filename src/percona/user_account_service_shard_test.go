@@ -0,0 +1,100 @@
+package percona_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona/perconatest"
+)
+
+// userAccountsOrderPattern matches the order of the listed user accounts, which is the same with and without
+// shards.
+const userAccountsOrderPattern = `ORDER BY ua\.created_at, ua\.user_account_id`
+
+func newShardedUserAccountService(t *testing.T, scripts ...*perconatest.Script) *percona.UserAccountService {
+	t.Helper()
+
+	var (
+		shards = make([]percona.Shard, 0, len(scripts))
+		names  = make([]string, 0, len(scripts))
+	)
+
+	for i, script := range scripts {
+		name := string(rune('a' + i))
+
+		shards = append(shards, percona.Shard{
+			Name:              name,
+			PrepareTxBeginner: connectClient(t, script),
+		})
+		names = append(names, name)
+	}
+
+	return percona.NewUserAccountService(percona.NewShardRouter(shards), nil, nil, percona.WithShards(names))
+}
+
+func TestUserAccountService_FindUserAccounts_AcrossShards(t *testing.T) {
+	t.Parallel()
+
+	var (
+		columns = []string{
+			"user_account_id", "username", "ua_created_at", "user_id", "first_name", "last_name", "u_created_at",
+		}
+
+		first  = perconatest.NewScript(t)
+		second = perconatest.NewScript(t)
+	)
+
+	first.ExpectQuery(`SELECT count\(1\)`).WillReturnRows(perconatest.NewRows("count").AddRow(int64(2)))
+	first.ExpectQuery(userAccountsOrderPattern).WithArgs("tenant", uint64(4)).
+		WillReturnRows(perconatest.NewRows(columns...).
+			AddRow("a1", "alice", int64(1), "u1", "Alice", "A", int64(1)).
+			AddRow("a3", "carol", int64(3), "u3", "Carol", "C", int64(3)))
+
+	second.ExpectQuery(`SELECT count\(1\)`).WillReturnRows(perconatest.NewRows("count").AddRow(int64(1)))
+	second.ExpectQuery(userAccountsOrderPattern).WithArgs("tenant", uint64(4)).
+		WillReturnRows(perconatest.NewRows(columns...).
+			AddRow("b2", "bob", int64(2), "u2", "Bob", "B", int64(2)))
+
+	svc := newShardedUserAccountService(t, first, second)
+	ctx := otelexample.NewContextWithTenantID(context.Background(), "tenant")
+
+	result, err := svc.FindUserAccounts(ctx, otelexample.NewFindOptions(2, 1))
+	if err != nil {
+		t.Fatalf("find user accounts: %v", err)
+	}
+
+	if result.Total != 3 || result.HasNext {
+		t.Errorf("total = %d, has next = %t, expected 3 and false", result.Total, result.HasNext)
+	}
+
+	if len(result.Data) != 2 || result.Data[0].ID != "b2" || result.Data[1].ID != "a3" {
+		t.Errorf("unexpected page: %v", result.Data)
+	}
+}
+
+func TestUserAccountService_FindUserAccounts_DeepPageAcrossShards(t *testing.T) {
+	t.Parallel()
+
+	for name, offset := range map[string]uint64{
+		"exceeded window": percona.MaxShardedWindow,
+		"overflow":        math.MaxUint64,
+	} {
+		offset := offset
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// No calls are scripted, so the shards should not be queried.
+			svc := newShardedUserAccountService(t, perconatest.NewScript(t), perconatest.NewScript(t))
+			ctx := otelexample.NewContextWithTenantID(context.Background(), "tenant")
+
+			_, err := svc.FindUserAccounts(ctx, otelexample.NewFindOptions(10, offset))
+			if code := otelexample.ErrorCodeFromError(err); code != otelexample.ErrorCodeInvalid {
+				t.Fatalf("error code = %s, expected %s: %v", code, otelexample.ErrorCodeInvalid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package percona_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/otelexampletest"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona/perconatest"
+)
+
+// errDuplicateEntry is the number of the error which is returned when the unique key is violated.
+const errDuplicateEntry = 1062
+
+type fakeUser struct {
+	firstName string
+	lastName  string
+	createdAt int64
+}
+
+type fakeUserAccount struct {
+	tenantID  string
+	id        string
+	username  string
+	userID    string
+	createdAt int64
+}
+
+// fakeDatabase keeps the rows of users and user_accounts tables and answers
+// the statements of UserAccountService.
+type fakeDatabase struct {
+	users        map[[2]string]fakeUser
+	userAccounts []fakeUserAccount
+}
+
+// newFakeDatabase returns the script which is answered by the fake database.
+func newFakeDatabase(t *testing.T) *perconatest.Script {
+	t.Helper()
+
+	var (
+		script = perconatest.NewScript(t)
+		db     = &fakeDatabase{
+			users:        make(map[[2]string]fakeUser),
+			userAccounts: nil,
+		}
+	)
+
+	script.RespondQuery(`^SELECT EXISTS\(SELECT 1 FROM user_accounts ua WHERE`, db.usernameExists)
+	script.RespondExec(`^INSERT INTO users `, db.insertUser)
+	script.RespondExec(`^INSERT INTO user_accounts `, db.insertUserAccount)
+	script.RespondQuery(`^SELECT count\(1\) FROM user_accounts`, db.countUserAccounts)
+	script.RespondQuery(`^SELECT \* FROM \(SELECT ROW_NUMBER\(\)`, db.findUserAccounts)
+	script.RespondQuery(`^SELECT EXISTS\(SELECT 1 FROM \(SELECT ROW_NUMBER\(\)`, db.hasNextUserAccounts)
+	script.RespondQuery(`^SELECT ua.username, `, db.findUserAccountByID)
+
+	return script
+}
+
+func (db *fakeDatabase) tenantUserAccounts(tenantID string) []fakeUserAccount {
+	uaa := make([]fakeUserAccount, 0, len(db.userAccounts))
+
+	for _, ua := range db.userAccounts {
+		if ua.tenantID == tenantID {
+			uaa = append(uaa, ua)
+		}
+	}
+
+	return uaa
+}
+
+func (db *fakeDatabase) usernameExists(args []driver.NamedValue) (*perconatest.Rows, error) {
+	for _, ua := range db.tenantUserAccounts(args[0].Value.(string)) { // nolint:forcetypeassert
+		if ua.username == args[1].Value {
+			return perconatest.NewRows("is_exists").AddRow(int64(1)), nil
+		}
+	}
+
+	return perconatest.NewRows("is_exists").AddRow(int64(0)), nil
+}
+
+func (db *fakeDatabase) insertUser(args []driver.NamedValue) (driver.Result, error) {
+	db.users[[2]string{args[0].Value.(string), args[1].Value.(string)}] = fakeUser{ // nolint:forcetypeassert
+		firstName: args[2].Value.(string), // nolint:forcetypeassert
+		lastName:  args[3].Value.(string), // nolint:forcetypeassert
+		createdAt: args[4].Value.(int64),  // nolint:forcetypeassert
+	}
+
+	return perconatest.NewResult(0, 1), nil
+}
+
+func (db *fakeDatabase) insertUserAccount(args []driver.NamedValue) (driver.Result, error) {
+	ua := fakeUserAccount{
+		tenantID:  args[0].Value.(string), // nolint:forcetypeassert
+		id:        args[1].Value.(string), // nolint:forcetypeassert
+		username:  args[2].Value.(string), // nolint:forcetypeassert
+		userID:    args[3].Value.(string), // nolint:forcetypeassert
+		createdAt: args[4].Value.(int64),  // nolint:forcetypeassert
+	}
+
+	for _, existing := range db.tenantUserAccounts(ua.tenantID) {
+		if existing.username == ua.username {
+			return nil, perconatest.MySQLError(errDuplicateEntry, "Duplicate entry")
+		}
+	}
+
+	db.userAccounts = append(db.userAccounts, ua)
+
+	return perconatest.NewResult(int64(len(db.userAccounts)), 1), nil
+}
+
+func (db *fakeDatabase) countUserAccounts(args []driver.NamedValue) (*perconatest.Rows, error) {
+	uaa := db.tenantUserAccounts(args[0].Value.(string)) // nolint:forcetypeassert
+
+	return perconatest.NewRows("count(1)").AddRow(int64(len(uaa))), nil
+}
+
+func (db *fakeDatabase) findUserAccounts(args []driver.NamedValue) (*perconatest.Rows, error) {
+	var (
+		uaa    = db.tenantUserAccounts(args[0].Value.(string)) // nolint:forcetypeassert
+		offset = intValue(args[1].Value)
+		limit  = intValue(args[2].Value)
+		rows   = perconatest.NewRows("row_num", "user_account_id", "username", "ua_created_at", "user_id",
+			"first_name", "last_name", "u_created_at")
+	)
+
+	for i := offset; i < len(uaa) && i < offset+limit; i++ {
+		user := db.users[[2]string{uaa[i].tenantID, uaa[i].userID}]
+
+		rows.AddRow(int64(i+1), uaa[i].id, uaa[i].username, uaa[i].createdAt, uaa[i].userID, user.firstName,
+			user.lastName, user.createdAt)
+	}
+
+	return rows, nil
+}
+
+func (db *fakeDatabase) hasNextUserAccounts(args []driver.NamedValue) (*perconatest.Rows, error) {
+	var (
+		uaa     = db.tenantUserAccounts(args[0].Value.(string)) // nolint:forcetypeassert
+		hasNext = int64(0)
+	)
+
+	if len(uaa) > intValue(args[1].Value) {
+		hasNext = 1
+	}
+
+	return perconatest.NewRows("has_next").AddRow(hasNext), nil
+}
+
+func (db *fakeDatabase) findUserAccountByID(args []driver.NamedValue) (*perconatest.Rows, error) {
+	rows := perconatest.NewRows("username", "ua_created_at", "user_id", "first_name", "last_name",
+		"u_created_at")
+
+	for _, ua := range db.tenantUserAccounts(args[0].Value.(string)) { // nolint:forcetypeassert
+		if ua.id != args[1].Value {
+			continue
+		}
+
+		user := db.users[[2]string{ua.tenantID, ua.userID}]
+
+		rows.AddRow(ua.username, ua.createdAt, ua.userID, user.firstName, user.lastName, user.createdAt)
+	}
+
+	return rows, nil
+}
+
+// intValue returns the value of the integer argument, which is converted to int64 or uint64 by database/sql.
+func intValue(value driver.Value) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case uint64:
+		return int(v)
+	}
+
+	return 0
+}
+
+func TestUserAccountService(t *testing.T) {
+	t.Parallel()
+
+	otelexampletest.RunUserAccountServiceSuite(t, func(
+		t *testing.T,
+		identifierGenerator otelexample.IdentifierGenerator,
+		timer otelexample.Timer,
+	) otelexample.UserAccountService {
+		t.Helper()
+
+		return percona.NewUserAccountService(connectClient(t, newFakeDatabase(t)), identifierGenerator, timer)
+	})
+}
+
+// newScriptedUserAccountService returns the service which follows the script.
+func newScriptedUserAccountService(
+	t *testing.T,
+	script *perconatest.Script,
+	opts ...percona.UserAccountServiceOption,
+) *percona.UserAccountService {
+	t.Helper()
+
+	var (
+		identifierGenerator = otelexampletest.NewSequenceIdentifierGenerator("id-")
+		timer               = otelexampletest.NewStepTimer(time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+			time.Second)
+	)
+
+	return percona.NewUserAccountService(connectClient(t, script), identifierGenerator, timer, opts...)
+}
+
+func newUserAccount() *otelexample.UserAccount {
+	return &otelexample.UserAccount{
+		ID:       otelexample.EmptyID,
+		Username: "alice",
+		User: &otelexample.User{
+			ID:        otelexample.EmptyID,
+			FirstName: "Alice",
+			LastName:  "Liddell",
+			CreatedAt: time.Time{},
+		},
+		CreatedAt: time.Time{},
+	}
+}
+
+func TestUserAccountService_CreateUserAccount_Conflict(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		script func(script *perconatest.Script)
+	}{
+		{
+			name: "UsernameExists",
+			script: func(script *perconatest.Script) {
+				script.ExpectBegin()
+				script.ExpectQuery(`^SELECT EXISTS`).WithArgs("tenant", "alice").
+					WillReturnRows(perconatest.NewRows("is_exists").AddRow(int64(1)))
+				script.ExpectRollback()
+			},
+		},
+		{
+			name: "DuplicateEntry",
+			script: func(script *perconatest.Script) {
+				script.ExpectBegin()
+				script.ExpectQuery(`^SELECT EXISTS`).WithArgs("tenant", "alice").
+					WillReturnRows(perconatest.NewRows("is_exists").AddRow(int64(0)))
+				script.ExpectExec(`^INSERT INTO users `)
+				script.ExpectExec(`^INSERT INTO user_accounts `).
+					WillReturnError(perconatest.MySQLError(errDuplicateEntry, "Duplicate entry"))
+				script.ExpectRollback()
+			},
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			script := perconatest.NewScript(t)
+			tc.script(script)
+
+			var (
+				ctx = otelexample.NewContextWithTenantID(context.Background(), "tenant")
+				svc = newScriptedUserAccountService(t, script)
+				ua  = newUserAccount()
+			)
+
+			err := svc.CreateUserAccount(ctx, ua)
+			if code := otelexample.ErrorCodeFromError(err); code != otelexample.ErrorCodeConflict {
+				t.Fatalf("unexpected error code %q: %v", code, err)
+			}
+
+			if ua.ID != otelexample.EmptyID {
+				t.Errorf("identifier of the conflicting account was set: %q", ua.ID)
+			}
+		})
+	}
+}
+
+func TestUserAccountService_CreateUserAccount_CommitError(t *testing.T) {
+	t.Parallel()
+
+	errCommit := errors.New("commit failed")
+
+	script := perconatest.NewScript(t)
+	script.ExpectBegin()
+	script.ExpectQuery(`^SELECT EXISTS`).WillReturnRows(perconatest.NewRows("is_exists").AddRow(int64(0)))
+	script.ExpectExec(`^INSERT INTO users `).WithArgs("tenant", perconatest.AnyArg(), "Alice", "Liddell",
+		perconatest.AnyArg())
+	script.ExpectExec(`^INSERT INTO user_accounts `).WithArgs("tenant", perconatest.AnyArg(), "alice",
+		perconatest.AnyArg(), perconatest.AnyArg())
+	script.ExpectCommit().WillReturnError(errCommit)
+
+	var (
+		ctx = otelexample.NewContextWithTenantID(context.Background(), "tenant")
+		svc = newScriptedUserAccountService(t, script)
+	)
+
+	if err := svc.CreateUserAccount(ctx, newUserAccount()); !errors.Is(err, errCommit) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUserAccountService_FindUserAccountByID_DeadlineExceeded(t *testing.T) {
+	t.Parallel()
+
+	script := perconatest.NewScript(t)
+	script.ExpectQuery(`^SELECT ua.username, `).WithArgs("tenant", "id").WillDelayFor(time.Second)
+
+	var (
+		ctx      = otelexample.NewContextWithTenantID(context.Background(), "tenant")
+		exceeded = make(chan percona.Operation, 1)
+		svc      = newScriptedUserAccountService(t, script,
+			percona.WithGetTimeout(time.Millisecond*10),
+			percona.WithOperationDeadlineExceededHandler(func(_ context.Context, op percona.Operation) {
+				exceeded <- op
+			}))
+	)
+
+	if _, err := svc.FindUserAccountByID(ctx, "id"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if op := <-exceeded; op != percona.OperationGet {
+		t.Errorf("unexpected operation: %s", op)
+	}
+}
+
+func TestUserAccountService_FindUserAccounts_Order(t *testing.T) {
+	t.Parallel()
+
+	script := perconatest.NewScript(t)
+	script.ExpectQuery(`SELECT count\(1\)`).WillReturnRows(perconatest.NewRows("count").AddRow(int64(3)))
+	script.ExpectQuery(`OVER \(` + userAccountsOrderPattern + `\) as row_num, `).
+		WillReturnRows(perconatest.NewRows("row_num", "user_account_id", "username", "ua_created_at", "user_id",
+			"first_name", "last_name", "u_created_at").
+			AddRow(int64(1), "a1", "alice", int64(1), "u1", "Alice", "A", int64(1)).
+			AddRow(int64(2), "b2", "bob", int64(1), "u2", "Bob", "B", int64(1)))
+	script.ExpectQuery(`OVER \(` + userAccountsOrderPattern + `\) as row_num FROM`).
+		WillReturnRows(perconatest.NewRows("has_next").AddRow(int64(1)))
+
+	var (
+		svc = percona.NewUserAccountService(connectClient(t, script), nil, nil)
+		ctx = otelexample.NewContextWithTenantID(context.Background(), "tenant")
+	)
+
+	result, err := svc.FindUserAccounts(ctx, otelexample.NewFindOptions(2, 0))
+	if err != nil {
+		t.Fatalf("find user accounts: %v", err)
+	}
+
+	if len(result.Data) != 2 || result.Data[0].ID != "a1" || result.Data[1].ID != "b2" || !result.HasNext {
+		t.Errorf("unexpected page: %v, has next = %t", result.Data, result.HasNext)
+	}
+}
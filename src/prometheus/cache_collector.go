@@ -0,0 +1,73 @@
+package prometheus
+
+import (
+	"github.com/morozovcookie/opentelemetry-prometheus-example/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CacheStatsProvider represents a service that can provide the cache statistics.
+type CacheStatsProvider interface {
+	// Stats returns the cache statistics.
+	Stats() cache.Stats
+}
+
+var _ prometheus.Collector = (*CacheCollector)(nil)
+
+// CacheCollector collects the cache metrics.
+type CacheCollector struct {
+	provider CacheStatsProvider
+
+	sizeDesc        *prometheus.Desc
+	capacityDesc    *prometheus.Desc
+	hitsDesc        *prometheus.Desc
+	missesDesc      *prometheus.Desc
+	evictionsDesc   *prometheus.Desc
+	expirationsDesc *prometheus.Desc
+}
+
+// NewCacheCollector returns a new instance of CacheCollector.
+func NewCacheCollector(provider CacheStatsProvider) *CacheCollector {
+	return &CacheCollector{
+		provider: provider,
+
+		sizeDesc: prometheus.NewDesc("size",
+			"measures the number of entries are currently cached", nil, nil),
+		capacityDesc: prometheus.NewDesc("capacity",
+			"measures the maximum number of entries could be cached", nil, nil),
+		hitsDesc: prometheus.NewDesc("hits_total",
+			"measures the number of cache hits", nil, nil),
+		missesDesc: prometheus.NewDesc("misses_total",
+			"measures the number of cache misses", nil, nil),
+		evictionsDesc: prometheus.NewDesc("evictions_total",
+			"measures the number of entries were evicted because of capacity limit", nil, nil),
+		expirationsDesc: prometheus.NewDesc("expirations_total",
+			"measures the number of entries were removed because of TTL", nil, nil),
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics
+// collected by this Collector to the provided channel and returns once
+// the last descriptor has been sent.
+func (c *CacheCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.sizeDesc
+	descs <- c.capacityDesc
+	descs <- c.hitsDesc
+	descs <- c.missesDesc
+	descs <- c.evictionsDesc
+	descs <- c.expirationsDesc
+}
+
+// Collect is called by the Prometheus registry when collecting
+// metrics. The implementation sends each collected metric via the
+// provided channel and returns once the last metric has been sent.
+func (c *CacheCollector) Collect(metrics chan<- prometheus.Metric) {
+	stats := c.provider.Stats()
+
+	metrics <- prometheus.MustNewConstMetric(c.sizeDesc, prometheus.GaugeValue, float64(stats.Size))
+	metrics <- prometheus.MustNewConstMetric(c.capacityDesc, prometheus.GaugeValue, float64(stats.Capacity))
+	metrics <- prometheus.MustNewConstMetric(c.hitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	metrics <- prometheus.MustNewConstMetric(c.missesDesc, prometheus.CounterValue, float64(stats.Misses))
+	metrics <- prometheus.MustNewConstMetric(c.evictionsDesc, prometheus.CounterValue, float64(stats.Evictions))
+	metrics <- prometheus.MustNewConstMetric(c.expirationsDesc, prometheus.CounterValue,
+		float64(stats.Expirations))
+}
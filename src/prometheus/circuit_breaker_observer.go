@@ -0,0 +1,67 @@
+package prometheus
+
+import (
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CircuitBreakerObserver measures the circuit breaker state transitions.
+type CircuitBreakerObserver struct {
+	stateGaugeVec         *prometheus.GaugeVec
+	transitionsCounterVec *prometheus.CounterVec
+}
+
+// NewCircuitBreakerObserver returns a new instance of CircuitBreakerObserver.
+func NewCircuitBreakerObserver(registerer prometheus.Registerer) *CircuitBreakerObserver {
+	observer := &CircuitBreakerObserver{
+		stateGaugeVec: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   "",
+			Subsystem:   "",
+			Name:        "circuit_breaker_state",
+			Help:        "measures the current state of the circuit breaker",
+			ConstLabels: nil,
+		}, []string{"state"}),
+		transitionsCounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "",
+			Subsystem:   "",
+			Name:        "circuit_breaker_transitions_total",
+			Help:        "measures the number of the circuit breaker state transitions",
+			ConstLabels: nil,
+		}, []string{"from", "to"}),
+	}
+
+	registerer.MustRegister(observer.stateGaugeVec, observer.transitionsCounterVec)
+
+	observer.setState(percona.CircuitBreakerClosed)
+
+	return observer
+}
+
+// HandleStateChange records the state transition. It could be used as percona.CircuitBreakerStateChangeHandler.
+func (o *CircuitBreakerObserver) HandleStateChange(from, to percona.CircuitBreakerState) {
+	o.transitionsCounterVec.
+		With(prometheus.Labels{
+			"from": from.String(),
+			"to":   to.String(),
+		}).
+		Inc()
+
+	o.setState(to)
+}
+
+func (o *CircuitBreakerObserver) setState(current percona.CircuitBreakerState) {
+	for _, state := range []percona.CircuitBreakerState{
+		percona.CircuitBreakerClosed, percona.CircuitBreakerOpen, percona.CircuitBreakerHalfOpen,
+	} {
+		var val float64
+		if state == current {
+			val = 1
+		}
+
+		o.stateGaugeVec.
+			With(prometheus.Labels{
+				"state": state.String(),
+			}).
+			Set(val)
+	}
+}
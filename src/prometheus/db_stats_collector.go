@@ -0,0 +1,85 @@
+package prometheus
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DBStatsProvider represents a service that can provide database connection pool statistics.
+type DBStatsProvider interface {
+	// Stats returns database connection pool statistics.
+	Stats() sql.DBStats
+}
+
+var _ prometheus.Collector = (*DBStatsCollector)(nil)
+
+// DBStatsCollector collects database connection pool metrics.
+type DBStatsCollector struct {
+	provider DBStatsProvider
+
+	maxOpenDesc      *prometheus.Desc
+	openDesc         *prometheus.Desc
+	inUseDesc        *prometheus.Desc
+	idleDesc         *prometheus.Desc
+	waitCountDesc    *prometheus.Desc
+	waitDurationDesc *prometheus.Desc
+	closedDesc       *prometheus.Desc
+}
+
+// NewDBStatsCollector returns a new instance of DBStatsCollector.
+func NewDBStatsCollector(provider DBStatsProvider) *DBStatsCollector {
+	return &DBStatsCollector{
+		provider: provider,
+
+		maxOpenDesc: prometheus.NewDesc("connections_max_open",
+			"measures the maximum number of open connections to the database", nil, nil),
+		openDesc: prometheus.NewDesc("connections_open",
+			"measures the number of established connections both in use and idle", nil, nil),
+		inUseDesc: prometheus.NewDesc("connections_in_use",
+			"measures the number of connections currently in use", nil, nil),
+		idleDesc: prometheus.NewDesc("connections_idle",
+			"measures the number of idle connections", nil, nil),
+		waitCountDesc: prometheus.NewDesc("connections_wait_total",
+			"measures the total number of connections waited for", nil, nil),
+		waitDurationDesc: prometheus.NewDesc("connections_wait_duration_seconds_total",
+			"measures the total time blocked waiting for a new connection", nil, nil),
+		closedDesc: prometheus.NewDesc("connections_closed_total",
+			"measures the total number of connections closed by the reason", []string{"reason"}, nil),
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics
+// collected by this Collector to the provided channel and returns once
+// the last descriptor has been sent.
+func (c *DBStatsCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.maxOpenDesc
+	descs <- c.openDesc
+	descs <- c.inUseDesc
+	descs <- c.idleDesc
+	descs <- c.waitCountDesc
+	descs <- c.waitDurationDesc
+	descs <- c.closedDesc
+}
+
+// Collect is called by the Prometheus registry when collecting
+// metrics. The implementation sends each collected metric via the
+// provided channel and returns once the last metric has been sent.
+func (c *DBStatsCollector) Collect(metrics chan<- prometheus.Metric) {
+	stats := c.provider.Stats()
+
+	metrics <- prometheus.MustNewConstMetric(c.maxOpenDesc, prometheus.GaugeValue,
+		float64(stats.MaxOpenConnections))
+	metrics <- prometheus.MustNewConstMetric(c.openDesc, prometheus.GaugeValue, float64(stats.OpenConnections))
+	metrics <- prometheus.MustNewConstMetric(c.inUseDesc, prometheus.GaugeValue, float64(stats.InUse))
+	metrics <- prometheus.MustNewConstMetric(c.idleDesc, prometheus.GaugeValue, float64(stats.Idle))
+	metrics <- prometheus.MustNewConstMetric(c.waitCountDesc, prometheus.CounterValue, float64(stats.WaitCount))
+	metrics <- prometheus.MustNewConstMetric(c.waitDurationDesc, prometheus.CounterValue,
+		stats.WaitDuration.Seconds())
+	metrics <- prometheus.MustNewConstMetric(c.closedDesc, prometheus.CounterValue, float64(stats.MaxIdleClosed),
+		"max_idle")
+	metrics <- prometheus.MustNewConstMetric(c.closedDesc, prometheus.CounterValue,
+		float64(stats.MaxIdleTimeClosed), "max_idle_time")
+	metrics <- prometheus.MustNewConstMetric(c.closedDesc, prometheus.CounterValue,
+		float64(stats.MaxLifetimeClosed), "max_lifetime")
+}
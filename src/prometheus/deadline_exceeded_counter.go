@@ -0,0 +1,39 @@
+package prometheus
+
+import (
+	"context"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DeadlineExceededCounter counts database operations which have hit their deadline.
+type DeadlineExceededCounter struct {
+	counterVec *prometheus.CounterVec
+}
+
+// NewDeadlineExceededCounter returns a new instance of DeadlineExceededCounter.
+func NewDeadlineExceededCounter(registerer prometheus.Registerer) *DeadlineExceededCounter {
+	counter := &DeadlineExceededCounter{
+		counterVec: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "",
+			Subsystem:   "",
+			Name:        "operation_deadline_exceeded_total",
+			Help:        "measures the number of operations which have hit their deadline",
+			ConstLabels: nil,
+		}, []string{"operation"}),
+	}
+
+	registerer.MustRegister(counter.counterVec)
+
+	return counter
+}
+
+// HandleDeadlineExceeded increments the counter of the operation. It could be used as percona.DeadlineExceededHandler.
+func (c *DeadlineExceededCounter) HandleDeadlineExceeded(_ context.Context, op percona.Operation) {
+	c.counterVec.
+		With(prometheus.Labels{
+			"operation": string(op),
+		}).
+		Inc()
+}
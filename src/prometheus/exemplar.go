@@ -0,0 +1,66 @@
+package prometheus
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	exemplarTraceIDLabel = "trace_id"
+
+	// exemplarRequestIDLabel is the name of the request identifier, which is
+	// short to leave room for the identifier itself. It is the same as the log
+	// field, so the request could be found in the logs.
+	exemplarRequestIDLabel = "rid"
+)
+
+// observeWithExemplar observes the value with the exemplar which links the
+// observation to the trace and the request. The oversized exemplar is dropped
+// by the client library, so the trace identifier is added only when it fits
+// and the request identifier is truncated to the rest of the limit. The prefix
+// of the request identifier is still enough to find the request in the logs.
+func observeWithExemplar(ctx context.Context, observer prometheus.Observer, value float64) {
+	var (
+		labels = make(prometheus.Labels, 2) // nolint:gomnd
+		runes  int
+	)
+
+	// The trace identifier goes first, because it leads to the whole request
+	// handling. The unsampled traces are not exported, so they are useless.
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsSampled() {
+		traceID := spanContext.TraceID().String()
+
+		labels[exemplarTraceIDLabel], runes = traceID, len(exemplarTraceIDLabel)+len(traceID)
+	}
+
+	if requestID := truncateRunes(middleware.GetReqID(ctx),
+		prometheus.ExemplarMaxRunes-runes-len(exemplarRequestIDLabel)); requestID != "" {
+		labels[exemplarRequestIDLabel] = requestID
+	}
+
+	exemplarObserver, ok := observer.(prometheus.ExemplarObserver)
+	if !ok || len(labels) == 0 {
+		observer.Observe(value)
+
+		return
+	}
+
+	exemplarObserver.ObserveWithExemplar(value, labels)
+}
+
+// truncateRunes returns the prefix of the value which has at most size runes.
+func truncateRunes(value string, size int) string {
+	if size <= 0 {
+		return ""
+	}
+
+	if utf8.RuneCountInString(value) <= size {
+		return value
+	}
+
+	return string([]rune(value)[:size])
+}
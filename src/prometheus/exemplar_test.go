@@ -0,0 +1,133 @@
+package prometheus_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/prometheus"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHTTPHandler_Exemplars(t *testing.T) {
+	t.Parallel()
+
+	const (
+		// requestID is as long as the identifiers which are generated by the server.
+		requestID = "0123456789abcdefghijklmnopqrstuvwxyz0123456789abcdefghijklmnopqr"
+		traceID   = "4bf92f3577b34da6a3ce929d0e0e4736"
+	)
+
+	for _, tc := range []struct {
+		name     string
+		sampled  bool
+		expected []string
+	}{
+		{
+			name:     "RequestID",
+			sampled:  false,
+			expected: []string{`rid="` + requestID[:61] + `"`},
+		},
+		{
+			name:     "TraceAndRequestID",
+			sampled:  true,
+			expected: []string{`trace_id="` + traceID + `"`, `rid="` + requestID[:21] + `"`},
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				registry = prom.NewRegistry()
+				router   = chi.NewRouter()
+				ctx      = context.WithValue(context.Background(), middleware.RequestIDKey, requestID)
+			)
+
+			if tc.sampled {
+				ctx = trace.ContextWithSpanContext(ctx, newSampledSpanContext(t, traceID))
+			}
+
+			router.Use(prometheus.HTTPHandler(registry, prometheus.NewTenantLabeler(1)))
+			router.Get("/users/{id}", func(writer http.ResponseWriter, _ *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			})
+
+			router.ServeHTTP(httptest.NewRecorder(),
+				httptest.NewRequest(http.MethodGet, "/users/1", nil).WithContext(ctx))
+
+			exemplar := findExemplar(t, scrapeOpenMetrics(t, registry), "request_duration_seconds_bucket")
+			for _, label := range tc.expected {
+				if !strings.Contains(exemplar, label) {
+					t.Errorf("exemplar %s does not contain %s", exemplar, label)
+				}
+			}
+		})
+	}
+}
+
+func newSampledSpanContext(t *testing.T, traceID string) trace.SpanContext {
+	t.Helper()
+
+	tid, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		t.Fatalf("trace id: %v", err)
+	}
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tid,
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		TraceState: trace.TraceState{},
+		Remote:     false,
+	})
+}
+
+// findExemplar returns the labels of the first exemplar of the metric.
+func findExemplar(t *testing.T, output, metric string) string {
+	t.Helper()
+
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, metric) {
+			continue
+		}
+
+		if _, exemplar, ok := strings.Cut(line, " # "); ok {
+			return exemplar[:strings.Index(exemplar, "}")+1]
+		}
+	}
+
+	t.Fatalf("metric %s has no exemplar:\n%s", metric, output)
+
+	return ""
+}
+
+// scrapeOpenMetrics returns the metrics in OpenMetrics format, which is the only one exposing the exemplars.
+func scrapeOpenMetrics(t *testing.T, gatherer prom.Gatherer) string {
+	t.Helper()
+
+	var (
+		recorder = httptest.NewRecorder()
+		request  = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	)
+
+	request.Header.Set("Accept", "application/openmetrics-text; version=0.0.1")
+
+	handler := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}) // nolint:exhaustivestruct
+	handler.ServeHTTP(recorder, request)
+
+	output, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		t.Fatalf("read metrics: %v", err)
+	}
+
+	return string(output)
+}
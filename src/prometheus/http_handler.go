@@ -1,10 +1,13 @@
 package prometheus
 
 import (
+	"io"
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -14,6 +17,7 @@ type response struct {
 	wrapped http.ResponseWriter
 
 	statusCode int
+	size       int64
 }
 
 // Header returns the header map that will be sent by
@@ -25,7 +29,10 @@ func (resp *response) Header() http.Header {
 
 // Write writes the data to the connection as part of an HTTP reply.
 func (resp *response) Write(bb []byte) (int, error) {
-	return resp.wrapped.Write(bb)
+	n, err := resp.wrapped.Write(bb)
+	resp.size += int64(n)
+
+	return n, err // nolint:wrapcheck
 }
 
 // WriteHeader sends an HTTP response header with the provided
@@ -36,78 +43,258 @@ func (resp *response) WriteHeader(statusCode int) {
 	resp.wrapped.WriteHeader(resp.statusCode)
 }
 
-func HTTPHandler(registry prometheus.Registerer) func(next http.Handler) http.Handler {
+var _ io.ReadCloser = (*body)(nil)
+
+// body counts the bytes of the request body which are read by the handler.
+type body struct {
+	wrapped io.ReadCloser
+
+	size int64
+}
+
+// Read reads up to len(bb) bytes of the request body.
+func (b *body) Read(bb []byte) (int, error) {
+	n, err := b.wrapped.Read(bb)
+	b.size += int64(n)
+
+	return n, err // nolint:wrapcheck
+}
+
+// Close closes the request body.
+func (b *body) Close() error {
+	return b.wrapped.Close() // nolint:wrapcheck
+}
+
+// UnmatchedRouteLabelValue is the target label value for the requests which match no route.
+const UnmatchedRouteLabelValue = "unmatched"
+
+// DefaultSizeBuckets are the histogram buckets for the request and response body sizes in bytes.
+// nolint:gochecknoglobals,gomnd
+var DefaultSizeBuckets = prometheus.ExponentialBuckets(100, 10, 6)
+
+type httpMetrics struct {
+	cfg     *httpHandlerConfig
+	tenants *TenantLabeler
+
+	inFlightGauge       prometheus.Gauge
+	requestCounterVec   *prometheus.CounterVec
+	requestDurationVec  *prometheus.HistogramVec
+	requestSizeVec      *prometheus.HistogramVec
+	responseSizeVec     *prometheus.HistogramVec
+	requestLimiter      *LabelLimiter
+	durationLimiter     *LabelLimiter
+	requestSizeLimiter  *LabelLimiter
+	responseSizeLimiter *LabelLimiter
+}
+
+// HTTPHandler measures the inbound HTTP requests. The target label is the matched route pattern, so the
+// requests to the different resources of the same route are reported as the single time series.
+func HTTPHandler(
+	registry prometheus.Registerer,
+	tenants *TenantLabeler,
+	opts ...HTTPHandlerOption,
+) func(next http.Handler) http.Handler {
+	cfg := &httpHandlerConfig{
+		clientIP:  false,
+		userAgent: false,
+		maxValues: DefaultMaxLabelValues,
+	}
+
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+
+	counterLabels := []string{"host", "method", "status_code", "target", "tenant", "scheme"}
+
+	if cfg.clientIP {
+		counterLabels = append(counterLabels, "client_ip")
+	}
+
+	if cfg.userAgent {
+		counterLabels = append(counterLabels, "user_agent")
+	}
+
 	var (
-		requestCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
-			Namespace:   "",
-			Subsystem:   "",
-			Name:        "requests_total",
-			Help:        "measures the number of concurrent HTTP requests that are currently in-flight",
-			ConstLabels: nil,
-		},
-			[]string{"host", "method", "status_code", "target", "client_ip", "scheme", "user_agent"})
-		requestDurationVec = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		histogramLabels = []string{"host", "method", "status_code", "target", "tenant"}
+
+		droppedCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace:   "",
 			Subsystem:   "",
-			Name:        "request_duration_seconds",
-			Help:        "measures the duration of the inbound HTTP request",
+			Name:        "label_values_dropped_total",
+			Help:        "measures the number of observations which label values were folded because of the limit",
 			ConstLabels: nil,
-			Buckets:     prometheus.DefBuckets,
 		},
-			[]string{"host", "method", "status_code", "target"})
+			[]string{"metric"})
+
+		// The method and the host are sent by the client as well as the others.
+		folded = []string{"host", "method", "target", "client_ip", "user_agent"}
+
+		metrics = &httpMetrics{
+			cfg:     cfg,
+			tenants: tenants,
+
+			inFlightGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+				Namespace:   "",
+				Subsystem:   "",
+				Name:        "requests_in_flight",
+				Help:        "measures the number of concurrent HTTP requests that are currently in-flight",
+				ConstLabels: nil,
+			}),
+			requestCounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace:   "",
+				Subsystem:   "",
+				Name:        "requests_total",
+				Help:        "measures the number of the handled HTTP requests",
+				ConstLabels: nil,
+			},
+				counterLabels),
+			requestDurationVec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace:   "",
+				Subsystem:   "",
+				Name:        "request_duration_seconds",
+				Help:        "measures the duration of the inbound HTTP request",
+				ConstLabels: nil,
+				Buckets:     prometheus.DefBuckets,
+			},
+				histogramLabels),
+			requestSizeVec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace:   "",
+				Subsystem:   "",
+				Name:        "request_size_bytes",
+				Help:        "measures the size of the inbound HTTP request body which was read by the handler",
+				ConstLabels: nil,
+				Buckets:     DefaultSizeBuckets,
+			},
+				histogramLabels),
+			responseSizeVec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace:   "",
+				Subsystem:   "",
+				Name:        "response_size_bytes",
+				Help:        "measures the size of the HTTP response body",
+				ConstLabels: nil,
+				Buckets:     DefaultSizeBuckets,
+			},
+				histogramLabels),
+
+			requestLimiter: NewLabelLimiter(cfg.maxValues, droppedCounterVec.WithLabelValues("requests_total"),
+				folded...),
+			durationLimiter: NewLabelLimiter(cfg.maxValues,
+				droppedCounterVec.WithLabelValues("request_duration_seconds"), folded...),
+			requestSizeLimiter: NewLabelLimiter(cfg.maxValues,
+				droppedCounterVec.WithLabelValues("request_size_bytes"), folded...),
+			responseSizeLimiter: NewLabelLimiter(cfg.maxValues,
+				droppedCounterVec.WithLabelValues("response_size_bytes"), folded...),
+		}
 	)
 
-	registry.MustRegister(requestCounterVec, requestDurationVec)
+	registry.MustRegister(metrics.inFlightGauge, metrics.requestCounterVec, metrics.requestDurationVec,
+		metrics.requestSizeVec, metrics.responseSizeVec, droppedCounterVec)
 
-	return httpHandler(requestCounterVec, requestDurationVec)
+	return metrics.handler
 }
 
-func httpHandler(
-	counter *prometheus.CounterVec,
-	histogram *prometheus.HistogramVec,
-) func(next http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-			resp := &response{
-				wrapped: writer,
+func (m *httpMetrics) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		resp := &response{
+			wrapped: writer,
+
+			statusCode: http.StatusOK,
+			size:       0,
+		}
+
+		var reqBody *body
+
+		if request.Body != nil {
+			reqBody = &body{
+				wrapped: request.Body,
 
-				statusCode: http.StatusOK,
+				size: 0,
 			}
 
-			userInfo := request.URL.User
-			request.URL.User = nil
+			request.Body = reqBody
+		}
 
-			target := request.URL.Path
+		clientIP := takeClientIP(request)
 
-			request.URL.User = userInfo
+		m.inFlightGauge.Inc()
 
-			clientIP, _, _ := net.SplitHostPort(request.RemoteAddr)
+		_, _, elapsed := trackOfTime(func() {
+			defer m.inFlightGauge.Dec()
 
-			_, _, elapsed := trackOfTime(func() {
-				next.ServeHTTP(resp, request)
-			})
+			next.ServeHTTP(resp, request)
+		})
+
+		var (
+			ctx = request.Context()
 
-			host, method, scheme, statusCode, ua := request.Host, request.Method, takeHTTPScheme(request),
-				strconv.Itoa(resp.statusCode), request.UserAgent()
+			host, method, statusCode = request.Host, request.Method, strconv.Itoa(resp.statusCode)
+			target, tenant           = routePattern(request), m.tenants.Label(ctx)
+		)
 
-			labels := prometheus.Labels{
+		histogramLabels := func() prometheus.Labels {
+			return prometheus.Labels{
 				"host":        host,
 				"method":      method,
 				"status_code": statusCode,
 				"target":      target,
+				"tenant":      tenant,
 			}
+		}
 
-			histogram.
-				With(labels).
-				Observe(elapsed.Seconds())
+		observeWithExemplar(ctx, m.requestDurationVec.With(m.durationLimiter.Limit(histogramLabels())),
+			elapsed.Seconds())
 
-			labels["client_ip"], labels["scheme"], labels["user_agent"] = clientIP, scheme, ua
+		if reqBody != nil {
+			m.requestSizeVec.
+				With(m.requestSizeLimiter.Limit(histogramLabels())).
+				Observe(float64(reqBody.size))
+		}
 
-			counter.
-				With(labels).
-				Inc()
-		})
+		m.responseSizeVec.
+			With(m.responseSizeLimiter.Limit(histogramLabels())).
+			Observe(float64(resp.size))
+
+		labels := histogramLabels()
+		labels["scheme"] = takeHTTPScheme(request)
+
+		if m.cfg.clientIP {
+			labels["client_ip"] = clientIP
+		}
+
+		if m.cfg.userAgent {
+			labels["user_agent"] = request.UserAgent()
+		}
+
+		m.requestCounterVec.
+			With(m.requestLimiter.Limit(labels)).
+			Inc()
+	})
+}
+
+// routePattern returns the pattern of the route which has handled the request. The routing is finished
+// after the request is served, so the pattern is not available before.
+func routePattern(request *http.Request) string {
+	routeContext := chi.RouteContext(request.Context())
+	if routeContext == nil {
+		return UnmatchedRouteLabelValue
 	}
+
+	// The mount pattern and the root pattern of the sub-router are joined with the double slash.
+	if pattern := routeContext.RoutePattern(); pattern != "" {
+		return strings.ReplaceAll(pattern, "//", "/")
+	}
+
+	return UnmatchedRouteLabelValue
+}
+
+// takeClientIP returns the host of the remote address. The address has no port when it was replaced with
+// the forwarded one.
+func takeClientIP(request *http.Request) string {
+	if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
+		return host
+	}
+
+	return request.RemoteAddr
 }
 
 func takeHTTPScheme(request *http.Request) string {
@@ -0,0 +1,41 @@
+package prometheus
+
+// HTTPHandlerOption represents an option for configure HTTP handler metrics.
+type HTTPHandlerOption interface {
+	apply(handler *httpHandlerConfig)
+}
+
+type httpHandlerOptionFunc func(handler *httpHandlerConfig)
+
+func (fn httpHandlerOptionFunc) apply(handler *httpHandlerConfig) {
+	fn(handler)
+}
+
+type httpHandlerConfig struct {
+	clientIP  bool
+	userAgent bool
+	maxValues int
+}
+
+// WithClientIPLabel adds the client IP label to the requests counter. Every client creates the new time series,
+// so it should be enabled only when the clients are known.
+func WithClientIPLabel() HTTPHandlerOption {
+	return httpHandlerOptionFunc(func(handler *httpHandlerConfig) {
+		handler.clientIP = true
+	})
+}
+
+// WithUserAgentLabel adds the user agent label to the requests counter. The value is controlled by the clients,
+// so it should be enabled only when the clients are known.
+func WithUserAgentLabel() HTTPHandlerOption {
+	return httpHandlerOptionFunc(func(handler *httpHandlerConfig) {
+		handler.userAgent = true
+	})
+}
+
+// WithMaxLabelValues sets up the maximum number of distinct label combinations per metric.
+func WithMaxLabelValues(maxValues int) HTTPHandlerOption {
+	return httpHandlerOptionFunc(func(handler *httpHandlerConfig) {
+		handler.maxValues = maxValues
+	})
+}
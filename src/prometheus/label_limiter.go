@@ -0,0 +1,98 @@
+package prometheus
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OtherLabelValue is the label value which replaces the values over the limit.
+const OtherLabelValue = "__other__"
+
+// DefaultMaxLabelValues is the default number of distinct label combinations which are reported per metric.
+const DefaultMaxLabelValues = 1000
+
+// LabelLimiter caps the number of distinct label combinations of the single metric. The combinations over
+// the limit have their unbounded labels folded into OtherLabelValue, so the number of time series does not grow
+// with the number of paths, clients or any other values which are controlled by the callers.
+type LabelLimiter struct {
+	mu sync.RWMutex
+
+	maxValues int
+	folded    []string
+	values    map[string]struct{}
+
+	dropped prometheus.Counter
+}
+
+// NewLabelLimiter returns a new instance of LabelLimiter. The folded are the names of the labels which values are
+// replaced when the limit is reached, the dropped counts the observations with the replaced values.
+func NewLabelLimiter(maxValues int, dropped prometheus.Counter, folded ...string) *LabelLimiter {
+	return &LabelLimiter{
+		mu: sync.RWMutex{},
+
+		maxValues: maxValues,
+		folded:    folded,
+		values:    make(map[string]struct{}),
+
+		dropped: dropped,
+	}
+}
+
+// Limit returns the labels to observe. The first combinations up to the limit are reported as is.
+func (l *LabelLimiter) Limit(labels prometheus.Labels) prometheus.Labels {
+	key := labelsKey(labels)
+
+	l.mu.RLock()
+	_, known := l.values[key]
+	l.mu.RUnlock()
+
+	if known {
+		return labels
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, known = l.values[key]; known {
+		return labels
+	}
+
+	if len(l.values) < l.maxValues {
+		l.values[key] = struct{}{}
+
+		return labels
+	}
+
+	l.dropped.Inc()
+
+	for _, name := range l.folded {
+		if _, ok := labels[name]; ok {
+			labels[name] = OtherLabelValue
+		}
+	}
+
+	return labels
+}
+
+func labelsKey(labels prometheus.Labels) string {
+	names := make([]string, 0, len(labels))
+
+	for name := range labels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var key strings.Builder
+
+	for _, name := range names {
+		// The separator could not be a part of the valid label value in UTF-8.
+		_, _ = key.WriteString(labels[name])
+		_ = key.WriteByte(0xff) // nolint:gomnd
+	}
+
+	return key.String()
+}
@@ -0,0 +1,66 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/prometheus"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+func TestLabelLimiter_Limit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		registry = prom.NewRegistry()
+		dropped  = prom.NewCounter(prom.CounterOpts{ // nolint:exhaustivestruct
+			Name: "dropped_label_values_total",
+		})
+		limiter = prometheus.NewLabelLimiter(2, dropped, "target", "client_ip")
+	)
+
+	registry.MustRegister(dropped)
+
+	for _, tc := range []struct {
+		labels   prom.Labels
+		expected prom.Labels
+	}{
+		{
+			labels:   prom.Labels{"method": "GET", "target": "/users", "client_ip": "10.0.0.1"},
+			expected: prom.Labels{"method": "GET", "target": "/users", "client_ip": "10.0.0.1"},
+		},
+		{
+			labels:   prom.Labels{"method": "GET", "target": "/accounts", "client_ip": "10.0.0.1"},
+			expected: prom.Labels{"method": "GET", "target": "/accounts", "client_ip": "10.0.0.1"},
+		},
+		// The combination over the limit has only the unbounded labels folded.
+		{
+			labels: prom.Labels{"method": "POST", "target": "/users", "client_ip": "10.0.0.2"},
+			expected: prom.Labels{
+				"method": "POST", "target": prometheus.OtherLabelValue, "client_ip": prometheus.OtherLabelValue,
+			},
+		},
+		// The known combination is still reported as is after the limit is reached.
+		{
+			labels:   prom.Labels{"method": "GET", "target": "/users", "client_ip": "10.0.0.1"},
+			expected: prom.Labels{"method": "GET", "target": "/users", "client_ip": "10.0.0.1"},
+		},
+		{
+			labels: prom.Labels{"method": "GET", "target": "/admin", "client_ip": "10.0.0.1"},
+			expected: prom.Labels{
+				"method": "GET", "target": prometheus.OtherLabelValue, "client_ip": prometheus.OtherLabelValue,
+			},
+		},
+	} {
+		actual := limiter.Limit(tc.labels)
+
+		for name, value := range tc.expected {
+			if actual[name] != value {
+				t.Errorf("unexpected label %s %q, expected %q", name, actual[name], value)
+			}
+		}
+	}
+
+	if value := counterValue(t, registry, "dropped_label_values_total", nil); value != 2 {
+		t.Errorf("unexpected dropped %v", value)
+	}
+}
@@ -3,7 +3,6 @@ package prometheus
 import (
 	"context"
 	"database/sql"
-	"strings"
 
 	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
 	"github.com/prometheus/client_golang/prometheus"
@@ -13,6 +12,7 @@ var _ percona.PrepareTxBeginner = (*PrepareTxBeginner)(nil)
 
 type PrepareTxBeginner struct {
 	wrapped percona.PrepareTxBeginner
+	tenants *TenantLabeler
 
 	errorsCounterVec    *prometheus.CounterVec
 	rollbacksCounterVec *prometheus.CounterVec
@@ -20,9 +20,14 @@ type PrepareTxBeginner struct {
 }
 
 // NewPrepareTxBeginner returns a new instance of PrepareTxBeginner.
-func NewPrepareTxBeginner(svc percona.PrepareTxBeginner, registerer prometheus.Registerer) *PrepareTxBeginner {
+func NewPrepareTxBeginner(
+	svc percona.PrepareTxBeginner,
+	registerer prometheus.Registerer,
+	tenants *TenantLabeler,
+) *PrepareTxBeginner {
 	wrapper := &PrepareTxBeginner{
 		wrapped: svc,
+		tenants: tenants,
 
 		errorsCounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace:   "",
@@ -30,14 +35,14 @@ func NewPrepareTxBeginner(svc percona.PrepareTxBeginner, registerer prometheus.R
 			Name:        "query_errors_total",
 			Help:        "measures the number of query errors",
 			ConstLabels: nil,
-		}, []string{"operation"}),
+		}, []string{"operation", "query", "tenant"}),
 		rollbacksCounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace:   "",
 			Subsystem:   "",
 			Name:        "tx_rollbacks_total",
 			Help:        "measures the number of transaction rollbacks",
 			ConstLabels: nil,
-		}, nil),
+		}, []string{"tenant"}),
 		queryDurationVec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace:   "",
 			Subsystem:   "",
@@ -45,7 +50,7 @@ func NewPrepareTxBeginner(svc percona.PrepareTxBeginner, registerer prometheus.R
 			Help:        "measures the duration of the SQL query execution",
 			ConstLabels: nil,
 			Buckets:     prometheus.DefBuckets,
-		}, []string{"operation"}),
+		}, []string{"operation", "query", "tenant"}),
 	}
 
 	registerer.MustRegister(wrapper.errorsCounterVec, wrapper.rollbacksCounterVec, wrapper.queryDurationVec)
@@ -55,11 +60,15 @@ func NewPrepareTxBeginner(svc percona.PrepareTxBeginner, registerer prometheus.R
 
 // PrepareContext creates a prepared statement for later queries or executions.
 func (svc *PrepareTxBeginner) PrepareContext(ctx context.Context, query string) (percona.Stmt, error) {
+	fingerprint := percona.FingerprintContext(ctx, query)
+
 	perconaStmt, err := svc.wrapped.PrepareContext(ctx, query)
 	if err != nil {
 		svc.errorsCounterVec.
 			With(prometheus.Labels{
 				"operation": "PREPARE",
+				"query":     fingerprint.Name,
+				"tenant":    svc.tenants.Label(ctx),
 			}).
 			Inc()
 
@@ -71,8 +80,10 @@ func (svc *PrepareTxBeginner) PrepareContext(ctx context.Context, query string)
 
 		errorsCounterVec: svc.errorsCounterVec,
 		queryDurationVec: svc.queryDurationVec,
+		tenants:          svc.tenants,
 
-		operation: strings.ToUpper(query[:strings.IndexByte(query, ' ')]),
+		operation: fingerprint.Operation,
+		query:     fingerprint.Name,
 	}, nil
 }
 
@@ -83,6 +94,8 @@ func (svc *PrepareTxBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions)
 		svc.errorsCounterVec.
 			With(prometheus.Labels{
 				"operation": "BEGIN",
+				"query":     "begin",
+				"tenant":    svc.tenants.Label(ctx),
 			}).
 			Inc()
 
@@ -91,6 +104,7 @@ func (svc *PrepareTxBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions)
 
 	return &tx{
 		wrapped: perconaTx,
+		tenants: svc.tenants,
 
 		errorsCounterVec:    svc.errorsCounterVec,
 		rollbacksCounterVec: svc.rollbacksCounterVec,
@@ -0,0 +1,132 @@
+package prometheus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/otelexampletest"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona/perconatest"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/prometheus"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPrepareTxBeginner_Conflict(t *testing.T) {
+	t.Parallel()
+
+	registry := prom.NewRegistry()
+
+	createConflictingUserAccount(t, prometheus.NewPrepareTxBeginner(connectClient(t, newDuplicateEntryScript(t)),
+		registry, prometheus.NewTenantLabeler(1)))
+
+	for _, tc := range []struct {
+		name   string
+		labels map[string]string
+	}{
+		{
+			name:   "query_errors_total",
+			labels: map[string]string{"operation": "INSERT", "query": "create_user_account", "tenant": "tenant"},
+		},
+		{
+			name:   "tx_rollbacks_total",
+			labels: map[string]string{"tenant": "tenant"},
+		},
+	} {
+		if value := counterValue(t, registry, tc.name, tc.labels); value != 1 {
+			t.Errorf("%s%v: expected 1, got %v", tc.name, tc.labels, value)
+		}
+	}
+}
+
+// counterValue returns the value of the counter with the given labels.
+func counterValue(t *testing.T, gatherer prom.Gatherer, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			matched := 0
+
+			for _, pair := range metric.GetLabel() {
+				if value, ok := labels[pair.GetName()]; ok && value == pair.GetValue() {
+					matched++
+				}
+			}
+
+			if matched == len(labels) {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+// connectClient returns the client which follows the script.
+func connectClient(t *testing.T, script *perconatest.Script) *percona.Client {
+	t.Helper()
+
+	client := percona.NewClient(script.DSN(), percona.WithDriverName(perconatest.DriverName))
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := client.Close(); err != nil {
+			t.Errorf("close: %v", err)
+		}
+	})
+
+	return client
+}
+
+// createConflictingUserAccount creates the user account which violates the unique key of the username.
+func createConflictingUserAccount(t *testing.T, prepareTxBeginner percona.PrepareTxBeginner) {
+	t.Helper()
+
+	var (
+		ctx = otelexample.NewContextWithTenantID(context.Background(), "tenant")
+		svc = percona.NewUserAccountService(prepareTxBeginner, otelexampletest.NewSequenceIdentifierGenerator("id-"),
+			otelexampletest.NewStepTimer(time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), time.Second))
+		ua = &otelexample.UserAccount{
+			ID:       otelexample.EmptyID,
+			Username: "alice",
+			User: &otelexample.User{
+				ID:        otelexample.EmptyID,
+				FirstName: "Alice",
+				LastName:  "Liddell",
+				CreatedAt: time.Time{},
+			},
+			CreatedAt: time.Time{},
+		}
+	)
+
+	err := svc.CreateUserAccount(ctx, ua)
+	if code := otelexample.ErrorCodeFromError(err); code != otelexample.ErrorCodeConflict {
+		t.Fatalf("unexpected error code %q: %v", code, err)
+	}
+}
+
+// newDuplicateEntryScript returns the script of the user account creation which fails with the duplicate entry.
+func newDuplicateEntryScript(t *testing.T) *perconatest.Script {
+	t.Helper()
+
+	script := perconatest.NewScript(t)
+	script.ExpectBegin()
+	script.ExpectQuery(`^SELECT EXISTS`).WillReturnRows(perconatest.NewRows("is_exists").AddRow(int64(0)))
+	script.ExpectExec(`^INSERT INTO users `)
+	script.ExpectExec(`^INSERT INTO user_accounts `).WillReturnError(perconatest.MySQLError(1062, "Duplicate entry"))
+	script.ExpectRollback()
+
+	return script
+}
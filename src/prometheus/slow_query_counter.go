@@ -0,0 +1,40 @@
+package prometheus
+
+import (
+	"context"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SlowQueryCounter counts queries which execution took longer than the threshold.
+type SlowQueryCounter struct {
+	counterVec *prometheus.CounterVec
+}
+
+// NewSlowQueryCounter returns a new instance of SlowQueryCounter.
+func NewSlowQueryCounter(registerer prometheus.Registerer) *SlowQueryCounter {
+	counter := &SlowQueryCounter{
+		counterVec: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "",
+			Subsystem:   "",
+			Name:        "slow_queries_total",
+			Help:        "measures the number of queries which execution took longer than the threshold",
+			ConstLabels: nil,
+		}, []string{"operation", "query"}),
+	}
+
+	registerer.MustRegister(counter.counterVec)
+
+	return counter
+}
+
+// HandleSlowQuery increments the counter of the query. It could be used as percona.SlowQueryHandler.
+func (c *SlowQueryCounter) HandleSlowQuery(_ context.Context, query percona.SlowQuery) {
+	c.counterVec.
+		With(prometheus.Labels{
+			"operation": query.Fingerprint.Operation,
+			"query":     query.Fingerprint.Name,
+		}).
+		Inc()
+}
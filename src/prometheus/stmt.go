@@ -16,8 +16,10 @@ type stmt struct {
 
 	errorsCounterVec *prometheus.CounterVec
 	queryDurationVec *prometheus.HistogramVec
+	tenants          *TenantLabeler
 
 	operation string
+	query     string
 }
 
 // ExecContext executes a prepared statement with the given arguments and
@@ -34,11 +36,11 @@ func (stmt *stmt) ExecContext(ctx context.Context, args ...any) (sql.Result, err
 
 	labels := prometheus.Labels{
 		"operation": stmt.operation,
+		"query":     stmt.query,
+		"tenant":    stmt.tenants.Label(ctx),
 	}
 
-	stmt.queryDurationVec.
-		With(labels).
-		Observe(elapsed.Seconds())
+	observeWithExemplar(ctx, stmt.queryDurationVec.With(labels), elapsed.Seconds())
 
 	if err != nil {
 		stmt.errorsCounterVec.
@@ -52,8 +54,8 @@ func (stmt *stmt) ExecContext(ctx context.Context, args ...any) (sql.Result, err
 }
 
 // QueryRowContext executes a prepared query statement with the given arguments.
-func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
-	var row *sql.Row
+func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) percona.Row {
+	var row percona.Row
 
 	_, _, elapsed := trackOfTime(func() {
 		row = stmt.wrapped.QueryRowContext(ctx, args...)
@@ -61,11 +63,11 @@ func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
 
 	labels := prometheus.Labels{
 		"operation": stmt.operation,
+		"query":     stmt.query,
+		"tenant":    stmt.tenants.Label(ctx),
 	}
 
-	stmt.queryDurationVec.
-		With(labels).
-		Observe(elapsed.Seconds())
+	observeWithExemplar(ctx, stmt.queryDurationVec.With(labels), elapsed.Seconds())
 
 	if err := row.Err(); err != nil {
 		stmt.errorsCounterVec.
@@ -90,11 +92,11 @@ func (stmt *stmt) QueryContext(ctx context.Context, args ...any) (*sql.Rows, err
 
 	labels := prometheus.Labels{
 		"operation": stmt.operation,
+		"query":     stmt.query,
+		"tenant":    stmt.tenants.Label(ctx),
 	}
 
-	stmt.queryDurationVec.
-		With(labels).
-		Observe(elapsed.Seconds())
+	observeWithExemplar(ctx, stmt.queryDurationVec.With(labels), elapsed.Seconds())
 
 	if err != nil {
 		stmt.errorsCounterVec.
@@ -0,0 +1,67 @@
+package prometheus
+
+import (
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StmtCacheStatsProvider represents a service that can provide the prepared statement cache statistics.
+type StmtCacheStatsProvider interface {
+	// StmtCacheStats returns the prepared statement cache statistics.
+	StmtCacheStats() percona.StmtCacheStats
+}
+
+var _ prometheus.Collector = (*StmtCacheCollector)(nil)
+
+// StmtCacheCollector collects the prepared statement cache metrics.
+type StmtCacheCollector struct {
+	provider StmtCacheStatsProvider
+
+	sizeDesc      *prometheus.Desc
+	capacityDesc  *prometheus.Desc
+	hitsDesc      *prometheus.Desc
+	missesDesc    *prometheus.Desc
+	evictionsDesc *prometheus.Desc
+}
+
+// NewStmtCacheCollector returns a new instance of StmtCacheCollector.
+func NewStmtCacheCollector(provider StmtCacheStatsProvider) *StmtCacheCollector {
+	return &StmtCacheCollector{
+		provider: provider,
+
+		sizeDesc: prometheus.NewDesc("stmt_cache_size",
+			"measures the number of prepared statements are currently cached", nil, nil),
+		capacityDesc: prometheus.NewDesc("stmt_cache_capacity",
+			"measures the maximum number of prepared statements could be cached", nil, nil),
+		hitsDesc: prometheus.NewDesc("stmt_cache_hits_total",
+			"measures the number of prepared statement cache hits", nil, nil),
+		missesDesc: prometheus.NewDesc("stmt_cache_misses_total",
+			"measures the number of prepared statement cache misses", nil, nil),
+		evictionsDesc: prometheus.NewDesc("stmt_cache_evictions_total",
+			"measures the number of prepared statements were evicted from the cache", nil, nil),
+	}
+}
+
+// Describe sends the super-set of all possible descriptors of metrics
+// collected by this Collector to the provided channel and returns once
+// the last descriptor has been sent.
+func (c *StmtCacheCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.sizeDesc
+	descs <- c.capacityDesc
+	descs <- c.hitsDesc
+	descs <- c.missesDesc
+	descs <- c.evictionsDesc
+}
+
+// Collect is called by the Prometheus registry when collecting
+// metrics. The implementation sends each collected metric via the
+// provided channel and returns once the last metric has been sent.
+func (c *StmtCacheCollector) Collect(metrics chan<- prometheus.Metric) {
+	stats := c.provider.StmtCacheStats()
+
+	metrics <- prometheus.MustNewConstMetric(c.sizeDesc, prometheus.GaugeValue, float64(stats.Size))
+	metrics <- prometheus.MustNewConstMetric(c.capacityDesc, prometheus.GaugeValue, float64(stats.Capacity))
+	metrics <- prometheus.MustNewConstMetric(c.hitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	metrics <- prometheus.MustNewConstMetric(c.missesDesc, prometheus.CounterValue, float64(stats.Misses))
+	metrics <- prometheus.MustNewConstMetric(c.evictionsDesc, prometheus.CounterValue, float64(stats.Evictions))
+}
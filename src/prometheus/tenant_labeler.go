@@ -0,0 +1,65 @@
+package prometheus
+
+import (
+	"context"
+	"sync"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+)
+
+// OtherTenantLabelValue is the label value for tenants over the limit.
+const OtherTenantLabelValue = OtherLabelValue
+
+// DefaultMaxTenantLabelValues is the default number of distinct tenants which are reported as the label values.
+const DefaultMaxTenantLabelValues = 100
+
+// TenantLabeler returns the tenant label value. The number of distinct values is capped, so the number of time
+// series does not grow with the number of tenants.
+type TenantLabeler struct {
+	mu sync.RWMutex
+
+	maxValues int
+	values    map[otelexample.TenantID]struct{}
+}
+
+// NewTenantLabeler returns a new instance of TenantLabeler.
+func NewTenantLabeler(maxValues int) *TenantLabeler {
+	return &TenantLabeler{
+		mu: sync.RWMutex{},
+
+		maxValues: maxValues,
+		values:    make(map[otelexample.TenantID]struct{}, maxValues),
+	}
+}
+
+// Label returns the label value for the tenant from the context. The first tenants up to the limit are reported
+// as is and the others are folded into OtherTenantLabelValue.
+func (l *TenantLabeler) Label(ctx context.Context) string {
+	id, ok := otelexample.TenantIDFromContext(ctx)
+	if !ok || l == nil {
+		return ""
+	}
+
+	l.mu.RLock()
+	_, known := l.values[id]
+	l.mu.RUnlock()
+
+	if known {
+		return id.String()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, known = l.values[id]; known {
+		return id.String()
+	}
+
+	if len(l.values) >= l.maxValues {
+		return OtherTenantLabelValue
+	}
+
+	l.values[id] = struct{}{}
+
+	return id.String()
+}
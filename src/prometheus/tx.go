@@ -2,7 +2,6 @@ package prometheus
 
 import (
 	"context"
-	"strings"
 
 	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
 	"github.com/prometheus/client_golang/prometheus"
@@ -13,6 +12,7 @@ var _ percona.Tx = (*tx)(nil)
 // tx is an in-progress database transaction.
 type tx struct {
 	wrapped percona.Tx
+	tenants *TenantLabeler
 
 	errorsCounterVec    *prometheus.CounterVec
 	rollbacksCounterVec *prometheus.CounterVec
@@ -21,11 +21,15 @@ type tx struct {
 
 // PrepareContext creates a prepared statement for later queries or executions.
 func (tx *tx) PrepareContext(ctx context.Context, query string) (percona.Stmt, error) {
+	fingerprint := percona.FingerprintContext(ctx, query)
+
 	perconaStmt, err := tx.wrapped.PrepareContext(ctx, query)
 	if err != nil {
 		tx.errorsCounterVec.
 			With(prometheus.Labels{
 				"operation": "PREPARE",
+				"query":     fingerprint.Name,
+				"tenant":    tx.tenants.Label(ctx),
 			}).
 			Inc()
 
@@ -37,17 +41,21 @@ func (tx *tx) PrepareContext(ctx context.Context, query string) (percona.Stmt, e
 
 		errorsCounterVec: tx.errorsCounterVec,
 		queryDurationVec: tx.queryDurationVec,
+		tenants:          tx.tenants,
 
-		operation: strings.ToUpper(query[:strings.IndexByte(query, ' ')]),
+		operation: fingerprint.Operation,
+		query:     fingerprint.Name,
 	}, nil
 }
 
 // Commit commits the transaction.
-func (tx *tx) Commit() error {
-	if err := tx.wrapped.Commit(); err != nil {
+func (tx *tx) Commit(ctx context.Context) error {
+	if err := tx.wrapped.Commit(ctx); err != nil {
 		tx.errorsCounterVec.
 			With(prometheus.Labels{
 				"operation": "COMMIT",
+				"query":     "commit",
+				"tenant":    tx.tenants.Label(ctx),
 			}).
 			Inc()
 
@@ -58,15 +66,19 @@ func (tx *tx) Commit() error {
 }
 
 // Rollback aborts the transaction.
-func (tx *tx) Rollback() error {
+func (tx *tx) Rollback(ctx context.Context) error {
 	tx.rollbacksCounterVec.
-		With(nil).
+		With(prometheus.Labels{
+			"tenant": tx.tenants.Label(ctx),
+		}).
 		Inc()
 
-	if err := tx.wrapped.Rollback(); err != nil {
+	if err := tx.wrapped.Rollback(ctx); err != nil {
 		tx.errorsCounterVec.
 			With(prometheus.Labels{
 				"operation": "ROLLBACK",
+				"query":     "rollback",
+				"tenant":    tx.tenants.Label(ctx),
 			}).
 			Inc()
 
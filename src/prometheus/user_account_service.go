@@ -0,0 +1,140 @@
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ otelexample.UserAccountService = (*UserAccountService)(nil)
+
+// UserAccountService measures the rate, the errors and the duration of the user account operations.
+type UserAccountService struct {
+	wrapped otelexample.UserAccountService
+	tenants *TenantLabeler
+
+	operationsCounterVec *prometheus.CounterVec
+	errorsCounterVec     *prometheus.CounterVec
+	durationVec          *prometheus.HistogramVec
+}
+
+// NewUserAccountService returns a new instance of UserAccountService.
+func NewUserAccountService(
+	svc otelexample.UserAccountService,
+	registerer prometheus.Registerer,
+	tenants *TenantLabeler,
+) *UserAccountService {
+	wrapper := &UserAccountService{
+		wrapped: svc,
+		tenants: tenants,
+
+		operationsCounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "",
+			Subsystem:   "",
+			Name:        "operations_total",
+			Help:        "measures the number of the service operations",
+			ConstLabels: nil,
+		}, []string{"method", "tenant"}),
+		errorsCounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "",
+			Subsystem:   "",
+			Name:        "operation_errors_total",
+			Help:        "measures the number of the failed service operations by the error code",
+			ConstLabels: nil,
+		}, []string{"method", "code", "tenant"}),
+		durationVec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   "",
+			Subsystem:   "",
+			Name:        "operation_duration_seconds",
+			Help:        "measures the duration of the service operation",
+			ConstLabels: nil,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"method", "tenant"}),
+	}
+
+	registerer.MustRegister(wrapper.operationsCounterVec, wrapper.errorsCounterVec, wrapper.durationVec)
+
+	return wrapper
+}
+
+// CreateUserAccount creates a new user account.
+func (svc *UserAccountService) CreateUserAccount(ctx context.Context, ua *otelexample.UserAccount) error {
+	var err error
+
+	_, _, elapsed := trackOfTime(func() {
+		err = svc.wrapped.CreateUserAccount(ctx, ua)
+	})
+
+	svc.observe(ctx, "CreateUserAccount", elapsed, err)
+
+	return err // nolint:wrapcheck
+}
+
+// FindUserAccounts returns a list of user accounts.
+func (svc *UserAccountService) FindUserAccounts(
+	ctx context.Context,
+	opts otelexample.FindOptions,
+) (
+	*otelexample.FindUserAccountsResult,
+	error,
+) {
+	var (
+		result *otelexample.FindUserAccountsResult
+		err    error
+	)
+
+	_, _, elapsed := trackOfTime(func() {
+		result, err = svc.wrapped.FindUserAccounts(ctx, opts)
+	})
+
+	svc.observe(ctx, "FindUserAccounts", elapsed, err)
+
+	return result, err // nolint:wrapcheck
+}
+
+// FindUserAccountByID returns user account by unique identifier.
+func (svc *UserAccountService) FindUserAccountByID(
+	ctx context.Context,
+	id otelexample.ID,
+) (
+	*otelexample.UserAccount,
+	error,
+) {
+	var (
+		ua  *otelexample.UserAccount
+		err error
+	)
+
+	_, _, elapsed := trackOfTime(func() {
+		ua, err = svc.wrapped.FindUserAccountByID(ctx, id)
+	})
+
+	svc.observe(ctx, "FindUserAccountByID", elapsed, err)
+
+	return ua, err // nolint:wrapcheck
+}
+
+func (svc *UserAccountService) observe(ctx context.Context, method string, elapsed time.Duration, err error) {
+	labels := prometheus.Labels{
+		"method": method,
+		"tenant": svc.tenants.Label(ctx),
+	}
+
+	svc.operationsCounterVec.
+		With(labels).
+		Inc()
+
+	observeWithExemplar(ctx, svc.durationVec.With(labels), elapsed.Seconds())
+
+	if err == nil {
+		return
+	}
+
+	labels["code"] = otelexample.ErrorCodeFromError(err).String()
+
+	svc.errorsCounterVec.
+		With(labels).
+		Inc()
+}
@@ -0,0 +1,71 @@
+package prometheus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/inmem"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/otelexampletest"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/prometheus"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+func TestUserAccountService_ErrorCode(t *testing.T) {
+	t.Parallel()
+
+	var (
+		registry = prom.NewRegistry()
+		ctx      = otelexample.NewContextWithTenantID(context.Background(), "tenant")
+		svc      = prometheus.NewUserAccountService(inmem.NewUserAccountService(
+			otelexampletest.NewSequenceIdentifierGenerator("id-"),
+			otelexampletest.NewStepTimer(time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), time.Second)),
+			registry, prometheus.NewTenantLabeler(1))
+	)
+
+	for i := 0; i < 2; i++ {
+		ua := &otelexample.UserAccount{
+			ID:       otelexample.EmptyID,
+			Username: "alice",
+			User: &otelexample.User{
+				ID:        otelexample.EmptyID,
+				FirstName: "Alice",
+				LastName:  "Liddell",
+				CreatedAt: time.Time{},
+			},
+			CreatedAt: time.Time{},
+		}
+
+		// The second account conflicts with the first one by the username.
+		if err := svc.CreateUserAccount(ctx, ua); (err != nil) != (i == 1) {
+			t.Fatalf("create user account %d: %v", i, err)
+		}
+	}
+
+	if _, err := svc.FindUserAccountByID(ctx, "unknown"); err == nil {
+		t.Fatal("user account is found")
+	}
+
+	for _, tc := range []struct {
+		labels   map[string]string
+		expected float64
+	}{
+		{
+			labels:   map[string]string{"method": "CreateUserAccount", "code": "conflict", "tenant": "tenant"},
+			expected: 1,
+		},
+		{
+			labels:   map[string]string{"method": "FindUserAccountByID", "code": "not_found", "tenant": "tenant"},
+			expected: 1,
+		},
+		{
+			labels:   map[string]string{"code": "internal"},
+			expected: 0,
+		},
+	} {
+		if value := counterValue(t, registry, "operation_errors_total", tc.labels); value != tc.expected {
+			t.Errorf("operation_errors_total%v: expected %v, got %v", tc.labels, tc.expected, value)
+		}
+	}
+}
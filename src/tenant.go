@@ -0,0 +1,35 @@
+package otelexample
+
+import (
+	"context"
+	"fmt"
+)
+
+var _ fmt.Stringer = (*TenantID)(nil)
+
+// TenantID describes the unique identifier of the customer whose data is isolated from others.
+type TenantID string
+
+// The String method is used to print values passed as an operand
+// to any format that accepts a string or to an unformatted printer
+// such as Print.
+func (id TenantID) String() string {
+	return string(id)
+}
+
+// EmptyTenantID is the constant for the tenant identifier with empty value.
+const EmptyTenantID = TenantID("")
+
+type tenantContextKey struct{}
+
+// NewContextWithTenantID returns a copy of parent context which carries the tenant identifier.
+func NewContextWithTenantID(ctx context.Context, id TenantID) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, id)
+}
+
+// TenantIDFromContext returns the tenant identifier carried by the context.
+func TenantIDFromContext(ctx context.Context) (TenantID, bool) {
+	id, ok := ctx.Value(tenantContextKey{}).(TenantID)
+
+	return id, ok && id != EmptyTenantID
+}
@@ -10,10 +10,10 @@ type User struct {
 	ID ID
 
 	// FirstName is the user first name.
-	FirstName string
+	FirstName string `log:"sensitive"`
 
 	// LastName is the user last name.
-	LastName string
+	LastName string `log:"sensitive"`
 
 	// CreatedAt is the time when user was created.
 	CreatedAt time.Time
@@ -11,7 +11,7 @@ type UserAccount struct {
 	ID ID
 
 	// Username is the user account name.
-	Username string
+	Username string `log:"sensitive"`
 
 	// User is the person who owned the account.
 	User *User
@@ -35,7 +35,8 @@ type UserAccountService interface {
 	// CreateUserAccount creates a new user account.
 	CreateUserAccount(ctx context.Context, ua *UserAccount) error
 
-	// FindUserAccounts returns a list of user accounts.
+	// FindUserAccounts returns a list of user accounts ordered by the creation
+	// time and then by the identifier.
 	FindUserAccounts(ctx context.Context, opts FindOptions) (*FindUserAccountsResult, error)
 
 	// FindUserAccountByID returns user account by unique identifier.
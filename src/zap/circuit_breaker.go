@@ -0,0 +1,23 @@
+package zap
+
+import (
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"go.uber.org/zap"
+)
+
+// CircuitBreakerStateChangeHandler returns the handler which logs the circuit breaker state transitions.
+func CircuitBreakerStateChangeHandler(logger *zap.Logger) percona.CircuitBreakerStateChangeHandler {
+	return func(from, to percona.CircuitBreakerState) {
+		ff := []zap.Field{
+			zap.Stringer("from", from), zap.Stringer("to", to),
+		}
+
+		if to == percona.CircuitBreakerOpen {
+			logger.Error("circuit breaker state changed", ff...)
+
+			return
+		}
+
+		logger.Warn("circuit breaker state changed", ff...)
+	}
+}
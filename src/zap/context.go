@@ -0,0 +1,76 @@
+package zap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type loggerContextKey struct{}
+
+// NewContextWithFields returns a copy of parent context which carries the fields of the request. The fields are
+// added to every entry which is logged within the context, so the entries of the request could be correlated.
+func NewContextWithFields(ctx context.Context, ff ...zap.Field) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, append(requestFields(ctx), ff...))
+}
+
+// LoggerFromContext returns the logger which is enriched with the fields carried by the context. The logger is
+// cloned with the fields encoded, so the decorators which log on every call check the entry first and pass
+// the fields of loggerFields to it instead.
+func LoggerFromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	return logger.With(loggerFields(ctx)...)
+}
+
+// loggerFields returns the fields carried by the context followed by ff: the fields of the request, the tenant
+// and the identifiers of the current span. The tenant is the principal of the request, because it is the only
+// identity which is authenticated by the server, so there is no separate principal field.
+func loggerFields(ctx context.Context, ff ...zap.Field) []zap.Field {
+	rff := requestFields(ctx)
+
+	fields := make([]zap.Field, 0, len(rff)+len(ff)+3) // nolint:gomnd
+	fields = append(append(fields, rff...), tenantField(ctx))
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = append(fields, zap.Stringer("traceId", spanContext.TraceID()),
+			zap.Stringer("spanId", spanContext.SpanID()))
+	}
+
+	return append(fields, ff...)
+}
+
+// requestFields returns the fields of the request carried by the context.
+func requestFields(ctx context.Context) []zap.Field {
+	ff, _ := ctx.Value(loggerContextKey{}).([]zap.Field)
+
+	return ff[:len(ff):len(ff)]
+}
+
+var _ fmt.Stringer = (*routePattern)(nil)
+
+// routePattern is the pattern of the route which handles the request. The routing is in progress when the
+// fields of the request are collected, so the pattern is taken when the logger is enriched with them.
+type routePattern struct {
+	routeContext *chi.Context
+}
+
+// The String method is used to print values passed as an operand
+// to any format that accepts a string or to an unformatted printer
+// such as Print.
+func (pattern routePattern) String() string {
+	// The mount pattern and the root pattern of the sub-router are joined with the double slash.
+	return strings.ReplaceAll(pattern.routeContext.RoutePattern(), "//", "/")
+}
+
+// routeField returns the field with the route pattern or skips it when the request is not routed by chi.
+func routeField(ctx context.Context) zap.Field {
+	routeContext := chi.RouteContext(ctx)
+	if routeContext == nil {
+		return zap.Skip()
+	}
+
+	return zap.Stringer("route", routePattern{routeContext: routeContext})
+}
@@ -49,28 +49,34 @@ func (resp *response) WriteHeader(statusCode int) {
 	resp.wrapped.WriteHeader(resp.statusCode)
 }
 
-func HTTPHandler(logger *zap.Logger) func(next http.Handler) http.Handler {
+func HTTPHandler(logger *zap.Logger, redactor *Redactor) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-			defer recoverRequestPanic(logger, writer, request)
+			ctx := request.Context()
+			request = request.WithContext(NewContextWithFields(ctx, zap.String("rid", middleware.GetReqID(ctx)),
+				routeField(ctx)))
 
-			logRequest(logger, next, writer, request)
+			defer recoverRequestPanic(logger, redactor, writer, request)
+
+			logRequest(logger, redactor, next, writer, request)
 		})
 	}
 }
 
-func recoverRequestPanic(logger *zap.Logger, writer http.ResponseWriter, request *http.Request) {
+func recoverRequestPanic(logger *zap.Logger, redactor *Redactor, writer http.ResponseWriter, request *http.Request) {
 	panicError := recover()
 	if panicError == nil {
 		return
 	}
 
+	logger = LoggerFromContext(request.Context(), logger)
+
 	err, ok := panicError.(error)
 	if !ok || errors.Is(err, http.ErrAbortHandler) {
 		return
 	}
 
-	dumpedRequest, dumpErr := httputil.DumpRequest(request, false)
+	dumpedRequest, dumpErr := httputil.DumpRequest(redactor.request(request), false)
 	if dumpErr != nil {
 		logger.Error("dump request", zap.Error(dumpErr))
 
@@ -106,7 +112,13 @@ func isBrokenPipe(err error) bool {
 		strings.Contains(strings.ToLower(syscallError.Error()), "connection reset by peer")
 }
 
-func logRequest(logger *zap.Logger, next http.Handler, writer http.ResponseWriter, request *http.Request) {
+func logRequest(
+	logger *zap.Logger,
+	redactor *Redactor,
+	next http.Handler,
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
 	resp := &response{
 		wrapped: writer,
 
@@ -118,8 +130,10 @@ func logRequest(logger *zap.Logger, next http.Handler, writer http.ResponseWrite
 		next.ServeHTTP(resp, request)
 	})
 
+	logger = LoggerFromContext(request.Context(), logger)
+
 	ff := []zap.Field{
-		zap.String("rid", middleware.GetReqID(request.Context())), zap.Int("status", resp.statusCode),
+		zap.Int("status", resp.statusCode),
 		zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
 		zap.String("http-method", request.Method), zap.String("path", request.URL.Path),
 		zap.String("user-agent", request.UserAgent()), zap.String("query", request.URL.RawQuery),
@@ -127,7 +141,7 @@ func logRequest(logger *zap.Logger, next http.Handler, writer http.ResponseWrite
 	}
 
 	if check := logger.Check(zap.DebugLevel, request.URL.Path); check != nil {
-		check.Write(append(ff, zap.Stringer("response", resp.buffer))...)
+		check.Write(append(ff, redactor.JSON("response", resp.buffer.Bytes()))...)
 
 		return
 	}
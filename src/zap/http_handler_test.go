@@ -0,0 +1,55 @@
+package zap_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/zap"
+)
+
+// responseBody is the body with the personal data of the user account.
+const responseBody = `{"id":"account-id","username":"alice","user":{"firstName":"Alice"}}`
+
+func TestHTTPHandler_Redaction(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range redactionPolicies {
+		policy := policy
+
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				logger, logs = newObservedLogger()
+				recorder     = httptest.NewRecorder()
+				handler      = zap.HTTPHandler(logger, zap.NewRedactor(policy, testHashKey))
+			)
+
+			handler(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				_, _ = io.WriteString(writer, responseBody)
+			})).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+			// The client receives the original body, only the logged one is redacted.
+			if body := recorder.Body.String(); body != responseBody {
+				t.Errorf("unexpected response body %s", body)
+			}
+
+			assertNotLogged(t, logs, "alice", "Alice")
+
+			entries := logs.FilterMessage("/users/1").AllUntimed()
+			if len(entries) != 1 {
+				t.Fatalf("unexpected entries %v", logs.AllUntimed())
+			}
+
+			response := objectField(t, entries[0].ContextMap(), "response")
+			if response["id"] != "account-id" {
+				t.Errorf("unexpected account id %v", response["id"])
+			}
+
+			assertRedacted(t, policy, response, "username", "alice")
+			assertRedacted(t, policy, objectField(t, response, "user"), "firstName", "Alice")
+		})
+	}
+}
@@ -31,8 +31,10 @@ func (svc *IdentifierGenerator) GenerateIdentifier(ctx context.Context) otelexam
 		id = svc.wrapped.GenerateIdentifier(ctx)
 	})
 
-	svc.logger.Debug("generate identifier", zap.Stringer("start", start), zap.Stringer("end", end),
-		zap.Stringer("elapsed", elapsed), zap.Stringer("id", id))
+	if entry := svc.logger.Check(zap.DebugLevel, "generate identifier"); entry != nil {
+		entry.Write(loggerFields(ctx, zap.Stringer("start", start), zap.Stringer("end", end),
+			zap.Stringer("elapsed", elapsed), zap.Stringer("id", id))...)
+	}
 
 	return id
 }
@@ -0,0 +1,262 @@
+package zap
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelOverride is the level of the named logger and its descendants.
+type LevelOverride struct {
+	// Logger is the name of the logger, like server.percona.stmt.
+	Logger string `json:"logger"`
+
+	// Level is the minimum enabled level.
+	Level zapcore.Level `json:"level"`
+
+	// ExpiresAt is the time when the level is reverted. It is omitted for the permanent level.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// LevelReport is the state of the logger levels.
+type LevelReport struct {
+	// Level is the level of the loggers which are not overridden.
+	Level zapcore.Level `json:"level"`
+
+	// ExpiresAt is the time when the level is reverted. It is omitted for the permanent level.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// Overrides is the list of levels of the named loggers.
+	Overrides []LevelOverride `json:"overrides"`
+}
+
+// temporaryLevel is the level which is reverted when the timer fires.
+type temporaryLevel struct {
+	level     zapcore.Level
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+var _ zapcore.LevelEnabler = (*LevelRegistry)(nil)
+
+// LevelRegistry changes the levels of the named loggers at runtime. The level of the logger is the level of its
+// most specific ancestor which has one, so the level of server.percona is applied to server.percona.stmt too.
+// The temporary level takes precedence over the permanent level of the same logger until it expires.
+type LevelRegistry struct {
+	base zap.AtomicLevel
+
+	mu        sync.RWMutex
+	permanent map[string]zapcore.Level
+	temporary map[string]*temporaryLevel
+}
+
+// NewLevelRegistry returns a new instance of LevelRegistry. The base level is the permanent level of the root.
+func NewLevelRegistry(base zap.AtomicLevel) *LevelRegistry {
+	return &LevelRegistry{
+		base: base,
+
+		mu:        sync.RWMutex{},
+		permanent: make(map[string]zapcore.Level),
+		temporary: make(map[string]*temporaryLevel),
+	}
+}
+
+// Enabled returns true if the level is enabled for any logger.
+func (r *LevelRegistry) Enabled(level zapcore.Level) bool {
+	if r.base.Enabled(level) {
+		return true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, lvl := range r.permanent {
+		if lvl.Enabled(level) {
+			return true
+		}
+	}
+
+	for _, tmp := range r.temporary {
+		if tmp.level.Enabled(level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetLevel sets the level of the named logger, the empty name is the root. The level is reverted after the ttl
+// when it is positive, otherwise the level is permanent and the temporary level of the logger is dropped.
+func (r *LevelRegistry) SetLevel(name string, level zapcore.Level, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stopTemporary(name)
+
+	if ttl > 0 {
+		tmp := &temporaryLevel{
+			level:     level,
+			expiresAt: time.Now().Add(ttl),
+			timer:     nil,
+		}
+
+		tmp.timer = time.AfterFunc(ttl, func() {
+			r.expire(name, tmp)
+		})
+
+		r.temporary[name] = tmp
+
+		return
+	}
+
+	if name == "" {
+		r.base.SetLevel(level)
+
+		return
+	}
+
+	r.permanent[name] = level
+}
+
+// ResetLevel drops the levels of the named logger, so the level of its ancestor is applied. The root level
+// could not be dropped, so only its temporary level is.
+func (r *LevelRegistry) ResetLevel(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stopTemporary(name)
+	delete(r.permanent, name)
+}
+
+// Report returns the current state of the levels.
+func (r *LevelRegistry) Report() LevelReport {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report := LevelReport{
+		Level:     r.base.Level(),
+		ExpiresAt: nil,
+		Overrides: make([]LevelOverride, 0, len(r.permanent)+len(r.temporary)),
+	}
+
+	for name, level := range r.permanent {
+		if _, ok := r.temporary[name]; ok {
+			continue
+		}
+
+		report.Overrides = append(report.Overrides, LevelOverride{
+			Logger:    name,
+			Level:     level,
+			ExpiresAt: nil,
+		})
+	}
+
+	for name, tmp := range r.temporary {
+		expiresAt := tmp.expiresAt
+
+		if name == "" {
+			report.Level, report.ExpiresAt = tmp.level, &expiresAt
+
+			continue
+		}
+
+		report.Overrides = append(report.Overrides, LevelOverride{
+			Logger:    name,
+			Level:     tmp.level,
+			ExpiresAt: &expiresAt,
+		})
+	}
+
+	sort.Slice(report.Overrides, func(i, j int) bool {
+		return report.Overrides[i].Logger < report.Overrides[j].Logger
+	})
+
+	return report
+}
+
+// Core wraps the core, so the entries are filtered by the level of the logger which has written them. The
+// wrapped core should enable all levels.
+func (r *LevelRegistry) Core(core zapcore.Core) zapcore.Core {
+	return &levelCore{
+		Core:     core,
+		registry: r,
+	}
+}
+
+// level returns the level of the named logger.
+func (r *LevelRegistry) level(name string) zapcore.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for {
+		if tmp, ok := r.temporary[name]; ok {
+			return tmp.level
+		}
+
+		if level, ok := r.permanent[name]; ok {
+			return level
+		}
+
+		if name == "" {
+			return r.base.Level()
+		}
+
+		idx := strings.LastIndexByte(name, '.')
+		if idx < 0 {
+			idx = 0
+		}
+
+		name = name[:idx]
+	}
+}
+
+func (r *LevelRegistry) stopTemporary(name string) {
+	if tmp, ok := r.temporary[name]; ok {
+		tmp.timer.Stop()
+		delete(r.temporary, name)
+	}
+}
+
+func (r *LevelRegistry) expire(name string, tmp *temporaryLevel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The level could be replaced while the timer was firing.
+	if r.temporary[name] == tmp {
+		delete(r.temporary, name)
+	}
+}
+
+var _ zapcore.Core = (*levelCore)(nil)
+
+type levelCore struct {
+	zapcore.Core
+
+	registry *LevelRegistry
+}
+
+// Enabled decides whether a given logging level is enabled when logging a message.
+func (core *levelCore) Enabled(level zapcore.Level) bool {
+	return core.registry.Enabled(level)
+}
+
+// With adds structured context to the Core.
+func (core *levelCore) With(ff []zapcore.Field) zapcore.Core {
+	return &levelCore{
+		Core:     core.Core.With(ff),
+		registry: core.registry,
+	}
+}
+
+// Check determines whether the supplied Entry should be logged.
+func (core *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !core.registry.level(entry.LoggerName).Enabled(entry.Level) {
+		return checked
+	}
+
+	return core.Core.Check(entry, checked)
+}
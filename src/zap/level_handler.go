@@ -0,0 +1,125 @@
+package zap
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxLevelRequestSize is the maximum size of the request body which changes the level.
+const maxLevelRequestSize = 1 << 12
+
+// levelRequest changes the level of the named logger.
+type levelRequest struct {
+	// Logger is the name of the logger. The root level is changed when it is empty.
+	Logger string `json:"logger"`
+
+	// Level is the minimum enabled level. The level of the logger is reset when it is null.
+	Level *zapcore.Level `json:"level"`
+
+	// TTL is the duration after which the level is reverted, like 15m. The level is permanent when it is empty.
+	TTL string `json:"ttl"`
+}
+
+// Handler returns handler which reports the levels on GET and changes the level of the logger on PUT. The level
+// could be used to flood or to silence the logs, so it is changed only by the request with the bearer token, and
+// it could not be changed at all when the token is empty. The handler should not be exposed outside of the
+// internal network anyway.
+func (r *LevelRegistry) Handler(token string) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if !isAuthorized(request, token) {
+				writer.Header().Set("WWW-Authenticate", "Bearer")
+				writer.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			if err := r.change(writer, request); err != nil {
+				encodeLevelResponse(writer, http.StatusBadRequest, &struct {
+					Code    string `json:"code"`
+					Message string `json:"message"`
+				}{
+					Code:    otelexample.ErrorCodeFromError(err).String(),
+					Message: otelexample.ErrorMessageFromError(err),
+				})
+
+				return
+			}
+		default:
+			writer.Header().Set("Allow", http.MethodGet+", "+http.MethodPut)
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		encodeLevelResponse(writer, http.StatusOK, r.Report())
+	})
+}
+
+func (r *LevelRegistry) change(writer http.ResponseWriter, request *http.Request) error {
+	decoded := new(levelRequest)
+
+	if err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxLevelRequestSize)).
+		Decode(decoded); err != nil {
+		return fmt.Errorf("decode levelRequest: %w", &otelexample.Error{
+			Code:    otelexample.ErrorCodeInvalid,
+			Message: "failed to decode request",
+			Err:     err,
+		})
+	}
+
+	var ttl time.Duration
+
+	if decoded.TTL != "" {
+		var err error
+
+		if ttl, err = time.ParseDuration(decoded.TTL); err != nil || ttl <= 0 {
+			return fmt.Errorf("decode levelRequest: %w", &otelexample.Error{
+				Code:    otelexample.ErrorCodeInvalid,
+				Message: "ttl should be a positive duration",
+				Err:     err,
+			})
+		}
+	}
+
+	if decoded.Level == nil {
+		r.ResetLevel(decoded.Logger)
+
+		return nil
+	}
+
+	r.SetLevel(decoded.Logger, *decoded.Level, ttl)
+
+	return nil
+}
+
+// isAuthorized checks that the request has the bearer token.
+func isAuthorized(request *http.Request, token string) bool {
+	const prefix = "bearer "
+
+	auth := request.Header.Get("Authorization")
+	if token == "" || len(auth) <= len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(auth[len(prefix):])), []byte(token)) == 1
+}
+
+func encodeLevelResponse(writer http.ResponseWriter, status int, response any) {
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+
+	if err := json.NewEncoder(writer).Encode(response); err != nil {
+		panic(err)
+	}
+}
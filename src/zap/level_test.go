@@ -0,0 +1,175 @@
+package zap_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/zap"
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newLevelRegistry returns the registry with the error root level and the logger which is filtered by it.
+func newLevelRegistry() (*zap.LevelRegistry, *uberzap.Logger, *observer.ObservedLogs) {
+	var (
+		registry   = zap.NewLevelRegistry(uberzap.NewAtomicLevelAt(zapcore.ErrorLevel))
+		core, logs = observer.New(zapcore.DebugLevel)
+	)
+
+	return registry, uberzap.New(registry.Core(core)), logs
+}
+
+// isDebugEnabled checks that the debug entry of the named logger is written.
+func isDebugEnabled(logger *uberzap.Logger, logs *observer.ObservedLogs, name string) bool {
+	logger.Named(name).Debug("debug")
+
+	return len(logs.TakeAll()) == 1
+}
+
+func TestLevelRegistry_Ancestor(t *testing.T) {
+	t.Parallel()
+
+	registry, logger, logs := newLevelRegistry()
+	registry.SetLevel("server.percona", zapcore.DebugLevel, 0)
+
+	for name, expected := range map[string]bool{
+		"server":                false,
+		"server.percona":        true,
+		"server.percona.stmt":   true,
+		"server.percona_shard":  false,
+		"server.http.percona":   false,
+		"server.percona.stmt.x": true,
+	} {
+		if actual := isDebugEnabled(logger, logs, name); actual != expected {
+			t.Errorf("unexpected debug of %s: %t", name, actual)
+		}
+	}
+
+	registry.ResetLevel("server.percona")
+
+	if isDebugEnabled(logger, logs, "server.percona.stmt") {
+		t.Error("debug is enabled after reset")
+	}
+}
+
+func TestLevelRegistry_Expire(t *testing.T) {
+	t.Parallel()
+
+	registry, logger, logs := newLevelRegistry()
+	registry.SetLevel("server", zapcore.InfoLevel, 0)
+	registry.SetLevel("server", zapcore.DebugLevel, time.Millisecond*20)
+
+	if !isDebugEnabled(logger, logs, "server.percona") {
+		t.Fatal("temporary level is not applied")
+	}
+
+	for deadline := time.Now().Add(time.Second); len(registry.Report().Overrides) != 1 ||
+		registry.Report().Overrides[0].ExpiresAt != nil; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("temporary level is not expired: %+v", registry.Report())
+		}
+	}
+
+	// The permanent level of the logger is applied again.
+	if isDebugEnabled(logger, logs, "server.percona") {
+		t.Error("debug is enabled after expiration")
+	}
+
+	logger.Named("server.percona").Info("info")
+
+	if logs.Len() != 1 {
+		t.Error("permanent level is not applied after expiration")
+	}
+}
+
+func TestLevelRegistry_Report(t *testing.T) {
+	t.Parallel()
+
+	registry, _, _ := newLevelRegistry()
+	registry.SetLevel("", zapcore.DebugLevel, time.Hour)
+	registry.SetLevel("server.percona", zapcore.DebugLevel, time.Hour)
+
+	report := registry.Report()
+	if report.Level != zapcore.DebugLevel || report.ExpiresAt == nil || len(report.Overrides) != 1 ||
+		report.Overrides[0].ExpiresAt == nil {
+		t.Fatalf("unexpected report of temporary levels: %+v", report)
+	}
+
+	// The permanent level replaces the temporary one, so nothing is reverted.
+	registry.SetLevel("", zapcore.WarnLevel, 0)
+	registry.SetLevel("server.percona", zapcore.InfoLevel, 0)
+
+	report = registry.Report()
+	if report.Level != zapcore.WarnLevel || report.ExpiresAt != nil {
+		t.Errorf("unexpected root level %s expiring at %v", report.Level, report.ExpiresAt)
+	}
+
+	if len(report.Overrides) != 1 || report.Overrides[0].Logger != "server.percona" ||
+		report.Overrides[0].Level != zapcore.InfoLevel || report.Overrides[0].ExpiresAt != nil {
+		t.Errorf("unexpected overrides: %+v", report.Overrides)
+	}
+}
+
+func TestLevelRegistry_Handler(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name          string
+		token         string
+		authorization string
+		expected      int
+	}{
+		{
+			name:          "Authorized",
+			token:         "secret",
+			authorization: "Bearer secret",
+			expected:      http.StatusOK,
+		},
+		{
+			name:          "WrongToken",
+			token:         "secret",
+			authorization: "Bearer public",
+			expected:      http.StatusUnauthorized,
+		},
+		{
+			name:          "NoToken",
+			token:         "secret",
+			authorization: "",
+			expected:      http.StatusUnauthorized,
+		},
+		{
+			name:          "NotConfigured",
+			token:         "",
+			authorization: "Bearer ",
+			expected:      http.StatusUnauthorized,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				registry, _, _ = newLevelRegistry()
+				recorder       = httptest.NewRecorder()
+				request        = httptest.NewRequest(http.MethodPut, "/admin/log-level",
+					strings.NewReader(`{"logger":"server","level":"debug"}`))
+			)
+
+			request.Header.Set("Authorization", tc.authorization)
+			registry.Handler(tc.token).ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.expected {
+				t.Fatalf("unexpected status %d", recorder.Code)
+			}
+
+			if changed := len(registry.Report().Overrides) == 1; changed != (tc.expected == http.StatusOK) {
+				t.Errorf("unexpected overrides: %+v", registry.Report().Overrides)
+			}
+		})
+	}
+}
@@ -11,17 +11,24 @@ import (
 var _ percona.PrepareTxBeginner = (*PrepareTxBeginner)(nil)
 
 type PrepareTxBeginner struct {
-	wrapped percona.PrepareTxBeginner
-	logger  *zap.Logger
-	fields  []zap.Field
+	wrapped  percona.PrepareTxBeginner
+	logger   *zap.Logger
+	redactor *Redactor
+	fields   []zap.Field
 }
 
 // NewPrepareTxBeginner returns a new instance of PrepareTxBeginner.
-func NewPrepareTxBeginner(svc percona.PrepareTxBeginner, logger *zap.Logger, ff ...zap.Field) *PrepareTxBeginner {
+func NewPrepareTxBeginner(
+	svc percona.PrepareTxBeginner,
+	logger *zap.Logger,
+	redactor *Redactor,
+	ff ...zap.Field,
+) *PrepareTxBeginner {
 	return &PrepareTxBeginner{
-		wrapped: svc,
-		logger:  logger,
-		fields:  ff,
+		wrapped:  svc,
+		logger:   logger,
+		redactor: redactor,
+		fields:   ff,
 	}
 }
 
@@ -32,28 +39,35 @@ func (svc *PrepareTxBeginner) PrepareContext(ctx context.Context, query string)
 		err         error
 	)
 
+	fingerprint := percona.FingerprintContext(ctx, query)
+
 	start, end, elapsed := trackOfTime(func() {
 		perconaStmt, err = svc.wrapped.PrepareContext(ctx, query)
 	})
 
 	ff := svc.fields
 	ff = append(ff, zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
-		zap.String("query", query), zap.Error(err))
+		queryField(fingerprint), zap.Error(err))
 
-	svc.logger.Debug("prepare", ff...)
+	if entry := svc.logger.Check(zap.DebugLevel, "prepare"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		svc.logger.Error("prepare", ff...)
+		if entry := svc.logger.Check(zap.ErrorLevel, "prepare"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return nil, err
 	}
 
 	return &stmt{
-		wrapped: perconaStmt,
-		logger:  svc.logger.Named("stmt"),
-		fields:  svc.fields,
+		wrapped:  perconaStmt,
+		logger:   svc.logger.Named("stmt"),
+		redactor: svc.redactor,
+		fields:   svc.fields,
 
-		query: query,
+		fingerprint: fingerprint,
 	}, nil
 }
 
@@ -72,17 +86,22 @@ func (svc *PrepareTxBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions)
 	ff = append(ff, zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
 		zap.Any("options", opts), zap.Error(err))
 
-	svc.logger.Debug("begin tx", ff...)
+	if entry := svc.logger.Check(zap.DebugLevel, "begin tx"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		svc.logger.Error("begin tx", ff...)
+		if entry := svc.logger.Check(zap.ErrorLevel, "begin tx"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return nil, err // nolint:wrapcheck
 	}
 
 	return &tx{
-		wrapped: perconaTx,
-		logger:  svc.logger.Named("tx"),
-		fields:  svc.fields,
+		wrapped:  perconaTx,
+		logger:   svc.logger.Named("tx"),
+		redactor: svc.redactor,
+		fields:   svc.fields,
 	}, nil
 }
@@ -0,0 +1,157 @@
+package zap_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/otelexampletest"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona/perconatest"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/zap"
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestPrepareTxBeginner_Conflict(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf    = new(bytes.Buffer)
+		logger = uberzap.New(zapcore.NewCore(zapcore.NewJSONEncoder(uberzap.NewProductionEncoderConfig()),
+			zapcore.AddSync(buf), uberzap.DebugLevel))
+	)
+
+	createConflictingUserAccount(t, zap.NewPrepareTxBeginner(connectClient(t, newDuplicateEntryScript(t)), logger,
+		zap.NewRedactor(zap.RedactionMask, nil)))
+
+	// The arguments contain the personal data, so they should be redacted.
+	if output := buf.String(); strings.Contains(output, "alice") || strings.Contains(output, "Liddell") {
+		t.Errorf("sensitive arguments are written to the log: %s", output)
+	}
+
+	var failed, rolledBack bool
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := make(map[string]any)
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("decode entry %q: %v", line, err)
+		}
+
+		failed = failed || entry["level"] == "error" && entry["msg"] == "exec" && entry["tenant"] == "tenant" &&
+			strings.Contains(line, "create_user_account") && strings.Contains(line, "Duplicate entry")
+		rolledBack = rolledBack || entry["msg"] == "rollback"
+	}
+
+	if !failed {
+		t.Errorf("failed statement is not logged: %s", buf.String())
+	}
+
+	if !rolledBack {
+		t.Errorf("rollback is not logged: %s", buf.String())
+	}
+}
+
+func TestPrepareTxBeginner_Redaction(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range redactionPolicies {
+		policy := policy
+
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			logger, logs := newObservedLogger()
+
+			createConflictingUserAccount(t, zap.NewPrepareTxBeginner(connectClient(t, newDuplicateEntryScript(t)),
+				logger, zap.NewRedactor(policy, testHashKey)))
+
+			assertNotLogged(t, logs, "alice", "Alice", "Liddell")
+
+			failed := logs.FilterMessage("exec").FilterLevelExact(zapcore.ErrorLevel).AllUntimed()
+			if len(failed) == 0 {
+				t.Fatalf("failed statement is not logged: %v", logs.AllUntimed())
+			}
+
+			// The user account is inserted with the tenant, the identifier, the username, the user identifier
+			// and the creation time.
+			args, ok := failed[len(failed)-1].ContextMap()["args"].([]any)
+			if !ok || len(args) != 5 {
+				t.Fatalf("unexpected args %v", failed[len(failed)-1].ContextMap())
+			}
+
+			expected, kept := zap.NewRedactor(policy, testHashKey).Redact("alice")
+			if !kept && args[2] != nil || kept && args[2] != expected {
+				t.Errorf("unexpected username argument %v", args[2])
+			}
+
+			// The arguments which are not strings are not sensitive.
+			if args[4] != time.Date(2022, time.January, 1, 0, 0, 1, 0, time.UTC).UnixMilli() {
+				t.Errorf("unexpected creation time argument %v", args[4])
+			}
+		})
+	}
+}
+
+// connectClient returns the client which follows the script.
+func connectClient(t *testing.T, script *perconatest.Script) *percona.Client {
+	t.Helper()
+
+	client := percona.NewClient(script.DSN(), percona.WithDriverName(perconatest.DriverName))
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := client.Close(); err != nil {
+			t.Errorf("close: %v", err)
+		}
+	})
+
+	return client
+}
+
+// createConflictingUserAccount creates the user account which violates the unique key of the username.
+func createConflictingUserAccount(t *testing.T, prepareTxBeginner percona.PrepareTxBeginner) {
+	t.Helper()
+
+	var (
+		ctx = otelexample.NewContextWithTenantID(context.Background(), "tenant")
+		svc = percona.NewUserAccountService(prepareTxBeginner, otelexampletest.NewSequenceIdentifierGenerator("id-"),
+			otelexampletest.NewStepTimer(time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), time.Second))
+		ua = &otelexample.UserAccount{
+			ID:       otelexample.EmptyID,
+			Username: "alice",
+			User: &otelexample.User{
+				ID:        otelexample.EmptyID,
+				FirstName: "Alice",
+				LastName:  "Liddell",
+				CreatedAt: time.Time{},
+			},
+			CreatedAt: time.Time{},
+		}
+	)
+
+	err := svc.CreateUserAccount(ctx, ua)
+	if code := otelexample.ErrorCodeFromError(err); code != otelexample.ErrorCodeConflict {
+		t.Fatalf("unexpected error code %q: %v", code, err)
+	}
+}
+
+// newDuplicateEntryScript returns the script of the user account creation which fails with the duplicate entry.
+func newDuplicateEntryScript(t *testing.T) *perconatest.Script {
+	t.Helper()
+
+	script := perconatest.NewScript(t)
+	script.ExpectBegin()
+	script.ExpectQuery(`^SELECT EXISTS`).WillReturnRows(perconatest.NewRows("is_exists").AddRow(int64(0)))
+	script.ExpectExec(`^INSERT INTO users `)
+	script.ExpectExec(`^INSERT INTO user_accounts `).WillReturnError(perconatest.MySQLError(1062, "Duplicate entry"))
+	script.ExpectRollback()
+
+	return script
+}
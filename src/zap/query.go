@@ -0,0 +1,26 @@
+package zap
+
+import (
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var _ zapcore.ObjectMarshaler = (*queryFingerprint)(nil)
+
+// queryFingerprint logs the normalized query, so the literal values of the query never get to the log.
+type queryFingerprint percona.QueryFingerprint
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (fingerprint queryFingerprint) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("query", fingerprint.Normalized)
+	encoder.AddString("queryName", fingerprint.Name)
+	encoder.AddString("queryDigest", fingerprint.Digest)
+
+	return nil
+}
+
+// queryField returns the inlined fields of the query fingerprint.
+func queryField(fingerprint percona.QueryFingerprint) zap.Field {
+	return zap.Inline(queryFingerprint(fingerprint))
+}
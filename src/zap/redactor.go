@@ -0,0 +1,390 @@
+package zap
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactionPolicy describes how the sensitive data is written to the log.
+type RedactionPolicy string
+
+const (
+	// RedactionMask replaces the sensitive value with the mask.
+	RedactionMask = RedactionPolicy("mask")
+
+	// RedactionHash replaces the sensitive value with its keyed hash, so the
+	// same values could be correlated across log entries.
+	RedactionHash = RedactionPolicy("hash")
+
+	// RedactionDrop removes the sensitive value from the log entry.
+	RedactionDrop = RedactionPolicy("drop")
+)
+
+// ErrUnknownRedactionPolicy is returned when the redaction policy is not supported.
+var ErrUnknownRedactionPolicy = errors.New("redaction policy should be mask, hash or drop")
+
+// ParseRedactionPolicy parses the redaction policy.
+func ParseRedactionPolicy(value string) (RedactionPolicy, error) {
+	switch policy := RedactionPolicy(value); policy {
+	case RedactionMask, RedactionHash, RedactionDrop:
+		return policy, nil
+	}
+
+	return "", fmt.Errorf("%q: %w", value, ErrUnknownRedactionPolicy)
+}
+
+const (
+	// SensitiveTag is the struct tag value which marks the field with the
+	// sensitive data like `log:"sensitive"`. The domain types tag the fields
+	// with personal data, so they are redacted in logs.
+	SensitiveTag = "sensitive"
+
+	// redactionTagKey is the struct tag key which is used by redactor.
+	redactionTagKey = "log"
+
+	// redactionMask is the value which replaces the masked data.
+	redactionMask = "***"
+
+	// redactionHashSize is the number of hash bytes which are written to the log.
+	redactionHashSize = 8
+)
+
+// nolint:gochecknoglobals
+var (
+	// sensitiveKeys are the normalized names of the sensitive fields of the domain
+	// types. They are used for redaction of the encoded data like JSON bodies.
+	sensitiveKeys = collectSensitiveKeys(reflect.TypeOf(otelexample.UserAccount{}), make(map[string]struct{}))
+
+	// sensitiveHeaders are the request headers with credentials.
+	sensitiveHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+	timeType     = reflect.TypeOf(time.Time{})
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// Redactor hides the sensitive data which is written to the log.
+type Redactor struct {
+	policy  RedactionPolicy
+	hashKey []byte
+}
+
+// NewRedactor returns a new Redactor instance. The hash key is used by RedactionHash policy.
+func NewRedactor(policy RedactionPolicy, hashKey []byte) *Redactor {
+	return &Redactor{
+		policy:  policy,
+		hashKey: hashKey,
+	}
+}
+
+// Redact returns the redacted value. It returns false when the value should be dropped.
+func (r *Redactor) Redact(value string) (string, bool) {
+	switch r.policy {
+	case RedactionDrop:
+		return "", false
+	case RedactionHash:
+		mac := hmac.New(sha256.New, r.hashKey)
+		_, _ = mac.Write([]byte(value))
+
+		return hex.EncodeToString(mac.Sum(nil)[:redactionHashSize]), true
+	case RedactionMask:
+	}
+
+	return redactionMask, true
+}
+
+// Object returns the field which encodes the value with the redacted sensitive fields.
+func (r *Redactor) Object(key string, value any) zap.Field {
+	val := reflect.ValueOf(value)
+	if !val.IsValid() || (val.Kind() == reflect.Pointer && val.IsNil()) {
+		return zap.Reflect(key, nil)
+	}
+
+	switch indirectValue(val).Kind() { // nolint:exhaustive
+	case reflect.Struct:
+		return zap.Object(key, redactedObject{redactor: r, value: indirectValue(val)})
+	case reflect.Slice, reflect.Array:
+		return zap.Array(key, redactedArray{redactor: r, value: indirectValue(val)})
+	}
+
+	return zap.Any(key, value)
+}
+
+// Args returns the field which encodes the query arguments. The arguments are
+// not tagged, so all strings and bytes are considered sensitive.
+func (r *Redactor) Args(key string, args []any) zap.Field {
+	return zap.Array(key, redactedArgs{redactor: r, args: args})
+}
+
+// JSON returns the field with the JSON document where the values of the
+// sensitive fields are redacted. The document which could not be decoded is
+// not written at all.
+func (r *Redactor) JSON(key string, data []byte) zap.Field {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return zap.String(key, "")
+	}
+
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return zap.String(key, fmt.Sprintf("[%d bytes]", len(data)))
+	}
+
+	return zap.Reflect(key, r.redactJSON(document))
+}
+
+// request returns a copy of the request with the redacted credential headers.
+func (r *Redactor) request(request *http.Request) *http.Request {
+	redacted := request.Clone(request.Context())
+
+	for _, header := range sensitiveHeaders {
+		if redacted.Header.Get(header) == "" {
+			continue
+		}
+
+		value, ok := r.Redact(redacted.Header.Get(header))
+		if !ok {
+			redacted.Header.Del(header)
+
+			continue
+		}
+
+		redacted.Header.Set(header, value)
+	}
+
+	return redacted
+}
+
+func (r *Redactor) redactJSON(document any) any {
+	switch value := document.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if _, ok := sensitiveKeys[normalizeSensitiveKey(key)]; !ok {
+				value[key] = r.redactJSON(field)
+
+				continue
+			}
+
+			redacted, ok := r.Redact(fmt.Sprint(field))
+			if !ok {
+				delete(value, key)
+
+				continue
+			}
+
+			value[key] = redacted
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = r.redactJSON(item)
+		}
+	}
+
+	return document
+}
+
+func collectSensitiveKeys(typ reflect.Type, keys map[string]struct{}) map[string]struct{} {
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return keys
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		if field.Tag.Get(redactionTagKey) == SensitiveTag {
+			keys[normalizeSensitiveKey(field.Name)] = struct{}{}
+
+			continue
+		}
+
+		collectSensitiveKeys(field.Type, keys)
+	}
+
+	return keys
+}
+
+func normalizeSensitiveKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+func indirectValue(val reflect.Value) reflect.Value {
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+
+	return val
+}
+
+var _ zapcore.ObjectMarshaler = (*redactedObject)(nil)
+
+// redactedObject encodes the struct fields. The fields which are tagged as
+// sensitive are redacted and the fields which are tagged with "-" are skipped.
+type redactedObject struct {
+	redactor *Redactor
+	value    reflect.Value
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (obj redactedObject) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	typ := obj.value.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		switch field.Tag.Get(redactionTagKey) {
+		case "-":
+			continue
+		case SensitiveTag:
+			if redacted, ok := obj.redactor.Redact(fmt.Sprint(obj.value.Field(i).Interface())); ok {
+				encoder.AddString(field.Name, redacted)
+			}
+
+			continue
+		}
+
+		if err := obj.redactor.addValue(encoder, field.Name, obj.value.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nolint:cyclop
+func (r *Redactor) addValue(encoder zapcore.ObjectEncoder, key string, val reflect.Value) error {
+	if val.Kind() == reflect.Pointer && val.IsNil() {
+		return encoder.AddReflected(key, nil)
+	}
+
+	switch {
+	case val.Type() == timeType:
+		encoder.AddTime(key, val.Interface().(time.Time)) // nolint:forcetypeassert
+
+		return nil
+	case val.Type().Implements(stringerType) && val.Kind() != reflect.Pointer:
+		encoder.AddString(key, val.Interface().(fmt.Stringer).String()) // nolint:forcetypeassert
+
+		return nil
+	}
+
+	val = indirectValue(val)
+
+	switch val.Kind() { // nolint:exhaustive
+	case reflect.Struct:
+		return encoder.AddObject(key, redactedObject{redactor: r, value: val})
+	case reflect.Slice, reflect.Array:
+		return encoder.AddArray(key, redactedArray{redactor: r, value: val})
+	case reflect.String:
+		encoder.AddString(key, val.String())
+	case reflect.Bool:
+		encoder.AddBool(key, val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encoder.AddInt64(key, val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		encoder.AddUint64(key, val.Uint())
+	case reflect.Float32, reflect.Float64:
+		encoder.AddFloat64(key, val.Float())
+	default:
+		return encoder.AddReflected(key, val.Interface())
+	}
+
+	return nil
+}
+
+var _ zapcore.ArrayMarshaler = (*redactedArray)(nil)
+
+// redactedArray encodes the items of slice or array with the redacted sensitive fields.
+type redactedArray struct {
+	redactor *Redactor
+	value    reflect.Value
+}
+
+// MarshalLogArray implements zapcore.ArrayMarshaler.
+func (arr redactedArray) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for i := 0; i < arr.value.Len(); i++ {
+		item := indirectValue(arr.value.Index(i))
+
+		switch {
+		case item.Kind() == reflect.Pointer:
+			if err := encoder.AppendReflected(nil); err != nil {
+				return err
+			}
+		case item.Kind() == reflect.Struct && item.Type() != timeType:
+			if err := encoder.AppendObject(redactedObject{redactor: arr.redactor, value: item}); err != nil {
+				return err
+			}
+		default:
+			if err := encoder.AppendReflected(item.Interface()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+var _ zapcore.ArrayMarshaler = (*redactedArgs)(nil)
+
+// redactedArgs encodes the query arguments with the redacted strings and bytes.
+type redactedArgs struct {
+	redactor *Redactor
+	args     []any
+}
+
+// MarshalLogArray implements zapcore.ArrayMarshaler.
+func (arr redactedArgs) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for _, arg := range arr.args {
+		var value string
+
+		switch typed := arg.(type) {
+		case time.Time:
+			encoder.AppendTime(typed)
+
+			continue
+		case string:
+			value = typed
+		case []byte:
+			value = string(typed)
+		case fmt.Stringer:
+			value = typed.String()
+		default:
+			if err := encoder.AppendReflected(arg); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		// The dropped argument is kept as null, so the positions of arguments are not shifted.
+		redacted, ok := arr.redactor.Redact(value)
+		if !ok {
+			if err := encoder.AppendReflected(nil); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		encoder.AppendString(redacted)
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package zap_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/zap"
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testHashKey is the key of the hashed sensitive values.
+var testHashKey = []byte("secret") // nolint:gochecknoglobals
+
+// redactionPolicies are the policies which are covered by the tests.
+var redactionPolicies = []zap.RedactionPolicy{ // nolint:gochecknoglobals
+	zap.RedactionMask,
+	zap.RedactionHash,
+	zap.RedactionDrop,
+}
+
+func TestRedactor_Object(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range redactionPolicies {
+		policy := policy
+
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			createdAt := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+			result := &otelexample.FindUserAccountsResult{
+				HasNext: false,
+				Total:   1,
+				Options: otelexample.FindOptions{},
+				Data: []*otelexample.UserAccount{{
+					ID:       "account-id",
+					Username: "alice",
+					User: &otelexample.User{
+						ID:        "user-id",
+						FirstName: "Alice",
+						LastName:  "Liddell",
+						CreatedAt: createdAt,
+					},
+					CreatedAt: createdAt,
+				}},
+			}
+
+			fields := logFields(t, zap.NewRedactor(policy, testHashKey).Object("result", result))
+
+			data, ok := objectField(t, fields, "result")["Data"].([]any)
+			if !ok || len(data) != 1 {
+				t.Fatalf("unexpected data: %v", fields)
+			}
+
+			account, ok := data[0].(map[string]any)
+			if !ok {
+				t.Fatalf("unexpected account: %v", data[0])
+			}
+
+			if account["ID"] != "account-id" {
+				t.Errorf("unexpected account id %v", account["ID"])
+			}
+
+			assertRedacted(t, policy, account, "Username", "alice")
+
+			user := objectField(t, account, "User")
+			if user["ID"] != "user-id" {
+				t.Errorf("unexpected user id %v", user["ID"])
+			}
+
+			if user["CreatedAt"] != createdAt {
+				t.Errorf("unexpected user creation time %v", user["CreatedAt"])
+			}
+
+			assertRedacted(t, policy, user, "FirstName", "Alice")
+			assertRedacted(t, policy, user, "LastName", "Liddell")
+		})
+	}
+}
+
+func TestRedactor_Object_Nil(t *testing.T) {
+	t.Parallel()
+
+	var ua *otelexample.UserAccount
+
+	if fields := logFields(t, zap.NewRedactor(zap.RedactionMask, nil).Object("account", ua)); fields["account"] != nil {
+		t.Errorf("unexpected account %v", fields["account"])
+	}
+}
+
+func TestRedactor_Args(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range redactionPolicies {
+		policy := policy
+
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			redactor := zap.NewRedactor(policy, testHashKey)
+
+			fields := logFields(t, redactor.Args("args", []any{"alice", []byte("Liddell"), int64(42)}))
+
+			args, ok := fields["args"].([]any)
+			if !ok || len(args) != 3 {
+				t.Fatalf("unexpected args %v", args)
+			}
+
+			// The dropped arguments are kept as nulls, so the positions are not shifted.
+			for i, value := range []string{"alice", "Liddell"} {
+				expected, kept := redactor.Redact(value)
+				if !kept && args[i] != nil || kept && args[i] != expected {
+					t.Errorf("unexpected argument %d %v", i, args[i])
+				}
+			}
+
+			if args[2] != int64(42) {
+				t.Errorf("unexpected argument %v", args[2])
+			}
+		})
+	}
+}
+
+func TestRedactor_JSON(t *testing.T) {
+	t.Parallel()
+
+	const data = `{"data":[{"id":"account-id","username":"alice",` +
+		`"user":{"id":"user-id","firstName":"Alice","LastName":"Liddell","first_name":"Alice"}}]}`
+
+	for _, policy := range redactionPolicies {
+		policy := policy
+
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			document := objectField(t, logFields(t, zap.NewRedactor(policy, testHashKey).JSON("response",
+				[]byte(data))), "response")
+
+			items, ok := document["data"].([]any)
+			if !ok || len(items) != 1 {
+				t.Fatalf("unexpected data %v", document)
+			}
+
+			account, ok := items[0].(map[string]any)
+			if !ok {
+				t.Fatalf("unexpected account %v", items[0])
+			}
+
+			if account["id"] != "account-id" {
+				t.Errorf("unexpected account id %v", account["id"])
+			}
+
+			assertRedacted(t, policy, account, "username", "alice")
+
+			// The keys are matched regardless of the case and the separators.
+			user := objectField(t, account, "user")
+			assertRedacted(t, policy, user, "firstName", "Alice")
+			assertRedacted(t, policy, user, "LastName", "Liddell")
+			assertRedacted(t, policy, user, "first_name", "Alice")
+
+			if user["id"] != "user-id" {
+				t.Errorf("unexpected user id %v", user["id"])
+			}
+		})
+	}
+}
+
+func TestRedactor_JSON_Invalid(t *testing.T) {
+	t.Parallel()
+
+	redactor := zap.NewRedactor(zap.RedactionMask, nil)
+
+	fields := logFields(t, redactor.JSON("response", []byte(`{"username":"alice"`)))
+	if fields["response"] != "[19 bytes]" {
+		t.Errorf("unexpected response %v", fields["response"])
+	}
+
+	if fields := logFields(t, redactor.JSON("response", []byte(" \n"))); fields["response"] != "" {
+		t.Errorf("unexpected response %v", fields["response"])
+	}
+}
+
+// newObservedLogger returns the logger which records the entries of all levels.
+func newObservedLogger() (*uberzap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	return uberzap.New(core), logs
+}
+
+// logFields returns the fields as they are encoded by the logger.
+func logFields(t *testing.T, ff ...zapcore.Field) map[string]any {
+	t.Helper()
+
+	logger, logs := newObservedLogger()
+	logger.Info("redacted", ff...)
+
+	entries := logs.AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("unexpected entries %v", entries)
+	}
+
+	return entries[0].ContextMap()
+}
+
+// objectField returns the value of the field which is encoded as an object.
+func objectField(t *testing.T, fields map[string]any, key string) map[string]any {
+	t.Helper()
+
+	object, ok := fields[key].(map[string]any)
+	if !ok {
+		t.Fatalf("field %s is not an object: %v", key, fields)
+	}
+
+	return object
+}
+
+// assertRedacted checks that the sensitive field is redacted according to the policy.
+func assertRedacted(t *testing.T, policy zap.RedactionPolicy, fields map[string]any, key, value string) {
+	t.Helper()
+
+	actual, ok := fields[key]
+
+	switch expected, kept := zap.NewRedactor(policy, testHashKey).Redact(value); {
+	case !kept && ok:
+		t.Errorf("field %s is not dropped: %v", key, actual)
+	case kept && actual != expected:
+		t.Errorf("unexpected field %s %v, expected %v", key, actual, expected)
+	}
+}
+
+// assertNotLogged checks that the sensitive values are not written to the log.
+func assertNotLogged(t *testing.T, logs *observer.ObservedLogs, values ...string) {
+	t.Helper()
+
+	for _, entry := range logs.AllUntimed() {
+		output := fmt.Sprint(entry.ContextMap())
+
+		for _, value := range values {
+			if strings.Contains(output, value) {
+				t.Errorf("sensitive value %q is written to the log: %s", value, output)
+			}
+		}
+	}
+}
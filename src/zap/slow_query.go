@@ -0,0 +1,49 @@
+package zap
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/morozovcookie/opentelemetry-prometheus-example/percona"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlowQueryHandler returns the handler which logs slow queries. Within each tick
+// the first entries are logged and then every thereafter-th entry is logged. The
+// sampling is disabled when the tick is zero.
+func SlowQueryHandler(logger *zap.Logger, tick time.Duration, first, thereafter int) percona.SlowQueryHandler {
+	if tick > 0 {
+		logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewSamplerWithOptions(core, tick, first, thereafter)
+		}))
+	}
+
+	return func(ctx context.Context, query percona.SlowQuery) {
+		ff := []zap.Field{
+			queryField(query.Fingerprint), zap.Int("argsCount", query.ArgsCount),
+			zap.Stringer("elapsed", query.Elapsed),
+		}
+
+		if query.RowsAffected >= 0 {
+			ff = append(ff, zap.Int64("rowsAffected", query.RowsAffected))
+		}
+
+		if query.Err != nil {
+			ff = append(ff, zap.Error(query.Err))
+		}
+
+		if query.Plan != "" {
+			ff = append(ff, zap.Reflect("plan", json.RawMessage(query.Plan)))
+		}
+
+		if query.PlanErr != nil {
+			ff = append(ff, zap.NamedError("planError", query.PlanErr))
+		}
+
+		if entry := logger.Check(zap.WarnLevel, "slow query"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
+	}
+}
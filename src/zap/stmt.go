@@ -12,11 +12,12 @@ var _ percona.Stmt = (*stmt)(nil)
 
 // stmt is a prepared statement.
 type stmt struct {
-	wrapped percona.Stmt
-	logger  *zap.Logger
-	fields  []zap.Field
+	wrapped  percona.Stmt
+	logger   *zap.Logger
+	redactor *Redactor
+	fields   []zap.Field
 
-	query string
+	fingerprint percona.QueryFingerprint
 }
 
 // ExecContext executes a prepared statement with the given arguments and
@@ -33,12 +34,16 @@ func (stmt *stmt) ExecContext(ctx context.Context, args ...any) (sql.Result, err
 
 	ff := stmt.fields
 	ff = append(ff, zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
-		zap.Any("args", args), zap.String("query", stmt.query), zap.Error(err))
+		stmt.redactor.Args("args", args), queryField(stmt.fingerprint), zap.Error(err))
 
-	stmt.logger.Debug("exec", ff...)
+	if entry := stmt.logger.Check(zap.DebugLevel, "exec"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		stmt.logger.Error("exec", ff...)
+		if entry := stmt.logger.Check(zap.ErrorLevel, "exec"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return nil, err // nolint:wrapcheck
 	}
@@ -47,8 +52,8 @@ func (stmt *stmt) ExecContext(ctx context.Context, args ...any) (sql.Result, err
 }
 
 // QueryRowContext executes a prepared query statement with the given arguments.
-func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
-	var row *sql.Row
+func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) percona.Row {
+	var row percona.Row
 
 	start, end, elapsed := trackOfTime(func() {
 		row = stmt.wrapped.QueryRowContext(ctx, args...)
@@ -56,9 +61,11 @@ func (stmt *stmt) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
 
 	ff := stmt.fields
 	ff = append(ff, zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
-		zap.Any("args", args), zap.String("query", stmt.query), zap.Error(row.Err()))
+		stmt.redactor.Args("args", args), queryField(stmt.fingerprint), zap.Error(row.Err()))
 
-	stmt.logger.Debug("query row", ff...)
+	if entry := stmt.logger.Check(zap.DebugLevel, "query row"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	return row
 }
@@ -77,12 +84,16 @@ func (stmt *stmt) QueryContext(ctx context.Context, args ...any) (*sql.Rows, err
 
 	ff := stmt.fields
 	ff = append(ff, zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
-		zap.Any("args", args), zap.String("query", stmt.query), zap.Error(err))
+		stmt.redactor.Args("args", args), queryField(stmt.fingerprint), zap.Error(err))
 
-	stmt.logger.Debug("query", ff...)
+	if entry := stmt.logger.Check(zap.DebugLevel, "query"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		stmt.logger.Error("query", ff...)
+		if entry := stmt.logger.Check(zap.ErrorLevel, "query"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return nil, err
 	}
@@ -99,12 +110,16 @@ func (stmt *stmt) Close(ctx context.Context) error {
 
 	ff := stmt.fields
 	ff = append(ff, zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
-		zap.Error(err), zap.String("query", stmt.query))
+		zap.Error(err), queryField(stmt.fingerprint))
 
-	stmt.logger.Debug("close", ff...)
+	if entry := stmt.logger.Check(zap.DebugLevel, "close"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		stmt.logger.Error("close", ff...)
+		if entry := stmt.logger.Check(zap.ErrorLevel, "close"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return err // nolint:wrapcheck
 	}
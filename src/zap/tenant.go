@@ -0,0 +1,18 @@
+package zap
+
+import (
+	"context"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"go.uber.org/zap"
+)
+
+// tenantField returns the field with the tenant from the context or skips it when the tenant is not specified.
+func tenantField(ctx context.Context) zap.Field {
+	id, ok := otelexample.TenantIDFromContext(ctx)
+	if !ok {
+		return zap.Skip()
+	}
+
+	return zap.Stringer("tenant", id)
+}
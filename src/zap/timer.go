@@ -32,8 +32,10 @@ func (svc *Timer) Time(ctx context.Context) time.Time {
 		timeValue = svc.wrapped.Time(ctx)
 	})
 
-	svc.logger.Debug("time", zap.Stringer("start", start), zap.Stringer("end", end),
-		zap.Stringer("elapsed", elapsed), zap.Stringer("time", timeValue))
+	if entry := svc.logger.Check(zap.DebugLevel, "time"); entry != nil {
+		entry.Write(loggerFields(ctx, zap.Stringer("start", start), zap.Stringer("end", end),
+			zap.Stringer("elapsed", elapsed), zap.Stringer("time", timeValue))...)
+	}
 
 	return timeValue
 }
@@ -11,9 +11,10 @@ var _ percona.Tx = (*tx)(nil)
 
 // tx is an in-progress database transaction.
 type tx struct {
-	wrapped percona.Tx
-	logger  *zap.Logger
-	fields  []zap.Field
+	wrapped  percona.Tx
+	logger   *zap.Logger
+	redactor *Redactor
+	fields   []zap.Field
 }
 
 // PrepareContext creates a prepared statement for later queries or executions.
@@ -23,47 +24,58 @@ func (tx *tx) PrepareContext(ctx context.Context, query string) (percona.Stmt, e
 		err         error
 	)
 
+	fingerprint := percona.FingerprintContext(ctx, query)
+
 	start, end, elapsed := trackOfTime(func() {
 		perconaStmt, err = tx.wrapped.PrepareContext(ctx, query)
 	})
 
 	ff := tx.fields
 	ff = append(ff, zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
-		zap.String("query", query), zap.Error(err))
+		queryField(fingerprint), zap.Error(err))
 
-	tx.logger.Debug("prepare", ff...)
+	if entry := tx.logger.Check(zap.DebugLevel, "prepare"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		tx.logger.Error("prepare", ff...)
+		if entry := tx.logger.Check(zap.ErrorLevel, "prepare"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return nil, err // nolint:wrapcheck
 	}
 
 	return &stmt{
-		wrapped: perconaStmt,
-		logger:  tx.logger.Named("stmt"),
-		fields:  tx.fields,
+		wrapped:  perconaStmt,
+		logger:   tx.logger.Named("stmt"),
+		redactor: tx.redactor,
+		fields:   tx.fields,
 
-		query: query,
+		fingerprint: fingerprint,
 	}, nil
 }
 
 // Commit commits the transaction.
-func (tx *tx) Commit() error {
+func (tx *tx) Commit(ctx context.Context) error {
 	var err error
 
 	start, end, elapsed := trackOfTime(func() {
-		err = tx.wrapped.Commit()
+		err = tx.wrapped.Commit(ctx)
 	})
 
 	ff := tx.fields
 	ff = append(ff, zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
 		zap.Error(err))
 
-	tx.logger.Debug("commit", ff...)
+	if entry := tx.logger.Check(zap.DebugLevel, "commit"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		tx.logger.Error("commit", ff...)
+		if entry := tx.logger.Check(zap.ErrorLevel, "commit"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return err // nolint:wrapcheck
 	}
@@ -72,21 +84,25 @@ func (tx *tx) Commit() error {
 }
 
 // Rollback aborts the transaction.
-func (tx *tx) Rollback() error {
+func (tx *tx) Rollback(ctx context.Context) error {
 	var err error
 
 	start, end, elapsed := trackOfTime(func() {
-		err = tx.wrapped.Rollback()
+		err = tx.wrapped.Rollback(ctx)
 	})
 
 	ff := tx.fields
 	ff = append(ff, zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
 		zap.Error(err))
 
-	tx.logger.Debug("rollback", ff...)
+	if entry := tx.logger.Check(zap.DebugLevel, "rollback"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		tx.logger.Error("rollback", ff...)
+		if entry := tx.logger.Check(zap.ErrorLevel, "rollback"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return err // nolint:wrapcheck
 	}
@@ -11,15 +11,21 @@ var _ otelexample.UserAccountService = (*UserAccountService)(nil)
 
 // UserAccountService represents a service for managing UserAccount data.
 type UserAccountService struct {
-	wrapped otelexample.UserAccountService
-	logger  *zap.Logger
+	wrapped  otelexample.UserAccountService
+	logger   *zap.Logger
+	redactor *Redactor
 }
 
 // NewUserAccountService returns a new instance of UserAccountService.
-func NewUserAccountService(svc otelexample.UserAccountService, logger *zap.Logger) *UserAccountService {
+func NewUserAccountService(
+	svc otelexample.UserAccountService,
+	logger *zap.Logger,
+	redactor *Redactor,
+) *UserAccountService {
 	return &UserAccountService{
-		wrapped: svc,
-		logger:  logger,
+		wrapped:  svc,
+		logger:   logger,
+		redactor: redactor,
 	}
 }
 
@@ -37,13 +43,17 @@ func (svc *UserAccountService) CreateUserAccount(ctx context.Context, ua *otelex
 
 	ff := []zap.Field{
 		zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
-		zap.Any("before", before), zap.Any("after", ua), zap.Error(err),
+		svc.redactor.Object("before", before), svc.redactor.Object("after", ua), zap.Error(err),
 	}
 
-	svc.logger.Debug("create user account", ff...)
+	if entry := svc.logger.Check(zap.DebugLevel, "create user account"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		svc.logger.Error("create user account", ff...)
+		if entry := svc.logger.Check(zap.ErrorLevel, "create user account"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return err // nolint:wrapcheck
 	}
@@ -75,14 +85,18 @@ func (svc *UserAccountService) FindUserAccounts(
 
 	ff := []zap.Field{
 		zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
-		zap.Any("options", opts), zap.Any("result", result), zap.Int("dataSize", count),
+		zap.Any("options", opts), svc.redactor.Object("result", result), zap.Int("dataSize", count),
 		zap.Error(err),
 	}
 
-	svc.logger.Debug("find user accounts", ff...)
+	if entry := svc.logger.Check(zap.DebugLevel, "find user accounts"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		svc.logger.Error("find user accounts", ff...)
+		if entry := svc.logger.Check(zap.ErrorLevel, "find user accounts"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return nil, err // nolint:wrapcheck
 	}
@@ -109,13 +123,17 @@ func (svc *UserAccountService) FindUserAccountByID(
 
 	ff := []zap.Field{
 		zap.Stringer("start", start), zap.Stringer("end", end), zap.Stringer("elapsed", elapsed),
-		zap.Stringer("accountId", id), zap.Any("account", ua), zap.Error(err),
+		zap.Stringer("accountId", id), svc.redactor.Object("account", ua), zap.Error(err),
 	}
 
-	svc.logger.Debug("find user account by id", ff...)
+	if entry := svc.logger.Check(zap.DebugLevel, "find user account by id"); entry != nil {
+		entry.Write(loggerFields(ctx, ff...)...)
+	}
 
 	if err != nil {
-		svc.logger.Error("find user account by id", ff...)
+		if entry := svc.logger.Check(zap.ErrorLevel, "find user account by id"); entry != nil {
+			entry.Write(loggerFields(ctx, ff...)...)
+		}
 
 		return nil, err // nolint:wrapcheck
 	}
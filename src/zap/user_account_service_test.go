@@ -0,0 +1,81 @@
+package zap_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	otelexample "github.com/morozovcookie/opentelemetry-prometheus-example"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/inmem"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/otelexampletest"
+	"github.com/morozovcookie/opentelemetry-prometheus-example/zap"
+	uberzap "go.uber.org/zap"
+)
+
+func TestUserAccountService(t *testing.T) {
+	t.Parallel()
+
+	otelexampletest.RunUserAccountServiceSuite(t, func(
+		_ *testing.T,
+		identifierGenerator otelexample.IdentifierGenerator,
+		timer otelexample.Timer,
+	) otelexample.UserAccountService {
+		return zap.NewUserAccountService(inmem.NewUserAccountService(identifierGenerator, timer),
+			uberzap.NewNop(), zap.NewRedactor(zap.RedactionMask, nil))
+	})
+}
+
+func TestUserAccountService_Redaction(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range redactionPolicies {
+		policy := policy
+
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				logger, logs = newObservedLogger()
+				ctx          = otelexample.NewContextWithTenantID(context.Background(), "tenant")
+				svc          = zap.NewUserAccountService(inmem.NewUserAccountService(
+					otelexampletest.NewSequenceIdentifierGenerator("id-"),
+					otelexampletest.NewStepTimer(time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), time.Second)),
+					logger, zap.NewRedactor(policy, testHashKey))
+				ua = &otelexample.UserAccount{
+					ID:       otelexample.EmptyID,
+					Username: "alice",
+					User: &otelexample.User{
+						ID:        otelexample.EmptyID,
+						FirstName: "Alice",
+						LastName:  "Liddell",
+						CreatedAt: time.Time{},
+					},
+					CreatedAt: time.Time{},
+				}
+			)
+
+			if err := svc.CreateUserAccount(ctx, ua); err != nil {
+				t.Fatalf("create user account: %v", err)
+			}
+
+			if _, err := svc.FindUserAccountByID(ctx, ua.ID); err != nil {
+				t.Fatalf("find user account: %v", err)
+			}
+
+			if _, err := svc.FindUserAccounts(ctx, otelexample.NewFindOptions(10, 0)); err != nil {
+				t.Fatalf("find user accounts: %v", err)
+			}
+
+			if entries := logs.Len(); entries != 3 {
+				t.Fatalf("unexpected number of entries %d", entries)
+			}
+
+			assertNotLogged(t, logs, "alice", "Alice", "Liddell")
+
+			account := objectField(t, logs.FilterMessage("find user account by id").AllUntimed()[0].ContextMap(),
+				"account")
+			assertRedacted(t, policy, account, "Username", "alice")
+			assertRedacted(t, policy, objectField(t, account, "User"), "FirstName", "Alice")
+		})
+	}
+}